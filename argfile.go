@@ -0,0 +1,125 @@
+package argonaut
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Writes the arguments generated from the given struct (without the command name) to w in the
+// format read by GNU-style "@argfile" arguments: one argument per line.  Arguments containing
+// whitespace, quotes, or backslashes (or that would otherwise be read as a comment) are wrapped in
+// double quotes.
+func WriteArgFile(w io.Writer, v interface{}) error {
+	args, err := Parse(v)
+
+	if err != nil {
+		return err
+	}
+
+	buf := bufio.NewWriter(w)
+
+	for _, arg := range args[1:] {
+		if _, err := fmt.Fprintln(buf, quoteArgFileToken(arg)); err != nil {
+			return err
+		}
+	}
+
+	return buf.Flush()
+}
+
+// Reads the arguments contained in an "@argfile" from r.  Arguments are separated by whitespace,
+// and may be enclosed in single or double quotes to preserve whitespace.  A backslash escapes the
+// character that follows it; a backslash at the end of a line continues the current argument on the
+// next line.  Lines whose first non-whitespace character is "#" are comments.
+func ReadArgFile(r io.Reader) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var inToken bool
+	var quote rune
+	var escaped bool
+	var lineStart = true
+
+	reader := bufio.NewReader(r)
+
+	for {
+		c, _, err := reader.ReadRune()
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case escaped:
+			escaped = false
+
+			// a backslash-newline is a line continuation and contributes nothing to the argument
+			if c != '\n' {
+				current.WriteRune(c)
+				inToken = true
+			}
+
+			lineStart = false
+			continue
+
+		case c == '\\' && quote != '\'':
+			escaped = true
+			continue
+
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+
+			continue
+
+		case lineStart && c == '#':
+			if _, err := reader.ReadString('\n'); err != nil && err != io.EOF {
+				return nil, err
+			}
+
+			continue
+
+		case c == '"' || c == '\'':
+			quote = c
+			inToken = true
+
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			if inToken {
+				args = append(args, current.String())
+				current.Reset()
+				inToken = false
+			}
+
+			lineStart = lineStart || c == '\n'
+			continue
+
+		default:
+			current.WriteRune(c)
+			inToken = true
+		}
+
+		lineStart = false
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in argument file", quote)
+	} else if inToken {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
+
+func quoteArgFileToken(arg string) string {
+	if arg != `` && !strings.ContainsAny(arg, " \t\r\n\"'\\") && !strings.HasPrefix(arg, `#`) {
+		return arg
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
@@ -0,0 +1,63 @@
+package argonaut
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteArgFile(t *testing.T) {
+	assert := require.New(t)
+
+	type compiler struct {
+		Command CommandName `argonaut:"gcc"`
+		Output  string      `argonaut:"o"`
+		Defines []string    `argonaut:"D"`
+		Sources []string    `argonaut:",positional"`
+	}
+
+	var buf bytes.Buffer
+
+	assert.NoError(WriteArgFile(&buf, &compiler{
+		Output:  `my program`,
+		Defines: []string{`NAME="x"`, `#HASH`},
+		Sources: []string{`main.c`, `C:\src\util.c`},
+	}))
+
+	assert.Equal("-o\n\"my program\"\n-D\n\"NAME=\\\"x\\\"\"\n-D\n\"#HASH\"\nmain.c\n\"C:\\\\src\\\\util.c\"\n", buf.String())
+
+	args, err := ReadArgFile(&buf)
+	assert.NoError(err)
+	assert.Equal([]string{`-o`, `my program`, `-D`, `NAME="x"`, `-D`, `#HASH`, `main.c`, `C:\src\util.c`}, args)
+}
+
+func TestReadArgFile(t *testing.T) {
+	assert := require.New(t)
+
+	args, err := ReadArgFile(strings.NewReader(`# a comment
+-O2 -Wall
+   # an indented comment
+-o 'single "quoted"' --not#comment
+--long-\
+continued
+""
+-DVALUE=\'x\'
+`))
+
+	assert.NoError(err)
+	assert.Equal([]string{
+		`-O2`,
+		`-Wall`,
+		`-o`,
+		`single "quoted"`,
+		`--not#comment`,
+		`--long-continued`,
+		``,
+		`-DVALUE='x'`,
+	}, args)
+
+	_, err = ReadArgFile(strings.NewReader(`-o "unterminated`))
+	assert.Error(err)
+}
@@ -0,0 +1,17 @@
+package argonaut
+
+// The limit returned by ArgMax on systems where it cannot be determined (this is the maximum
+// length of a command line on Windows).
+const DefaultArgMax = 32767
+
+// Returns the maximum length of the arguments to a new process on the current system (ARG_MAX),
+// which can be checked before building commands with a large number of values.  Note that on most
+// Unix systems this limit applies to the arguments and environment combined.  If the limit cannot
+// be determined, DefaultArgMax is returned.
+func ArgMax() int {
+	if argMax := sysArgMax(); argMax > 0 {
+		return argMax
+	}
+
+	return DefaultArgMax
+}
@@ -0,0 +1,21 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package argonaut
+
+import (
+	"encoding/binary"
+	"syscall"
+)
+
+// reads the kern.argmax sysctl, which is what sysconf(_SC_ARG_MAX) reports on these systems
+func sysArgMax() int {
+	if value, err := syscall.Sysctl(`kern.argmax`); err == nil {
+		// Sysctl returns the raw integer with any trailing zero bytes removed
+		raw := make([]byte, 4)
+		copy(raw, value)
+
+		return int(binary.LittleEndian.Uint32(raw))
+	}
+
+	return 0
+}
@@ -0,0 +1,29 @@
+package argonaut
+
+import (
+	"syscall"
+)
+
+// the kernel always allows at least this much space for arguments, regardless of the stack size
+const linuxMinArgMax = 131072
+
+// ...and never more than three quarters of its default 8MB stack limit
+const linuxMaxArgMax = 6 << 20
+
+// as with sysconf(_SC_ARG_MAX), the kernel allows arguments to use up to a quarter of the stack
+func sysArgMax() int {
+	var limit syscall.Rlimit
+
+	if err := syscall.Getrlimit(syscall.RLIMIT_STACK, &limit); err != nil {
+		return 0
+	}
+
+	// an unlimited stack (RLIM_INFINITY) is reported as the largest possible value
+	if quarter := limit.Cur / 4; quarter >= linuxMaxArgMax {
+		return linuxMaxArgMax
+	} else if quarter <= linuxMinArgMax {
+		return linuxMinArgMax
+	} else {
+		return int(quarter)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package argonaut
+
+// the limit can't be determined here (e.g.: on Windows), so ArgMax falls back to DefaultArgMax
+func sysArgMax() int {
+	return 0
+}
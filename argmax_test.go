@@ -0,0 +1,14 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArgMax(t *testing.T) {
+	assert := require.New(t)
+
+	assert.True(ArgMax() >= 4096)
+	assert.Equal(ArgMax(), ArgMax())
+}
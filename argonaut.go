@@ -8,64 +8,146 @@ strings that can be used to shell out.
 */
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"os"
 	"os/exec"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode/utf8"
 
 	"github.com/fatih/structs"
-	"github.com/ghetzel/go-stockutil/maputil"
 	"github.com/ghetzel/go-stockutil/sliceutil"
 	"github.com/ghetzel/go-stockutil/stringutil"
 	"github.com/ghetzel/go-stockutil/typeutil"
 	"github.com/ghetzel/go-stockutil/utils"
 )
 
-var DefaultArgumentDelimiter = ` `
-var DefaultCommandWordSeparator = `-`
-var DefaultArgumentKeyPartJoiner = `.`
-var DefaultArgumentKeyValueJoiner = DefaultArgumentDelimiter
-
 type CommandName string
 type ArgName string
 
+// The values of an ExtraArgs field are appended verbatim to the end of the arguments generated for
+// the struct that contains it, after all other fields (including positionals).  This is useful for
+// passing through arbitrary arguments (e.g.: everything after a user-supplied "--").
+type ExtraArgs []string
+
+var extraArgsType = reflect.TypeOf(ExtraArgs{})
+
+// Types implementing ArgonautFlag take full control of how they appear in the command line.  The
+// returned arguments are appended to the command as-is; no option name, joiner, or delimiter
+// processing takes place.
+type ArgonautFlag interface {
+	MarshalArgonautFlag() ([]string, error)
+}
+
+// Structs implementing Hook are given the chance to intercept the processing of each of their own
+// fields.  BeforeField is called with the value of each field before any arguments are generated
+// from it, and may return a different value to use in its place.  AfterField is called with the
+// arguments generated for the field, and returns the arguments that should be used instead.
+type Hook interface {
+	BeforeField(field string, value interface{}) (interface{}, error)
+	AfterField(field string, tokens []string) ([]string, error)
+}
+
 type argonautTag struct {
 	Options               []string
+	Aliases               []string
 	Label                 string
+	ArgValue              string
+	Deprecated            string
+	Help                  string
+	Default               string
+	Choices               []string
+	Min                   *float64
+	Max                   *float64
 	SkipName              bool
+	AutoPath              bool
 	Required              bool
+	EmitZero              bool
+	Stdin                 bool
 	Positional            bool
+	PositionalSafe        bool
 	LongOption            bool
+	AutoLong              bool
+	AutoShort             bool
+	NoAutoInfer           bool
 	ForceShort            bool
 	SuffixPrevious        bool
+	RepeatedStructNoCmd   bool
+	RequiredGroup         string
+	CleanEnv              bool
+	NoExpand              bool
+	Append                bool
+	Complex               bool
+	SkipEmpty             bool
+	Trim                  bool
+	Lowercase             bool
+	Clamp                 bool
+	Composite             bool
+	PadLeft               int
+	PadRight              int
+	PadChar               rune
+	TimeFormat            string
+	WrapOpen              string
+	WrapClose             string
+	LastWins              bool
+	NArgs                 *nargsSpec
+	When                  *condition
+	EmitIf                string
+	EmitUnless            string
+	UnknownOptions        []string
 	Delimiters            []string
 	MutuallyExclusiveWith []string
 	KeyPartJoiner         string
 	Joiner                string
 }
 
-func (self *argonautTag) DelimiterAt(i int) string {
-	if len(self.Delimiters) == 0 {
-		return DefaultArgumentDelimiter
-	} else if i >= len(self.Delimiters) {
-		return self.Delimiters[len(self.Delimiters)-1]
+// the prefix used when this tag is attached to a regular option
+func (self *argonautTag) OptionPrefix() string {
+	if self.LongOption && !self.ForceShort {
+		return `--`
+	} else {
+		return `-`
+	}
+}
+
+// the prefix used when this tag is attached to an ArgName field
+func (self *argonautTag) ArgNamePrefix() string {
+	if self.ForceShort {
+		return `-`
 	} else {
-		return self.Delimiters[i]
+		return `--`
 	}
 }
 
-// Marshals a given struct into a shell-ready command line string.
-func Marshal(v interface{}) ([]byte, error) {
-	if command, sep, err := generateCommand(v, true); err == nil {
+// surrounds a value with the strings given by the "wrap" option (if any)
+func (self *argonautTag) Wrap(value string) string {
+	return self.WrapOpen + value + self.WrapClose
+}
+
+// whether zero values of this field should be left out of the command.  Zero values are omitted
+// by default unless the field is required, or explicitly asks for zero values to be emitted.
+func (self *argonautTag) OmitZero() bool {
+	return !self.Required && !self.EmitZero
+}
+
+// Marshals a given struct into a shell-ready command line string.  Any options given override the
+// global configuration for this call only.
+func Marshal(v interface{}, opts ...Option) ([]byte, error) {
+	if command, sep, err := generateCommand(newConfig(opts...), v, true, false); err == nil {
 		return []byte(strings.Join(command, sep)), nil
 	} else {
 		return nil, err
 	}
 }
 
-// Parses a given struct and returns slice of strings that can be used with os/exec.
-func Parse(v interface{}) ([]string, error) {
-	if command, _, err := generateCommand(v, true); err == nil {
+// Parses a given struct and returns slice of strings that can be used with os/exec.  Any options
+// given override the global configuration for this call only.
+func Parse(v interface{}, opts ...Option) ([]string, error) {
+	if command, _, err := generateCommand(newConfig(opts...), v, true, false); err == nil {
 		return command, err
 	} else {
 		return nil, err
@@ -74,16 +156,52 @@ func Parse(v interface{}) ([]string, error) {
 
 // Parses a given struct and returns slice of strings that can be used with os/exec. Will panic if
 // an error occurs.
-func MustParse(v interface{}) []string {
-	if command, err := Parse(v); err == nil {
+func MustParse(v interface{}, opts ...Option) []string {
+	if command, err := Parse(v, opts...); err == nil {
+		return command
+	} else {
+		panic(err.Error())
+	}
+}
+
+// Parses the given value and returns a new *exec.Cmd instance.  Any options given override the
+// global configuration for this call only.
+func Command(v interface{}, opts ...Option) (*exec.Cmd, error) {
+	return buildCommand(nil, newConfig(opts...), v)
+}
+
+// Parses the given value and returns a new *exec.Cmd instance that is bound to the given context, as
+// with exec.CommandContext: the process is killed if the context is done before it exits.
+func CommandContext(ctx context.Context, v interface{}, opts ...Option) (*exec.Cmd, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("A non-nil context is required")
+	}
+
+	return buildCommand(ctx, newConfig(opts...), v)
+}
+
+// Parses the given value and returns a new *exec.Cmd instance.  Will panic if an error occurs.
+func MustCommand(v interface{}, opts ...Option) *exec.Cmd {
+	if command, err := Command(v, opts...); err == nil {
 		return command
 	} else {
 		panic(err.Error())
 	}
 }
 
-// Parses the given value and returns a new *exec.Cmd instance
-func Command(v interface{}) (*exec.Cmd, error) {
+// Parses the given value and returns a new *exec.Cmd instance that is bound to the given context.
+// Will panic if an error occurs.
+func MustCommandContext(ctx context.Context, v interface{}, opts ...Option) *exec.Cmd {
+	if command, err := CommandContext(ctx, v, opts...); err == nil {
+		return command
+	} else {
+		panic(err.Error())
+	}
+}
+
+// builds the *exec.Cmd for Command and CommandContext from the given Config; ctx is nil for commands
+// without a context
+func buildCommand(ctx context.Context, cfg *Config, v interface{}) (*exec.Cmd, error) {
 	var cmd string
 	var args []string
 
@@ -91,13 +209,25 @@ func Command(v interface{}) (*exec.Cmd, error) {
 		return nil, fmt.Errorf("Cannot parse empty argument into *exec.Cmd")
 	}
 
+	var execopts execOptions
+
 	if typeutil.IsKind(v, reflect.Struct) {
-		if cmdargs, err := Parse(v); err == nil {
+		if cmdargs, _, err := generateCommand(cfg, v, true, false); err == nil {
 			cmd = cmdargs[0]
 			args = cmdargs[1:]
 		} else {
 			return nil, err
 		}
+
+		if err := collectExecOptions(cfg, reflect.ValueOf(v), &execopts); err != nil {
+			return nil, err
+		}
+
+		if expanded, err := execopts.expandCommand(cmd); err == nil {
+			cmd = expanded
+		} else {
+			return nil, err
+		}
 	} else if typeutil.IsKind(v, reflect.String) || typeutil.IsArray(v) {
 		cmdargs := sliceutil.Stringify(sliceutil.Sliceify(v))
 
@@ -111,19 +241,75 @@ func Command(v interface{}) (*exec.Cmd, error) {
 		return nil, fmt.Errorf("Unexpected type: need struct, string, or []string, got: %T", v)
 	}
 
-	return exec.Command(cmd, args...), nil
-}
+	var command *exec.Cmd
 
-// Parses the given value and returns a new *exec.Cmd instance.  Will panic if an error occurs.
-func MustCommand(v interface{}) *exec.Cmd {
-	if command, err := Command(v); err == nil {
-		return command
+	if cfg.Timeout > 0 {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		command = exec.CommandContext(ctx, cmd, args...)
+		trackTimeout(ctx, command, cancel)
+	} else if ctx != nil {
+		command = exec.CommandContext(ctx, cmd, args...)
 	} else {
-		panic(err.Error())
+		command = exec.Command(cmd, args...)
 	}
+
+	if err := execopts.apply(command); err != nil {
+		ReleaseCommand(command)
+		return nil, err
+	}
+
+	return command, nil
 }
 
-func generateCommand(v interface{}, toplevel bool) ([]string, string, error) {
+// the cancel functions of the timeout contexts of commands created with WithTimeout, which are
+// called once the command has finished running
+var commandTimeouts = make(map[*exec.Cmd]context.CancelFunc)
+var commandTimeoutsLock sync.Mutex
+
+// records the cancel function of the given command's timeout context, and forgets it once the
+// context is done so commands that are never released don't accumulate
+func trackTimeout(ctx context.Context, cmd *exec.Cmd, cancel context.CancelFunc) {
+	commandTimeoutsLock.Lock()
+	commandTimeouts[cmd] = cancel
+	commandTimeoutsLock.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		commandTimeoutsLock.Lock()
+		delete(commandTimeouts, cmd)
+		commandTimeoutsLock.Unlock()
+	}()
+}
+
+// Releases the timeout context of a command created with the WithTimeout option, which otherwise
+// lives until the timeout has passed.  Run, ExecWithRetry, WatchAndRun, and Pipeline do this as
+// soon as the command exits; call it after running a command returned by Command yourself (e.g.:
+// "defer argonaut.ReleaseCommand(cmd)").  It does nothing for commands without a timeout.
+func ReleaseCommand(cmd *exec.Cmd) {
+	commandTimeoutsLock.Lock()
+	cancel, ok := commandTimeouts[cmd]
+	delete(commandTimeouts, cmd)
+	commandTimeoutsLock.Unlock()
+
+	if ok {
+		cancel()
+	}
+}
+
+func generateCommand(cfg *Config, v interface{}, toplevel bool, omitCommandName bool) ([]string, string, error) {
+	return generateCommandGroups(cfg, v, toplevel, omitCommandName, nil)
+}
+
+// generates the command for v; if the Config observes argument groups, the groups formed by the
+// returned arguments are stored in observed (if given), and reported to the observer by the
+// toplevel call once the whole command is known
+func generateCommandGroups(cfg *Config, v interface{}, toplevel bool, omitCommandName bool, observed *[]argGroup) ([]string, string, error) {
 	if !typeutil.IsKind(v, reflect.Struct) {
 		return nil, ``, fmt.Errorf("struct needed, got %T", v)
 	}
@@ -132,14 +318,35 @@ func generateCommand(v interface{}, toplevel bool) ([]string, string, error) {
 	command := make([]string, 0)
 
 	if toplevel {
-		command = append(command, fmtCommandWord(input.Name()))
+		command = append(command, cfg.fmtCommandWord(input.Name()))
 	}
 
-	separator := DefaultArgumentDelimiter
-	defaults := argonautTag{
-		Delimiters:    []string{DefaultArgumentDelimiter},
-		KeyPartJoiner: DefaultArgumentKeyPartJoiner,
-		Joiner:        DefaultArgumentKeyValueJoiner,
+	separator := cfg.ArgumentDelimiter
+	positionalSeparated := false
+	defaults := cfg.defaultTag()
+	requiredGroups := make([]string, 0)
+	requiredGroupFields := make(map[string][]string)
+	requiredGroupSatisfied := make(map[string]bool)
+	extraArgs := make([]string, 0)
+
+	hook, isHook := v.(Hook)
+	groups := make([]argGroup, 0)
+
+	if toplevel {
+		groups = append(groups, argGroup{
+			start:   0,
+			end:     1,
+			ordered: true,
+		})
+	}
+
+	// the values of all peer fields, used to evaluate "when" conditions
+	peerValues := make(map[string]interface{})
+
+	for _, field := range input.Fields() {
+		if field.IsExported() {
+			peerValues[field.Name()] = field.Value()
+		}
 	}
 
 	for _, field := range input.Fields() {
@@ -148,26 +355,258 @@ func generateCommand(v interface{}, toplevel bool) ([]string, string, error) {
 		}
 
 		if tag, err := parseTag(field.Tag(`argonaut`), &defaults); err == nil {
-			var primaryOpt string
+			if cfg.StrictTags {
+				if err := tag.checkUnknownOptions(field.Tag(`argonaut`)); err != nil {
+					return nil, separator, withTagContext(err, structTypeName(v), field.Name())
+				}
+			}
 
-			// for marshaling purposes, the option name is determined as:
-			//   - the first value of the tag, or, if that's empty...
-			//   - the field name formatted to a common default
-			if len(tag.Options) > 0 && tag.Options[0] != `` {
-				primaryOpt = tag.Options[0]
-			} else {
-				primaryOpt = fmtCommandWord(field.Name())
+			// fields that configure the *exec.Cmd (e.g.: WorkDir, Env) are not arguments
+			if fieldT := reflect.TypeOf(field.Value()); fieldT != nil && isExecOptionType(derefType(fieldT)) {
+				continue
+			}
+
+			// When: fields whose condition is not met are skipped entirely
+			if tag.When != nil {
+				if ok, err := tag.When.Evaluate(peerValues); err != nil {
+					return nil, separator, fmt.Errorf("field %s: %v", field.Name(), err)
+				} else if !ok {
+					continue
+				}
+			}
+
+			// EmitIf/EmitUnless: fields are skipped entirely (even if they are required) unless the
+			// named boolean peer field is true (or false, respectively)
+			if ok, err := tag.emitConditionMet(peerValues); err != nil {
+				return nil, separator, fmt.Errorf("field %s: %v", field.Name(), err)
+			} else if !ok {
+				continue
+			}
+
+			// ExtraArgs: appended as-is once all other fields have been processed
+			if extra, ok := field.Value().(ExtraArgs); ok {
+				extraArgs = append(extraArgs, extra...)
+				continue
+			} else if extra, ok := field.Value().(*ExtraArgs); ok {
+				if extra != nil {
+					extraArgs = append(extraArgs, (*extra)...)
+				}
+
+				continue
+			}
+
+			primaryOpt := cfg.primaryOption(&tag, field.Name())
+			fieldValue := field.Value()
+			fieldStart := len(command)
+			recursed := false
+			fieldGroups := make([]argGroup, 0)
+
+			// Hook: the struct may replace the value of the field before it is processed
+			if isHook {
+				if hooked, err := hook.BeforeField(field.Name(), fieldValue); err == nil {
+					fieldValue = hooked
+				} else {
+					return nil, separator, fmt.Errorf("field %s: %v", field.Name(), err)
+				}
+			}
+
+			// Interfaces: fields declared as an interface type are handled according to the type of
+			// the value they hold (e.g.: an interface{} holding a bool is a flag)
+			fieldKind := field.Kind()
+
+			if fieldKind == reflect.Interface {
+				if rV := reflect.ValueOf(fieldValue); rV.IsValid() {
+					fieldKind = rV.Kind()
+				}
 			}
 
 			var values []interface{}
+			var elemKind reflect.Kind
+
+			if _, _, ok := registeredSerializer(fieldValue); ok {
+				// values of registered types are never exploded, even if they are slices or
+				// structs, and take precedence over any built-in handling of their type
+				values = append(values, fieldValue)
+			} else if _, ok := fieldValue.(ArgonautFlag); ok {
+				// ArgonautFlag implementations are never exploded, even if they are slices or structs
+				values = append(values, fieldValue)
+			} else if _, ok := fieldValue.(OrderedMap); ok {
+				// OrderedMaps are exploded into key-value pairs, not into their elements
+				values = append(values, fieldValue)
+			} else if _, ok := asOptionSet(fieldValue); ok {
+				values = append(values, fieldValue)
+			} else if tag.NArgs != nil && !tag.Positional {
+				// NArgs: all values follow a single instance of the flag
+				if n := len(sliceutil.Sliceify(typeutil.ResolveValue(fieldValue))); n > 0 && !typeutil.IsZero(fieldValue) {
+					if err := tag.NArgs.Check(n); err != nil {
+						return nil, separator, fmt.Errorf("field %s: %v", field.Name(), err)
+					}
+				}
 
-			utils.SliceEach(field.Value(), func(i int, value interface{}) error {
-				values = append(values, value)
-				return nil
-			}, reflect.Struct, reflect.Map)
+				values = append(values, fieldValue)
+			} else if tag.Stdin && isStdioPlaceholder(fieldValue) {
+				// Stdin: the standard streams are represented by the conventional "-" placeholder
+				values = append(values, `-`)
+			} else {
+				// Typed Slices: booleans and numbers are handled per element, as scalars of their kind
+				if kind, ok := scalarSliceElem(reflect.TypeOf(fieldValue)); ok {
+					elemKind = kind
+				}
+
+				utils.SliceEach(fieldValue, func(i int, value interface{}) error {
+					values = append(values, value)
+					return nil
+				}, reflect.Struct, reflect.Map)
+			}
+
+			// Deprecated: warn whenever a deprecated field is actually being used
+			// ---------------------------------------------------------------------------------
+			if tag.Deprecated != `` && !typeutil.IsZero(fieldValue) {
+				log.Printf("[argonaut] DEPRECATED: field %s: %s", field.Name(), tag.Deprecated)
+			}
+
+			// RequiredGroup: track whether any field in the group has been given a value
+			// ---------------------------------------------------------------------------------
+			if group := tag.RequiredGroup; group != `` {
+				if _, ok := requiredGroupFields[group]; !ok {
+					requiredGroups = append(requiredGroups, group)
+				}
+
+				requiredGroupFields[group] = append(requiredGroupFields[group], field.Name())
+
+				if !typeutil.IsZero(fieldValue) {
+					requiredGroupSatisfied[group] = true
+				}
+			}
+
+			// PositionalSafe: emit a "--" ahead of positional values that look like flags
+			// ---------------------------------------------------------------------------------
+			if tag.Positional && !positionalSeparated && (tag.PositionalSafe || cfg.AutoPositionalSeparator) {
+				if positionalLooksLikeFlag(values) {
+					command = append(command, `--`)
+					positionalSeparated = true
+				}
+			}
 
 			// arrify and iterate through the field value
-			for _, value := range values {
+			for i, value := range values {
+				// Registered Types: serialize the value using the registered function, then
+				// proceed to process the resulting string normally
+				// ---------------------------------------------------------------------------------
+				if serializer, rvalue, ok := registeredSerializer(value); ok {
+					if str, err := serializer(rvalue); err == nil {
+						if str == `` && tag.OmitZero() {
+							continue
+						}
+
+						value = str
+					} else {
+						return nil, separator, err
+					}
+				}
+
+				// ArgonautFlag: the value provides its own arguments verbatim
+				// ---------------------------------------------------------------------------------
+				if flag, ok := value.(ArgonautFlag); ok {
+					if rv := reflect.ValueOf(value); rv.Kind() == reflect.Ptr && rv.IsNil() {
+						continue
+					}
+
+					if args, err := flag.MarshalArgonautFlag(); err == nil {
+						command = append(command, args...)
+						continue
+					} else {
+						return nil, separator, err
+					}
+				}
+
+				// OptionSet: emits only the active alternative (if any)
+				// ---------------------------------------------------------------------------------
+				if set, ok := asOptionSet(value); ok {
+					if set == nil {
+						continue
+					}
+
+					if alt, ok, err := set.Active(); err != nil {
+						return nil, separator, fmt.Errorf("field %s: %v", field.Name(), err)
+					} else if ok {
+						if typeutil.IsKind(alt.Value, reflect.Struct) && !isLeafType(derefType(reflect.TypeOf(alt.Value))) {
+							recursed = true
+
+							var nested []argGroup
+
+							if partial, psep, err := generateCommandGroups(cfg, alt.Value, false, false, &nested); err == nil {
+								command, fieldGroups = appendNested(command, fieldGroups, partial, psep, separator, nested)
+							} else {
+								return nil, separator, err
+							}
+						} else if _, isBool := alt.Value.(bool); isBool {
+							command = opt(command, &tag, separator, alt.Key)
+						} else {
+							command = opt(command, &tag, separator, alt.Key, sliceutil.Sliceify(typeutil.ResolveValue(alt.Value))...)
+						}
+					}
+
+					continue
+				}
+
+				// Typed Slices: numeric elements must be within the range given by "min" and "max",
+				// and are brought within it instead if the "clamp" option is given
+				// ---------------------------------------------------------------------------------
+				if isNumericKind(elemKind) && (tag.Min != nil || tag.Max != nil) {
+					if clamped, err := rangeCheckElement(value, &tag); err == nil {
+						value = clamped
+					} else {
+						return nil, separator, fmt.Errorf("field %s: %v", field.Name(), err)
+					}
+				}
+
+				// Times: formatted according to the "timefmt" option (RFC3339 by default)
+				// ---------------------------------------------------------------------------------
+				if str, isZero, ok := formatTime(value, tag.TimeFormat); ok {
+					if isZero && tag.OmitZero() {
+						continue
+					}
+
+					value = str
+				}
+
+				// TemplateString: string values are expanded using the values of their peer fields
+				// ---------------------------------------------------------------------------------
+				if expanded, err := cfg.expandTemplate(field.Name(), value, peerValues); err == nil {
+					value = expanded
+				} else {
+					return nil, separator, fmt.Errorf("field %s: %v", field.Name(), err)
+				}
+
+				// Preprocess: scalar values are passed through the preprocessing function (if any)
+				// ---------------------------------------------------------------------------------
+				if processed, ok := cfg.preprocessValue(field.Name(), value, &tag); ok {
+					value = processed
+				} else {
+					continue
+				}
+
+				// PadLeft/PadRight: scalar values are padded to a fixed width, once they have been
+				// otherwise processed
+				// ---------------------------------------------------------------------------------
+				value = padValue(value, &tag)
+
+				// Complex Numbers: emitted as "(real+imagi)", or as two separate real and imaginary
+				// values when the "complex" option is given
+				// ---------------------------------------------------------------------------------
+				if parts, isZero, ok := complexParts(value, tag.Complex); ok {
+					if isZero && tag.OmitZero() {
+						continue
+					} else if tag.Positional {
+						command = append(command, parts...)
+					} else {
+						command = opt(command, &tag, separator, sliceutil.OrString(primaryOpt, stringutil.Underscore(field.Name())), sliceutil.Sliceify(parts)...)
+					}
+
+					continue
+				}
+
 				// CommandName: specifies a named command and options for processing peer fields
 				// ---------------------------------------------------------------------------------
 				if _, ok := value.(CommandName); ok {
@@ -184,7 +623,11 @@ func generateCommand(v interface{}, toplevel bool) ([]string, string, error) {
 					defaults.Joiner = tag.Joiner
 					defaults.KeyPartJoiner = tag.KeyPartJoiner
 
-					if valueS != `` {
+					if omitCommandName {
+						// repeated structs may be configured to only emit their command name once
+						continue
+
+					} else if valueS != `` {
 						// prefer value of the field
 						command = []string{valueS}
 
@@ -192,12 +635,20 @@ func generateCommand(v interface{}, toplevel bool) ([]string, string, error) {
 						// fallback to label value
 						command = []string{tag.Label}
 
+					} else if tag.AutoPath {
+						// resolve the tag value (or field name) to a full path using $PATH
+						if path, err := exec.LookPath(primaryOpt); err == nil {
+							command = []string{path}
+						} else {
+							return nil, separator, fmt.Errorf("Cannot locate command %q: %v", primaryOpt, err)
+						}
+
 					} else if primaryOpt != `` {
 						// fallback to tag value
 						command = []string{primaryOpt}
 
 					} else {
-						command = []string{fmtCommandWord(field.Name())}
+						command = []string{cfg.fmtCommandWord(field.Name())}
 
 					}
 
@@ -205,54 +656,54 @@ func generateCommand(v interface{}, toplevel bool) ([]string, string, error) {
 					// ArgName: specifies a named argument from within a nested struct
 					// ---------------------------------------------------------------------------------
 
-					var prefix string
+					argName := tag.ArgNamePrefix() + cfg.argNameLabel(&tag, field.Name())
 
-					if tag.ForceShort {
-						prefix = `-`
+					// a fixed value given with the "value" option immediately follows the name,
+					// joined to it unless the joiner is the same as the separator
+					if tag.ArgValue == `` {
+						command = append(command, argName)
+					} else if tag.Joiner != separator {
+						command = append(command, argName+tag.Joiner+tag.ArgValue)
 					} else {
-						prefix = `--`
+						command = append(command, argName, tag.ArgValue)
 					}
 
-					if len(tag.Label) > 0 {
-						// prefer label
-						command = append(command, prefix+tag.Label)
-					} else if primaryOpt != `` {
-						command = append(command, prefix+primaryOpt)
-					} else {
-						command = append(command, prefix+fmtCommandWord(field.Name()))
-					}
-
-				} else if typeutil.IsKind(value, reflect.Map) {
-					// Maps: get exploded into options
+				} else if _, ok := value.(OrderedMap); ok || typeutil.IsKind(value, reflect.Map) {
+					// Maps: get exploded into options (sorted by key, or in declaration order
+					// for OrderedMaps)
 					// ---------------------------------------------------------------------------------
 
-					if err := maputil.Walk(value, func(v interface{}, key []string, isLeaf bool) error {
-						if isLeaf {
-							var kv string
+					if err := walkMapArguments(value, nil, func(key []string, v interface{}) error {
+						var kv string
 
-							if tag.ForceShort {
-								kv += `-`
-							} else if tag.LongOption {
-								kv += `--`
-							}
+						if tag.ForceShort {
+							kv += `-`
+						} else if tag.LongOption {
+							kv += `--`
+						}
 
-							// only non-nil values expand into [-]key=value arguments
-							if v != nil {
-								kv += strings.Join(key, tag.KeyPartJoiner)
+						kv += strings.Join(key, tag.KeyPartJoiner)
 
-								if tag.Joiner == separator {
-									command = append(command, kv)
-									kv = ``
-								} else {
-									kv += tag.Joiner
-								}
+						// nil values and false booleans are omitted, and true booleans are flags
+						str, ok, isFlag := mapArgValue(v)
 
-								kv += stringutil.MustString(v)
-							}
+						if !ok {
+							return nil
+						} else if isFlag {
+							command = append(command, kv)
+							return nil
+						}
 
+						if tag.Joiner == separator {
 							command = append(command, kv)
+							kv = ``
+						} else {
+							kv += tag.Joiner
 						}
 
+						kv += str
+						command = append(command, kv)
+
 						return nil
 					}); err != nil {
 						return nil, separator, err
@@ -262,16 +713,13 @@ func generateCommand(v interface{}, toplevel bool) ([]string, string, error) {
 					// Structs: recurses into this method
 					// ---------------------------------------------------------------------------------
 
-					if partial, psep, err := generateCommand(value, false); err == nil {
-						// if the separator used in the nested struct matches our own, just tack what
-						// came back onto our command stack,
-						//
-						// otherwise, join using the preferred separator and add it as one big blob
-						if psep == separator {
-							command = append(command, partial...)
-						} else {
-							command = append(command, strings.Join(partial, psep))
-						}
+					omit := (tag.RepeatedStructNoCmd && i > 0)
+					recursed = true
+
+					var nested []argGroup
+
+					if partial, psep, err := generateCommandGroups(cfg, value, false, omit, &nested); err == nil {
+						command, fieldGroups = appendNested(command, fieldGroups, partial, psep, separator, nested)
 					} else {
 						return nil, separator, err
 					}
@@ -279,10 +727,10 @@ func generateCommand(v interface{}, toplevel bool) ([]string, string, error) {
 				} else if tag.SuffixPrevious {
 					// SuffixPrevious: modifies the last argument on the command stack with the current value
 					// ---------------------------------------------------------------------------------
-					if len(command) > 0 && (!typeutil.IsZero(value) || tag.Required) {
+					if len(command) > 0 && (!typeutil.IsZero(value) || !tag.OmitZero()) {
 						last := command[len(command)-1]
 
-						last += tag.DelimiterAt(0)
+						last += cfg.delimiterAt(&tag, 0)
 						last += stringutil.MustString(value)
 
 						command[len(command)-1] = last
@@ -292,9 +740,13 @@ func generateCommand(v interface{}, toplevel bool) ([]string, string, error) {
 				} else if tag.Positional {
 					// Positional: puts whatever the value is into the command immediately
 					// ---------------------------------------------------------------------------------
-					command = append(command, sliceutil.Stringify(
-						sliceutil.Sliceify(value),
-					)...)
+					for _, v := range sliceutil.Stringify(sliceutil.Sliceify(value)) {
+						if v != `` {
+							v = tag.Wrap(v)
+						}
+
+						command = append(command, v)
+					}
 
 					// Scalar Arguments: puts the field name in as the argument name
 					//                    boolean fields:  go in as flags (false values are not added)
@@ -303,9 +755,9 @@ func generateCommand(v interface{}, toplevel bool) ([]string, string, error) {
 				} else {
 					argName := sliceutil.OrString(primaryOpt, stringutil.Underscore(field.Name()))
 
-					if field.Kind() == reflect.Bool {
+					if fieldKind == reflect.Bool || (elemKind == reflect.Bool && typeutil.IsKind(value, reflect.Bool)) {
 						if !typeutil.IsZero(value) {
-							command = opt(command, &tag, argName)
+							command = opt(command, &tag, separator, argName)
 						}
 
 					} else if value == nil {
@@ -313,48 +765,268 @@ func generateCommand(v interface{}, toplevel bool) ([]string, string, error) {
 					} else {
 						value = typeutil.ResolveValue(value)
 
-						if !typeutil.IsZero(value) || tag.Required {
-							command = opt(command, &tag, argName, sliceutil.Sliceify(value)...)
+						// SkipEmpty: empty strings are emitted as empty values (nil pointers are
+						// still omitted)
+						if tag.SkipEmpty && typeutil.IsKind(value, reflect.String) {
+							command = opt(command, &tag, separator, argName, value)
+						} else if !typeutil.IsZero(value) || !tag.OmitZero() {
+							command = opt(command, &tag, separator, argName, sliceutil.Sliceify(value)...)
 						}
 					}
 				}
 			}
+
+			// command names replace everything that came before them
+			_, isCommandName := fieldValue.(CommandName)
+
+			if isCommandName && !omitCommandName {
+				fieldStart = 0
+			}
+
+			// Hook: the struct may replace the arguments that were generated for the field
+			if isHook {
+				if tokens, err := hook.AfterField(field.Name(), append([]string{}, command[fieldStart:]...)); err == nil {
+					command = append(command[:fieldStart], tokens...)
+				} else {
+					return nil, separator, fmt.Errorf("field %s: %v", field.Name(), err)
+				}
+			}
+
+			if cfg.trace != nil && toplevel {
+				cfg.trace(field.Name(), fieldValue, append([]string{}, command[fieldStart:]...))
+			}
+
+			if cfg.observe != nil {
+				if isCommandName && !omitCommandName {
+					groups = groups[:0]
+				}
+
+				if recursed && !isHook {
+					// nested structs contribute the groups formed by their own fields
+					groups = append(groups, fieldGroups...)
+				} else {
+					// (the arguments of a hooked struct may no longer line up with those groups)
+					groups = append(groups, argGroup{
+						start:   fieldStart,
+						end:     len(command),
+						ordered: tag.Positional || isCommandName || recursed,
+					})
+				}
+			}
 		} else {
-			return nil, separator, err
+			return nil, separator, withTagContext(err, structTypeName(v), field.Name())
+		}
+	}
+
+	for _, group := range requiredGroups {
+		if !requiredGroupSatisfied[group] {
+			return nil, separator, fmt.Errorf(
+				"At least one of the fields in required group %q must be given: %s",
+				group,
+				strings.Join(requiredGroupFields[group], `, `),
+			)
+		}
+	}
+
+	command = append(command, extraArgs...)
+
+	if cfg.observe != nil {
+		if len(extraArgs) > 0 {
+			groups = append(groups, argGroup{
+				start:   len(command) - len(extraArgs),
+				end:     len(command),
+				ordered: true,
+			})
+		}
+
+		if observed != nil {
+			*observed = groups
+		}
+
+		if toplevel {
+			for _, group := range groups {
+				if group.end > group.start {
+					cfg.observe(append([]string{}, command[group.start:group.end]...), group.ordered)
+				}
+			}
 		}
 	}
 
 	return command, separator, nil
 }
 
-func fmtCommandWord(in string) string {
-	return strings.Replace(
-		stringutil.Underscore(in),
-		`_`,
-		DefaultCommandWordSeparator,
-		-1,
-	)
+// formats a map value as an argument according to its type, returning whether the value should be
+// emitted at all, and whether it is a boolean flag (whose key is emitted without any value)
+func mapArgValue(value interface{}) (string, bool, bool) {
+	rV := reflect.ValueOf(value)
+
+	for rV.Kind() == reflect.Ptr || rV.Kind() == reflect.Interface {
+		if rV.IsNil() {
+			return ``, false, false
+		}
+
+		rV = rV.Elem()
+	}
+
+	// types that describe themselves (e.g.: time.Duration) are emitted the way they always were
+	if rV.IsValid() {
+		if _, ok := rV.Interface().(fmt.Stringer); ok {
+			return stringutil.MustString(rV.Interface()), true, false
+		}
+	}
+
+	switch rV.Kind() {
+	case reflect.Invalid:
+		return ``, false, false
+	case reflect.Bool:
+		return ``, rV.Bool(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rV.Int(), 10), true, false
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rV.Uint(), 10), true, false
+	case reflect.Float32:
+		return strconv.FormatFloat(rV.Float(), 'f', -1, 32), true, false
+	case reflect.Float64:
+		return strconv.FormatFloat(rV.Float(), 'f', -1, 64), true, false
+	default:
+		return stringutil.MustString(rV.Interface()), true, false
+	}
+}
+
+// the range of arguments generated by a single field, as reported to Config.observe
+type argGroup struct {
+	start   int
+	end     int
+	ordered bool
+}
+
+// appends the arguments generated for a nested struct to the command, along with the groups they
+// form (moved to their position in the command).  If the separator used in the nested struct
+// matches our own, its arguments are tacked onto our command stack; otherwise they are joined using
+// the preferred separator and added as one big blob, whose position is significant.
+func appendNested(command []string, groups []argGroup, partial []string, psep string, separator string, nested []argGroup) ([]string, []argGroup) {
+	offset := len(command)
+
+	if psep == separator {
+		for _, group := range nested {
+			groups = append(groups, argGroup{
+				start:   offset + group.start,
+				end:     offset + group.end,
+				ordered: group.ordered,
+			})
+		}
+
+		return append(command, partial...), groups
+	}
+
+	return append(command, strings.Join(partial, psep)), append(groups, argGroup{
+		start:   offset,
+		end:     offset + 1,
+		ordered: true,
+	})
+}
+
+func structTypeName(v interface{}) string {
+	if vT := reflect.TypeOf(v); vT != nil {
+		for vT.Kind() == reflect.Ptr {
+			vT = vT.Elem()
+		}
+
+		return vT.String()
+	}
+
+	return ``
+}
+
+func positionalLooksLikeFlag(values []interface{}) bool {
+	for _, value := range values {
+		if _, ok := value.(OrderedMap); ok || typeutil.IsKind(value, reflect.Map, reflect.Struct) {
+			continue
+		}
+
+		for _, v := range sliceutil.Stringify(sliceutil.Sliceify(value)) {
+			// a lone "-" is the conventional placeholder for stdin/stdout, not a flag
+			if v != `-` && strings.HasPrefix(v, `-`) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// formats complex64 and complex128 values (or pointers to them) as either a single "(real+imagi)"
+// value or as separate real and imaginary values
+func complexParts(value interface{}, split bool) ([]string, bool, bool) {
+	rV := reflect.ValueOf(value)
+
+	for rV.Kind() == reflect.Ptr {
+		if rV.IsNil() {
+			return nil, false, false
+		}
+
+		rV = rV.Elem()
+	}
+
+	var bits int
+
+	switch rV.Kind() {
+	case reflect.Complex64:
+		bits = 32
+	case reflect.Complex128:
+		bits = 64
+	default:
+		return nil, false, false
+	}
+
+	c := rV.Complex()
+
+	if split {
+		return []string{
+			strconv.FormatFloat(real(c), 'g', -1, bits),
+			strconv.FormatFloat(imag(c), 'g', -1, bits),
+		}, c == 0, true
+	} else {
+		return []string{strconv.FormatComplex(c, 'g', -1, bits*2)}, c == 0, true
+	}
+}
+
+// whether the given value is one of the standard streams that "-" conventionally refers to
+func isStdioPlaceholder(value interface{}) bool {
+	if file, ok := value.(*os.File); ok {
+		return (file == os.Stdin || file == os.Stdout)
+	}
+
+	return false
 }
 
-func opt(command []string, tag *argonautTag, optname string, values ...interface{}) []string {
+// appends an option and its values to the command.  Long options are joined to their first value
+// using the tag's joiner (e.g.: "--flag=value"), unless the joiner is the same as the separator
+// between arguments, in which case the name and value are separate arguments (e.g.: "--flag",
+// "value").  Short options are always separate from their values.
+func opt(command []string, tag *argonautTag, separator string, optname string, values ...interface{}) []string {
 	argset := []string{}
 	prejoin := false
 
+	joiner := tag.Joiner
+
 	if !tag.SkipName {
-		if tag.LongOption && !tag.ForceShort {
-			argset = append(argset, `--`+optname)
+		argset = append(argset, tag.OptionPrefix()+optname)
+		prejoin = (tag.OptionPrefix() == `--` && tag.Joiner != separator)
+
+		// Composite: the value immediately follows the flag name (e.g.: "-Dfoo=bar")
+		if tag.Composite {
 			prejoin = true
-		} else {
-			argset = append(argset, `-`+optname)
+			joiner = ``
 		}
 	}
 
 	for _, v := range values {
-		argset = append(argset, stringutil.MustString(v))
+		argset = append(argset, tag.Wrap(stringutil.MustString(v)))
 	}
 
 	if prejoin && len(argset) >= 2 {
-		command = append(command, argset[0]+tag.Joiner+argset[1])
+		command = append(command, argset[0]+joiner+argset[1])
 		command = append(command, argset[2:]...)
 	} else {
 		command = append(command, argset...)
@@ -363,12 +1035,104 @@ func opt(command []string, tag *argonautTag, optname string, values ...interface
 	return command
 }
 
+// splits a tag on commas, except for those inside of bracketed option values (e.g.: the comma in
+// "delimiters=[::,->]" does not start a new option)
+func splitTagParts(tag string) []string {
+	parts := make([]string, 0)
+	depth := 0
+	start := 0
+
+	for i := 0; i < len(tag); i++ {
+		switch tag[i] {
+		case '[':
+			if depth > 0 || (i > 0 && tag[i-1] == '=') {
+				depth += 1
+			}
+		case ']':
+			if depth > 0 {
+				depth -= 1
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, tag[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, tag[start:])
+}
+
+// parses the (unbracketed) value of the "delimiters" option: a comma-separated list of delimiters,
+// each of which may be more than one character long.  A lone comma is treated as the comma delimiter.
+func parseDelimiters(in string) []string {
+	if in == `,` {
+		return []string{`,`}
+	}
+
+	return strings.Split(in, `,`)
+}
+
+// returns a *TagError for the first unknown option in the tag (if any), used when the StrictTags
+// setting is enabled
+func (self *argonautTag) checkUnknownOptions(tag string) error {
+	if len(self.UnknownOptions) == 0 {
+		return nil
+	}
+
+	message := fmt.Sprintf("unknown argonaut tag option %q", self.UnknownOptions[0])
+
+	if suggestion := closestString(self.UnknownOptions[0], tagOptionNames); suggestion != `` {
+		message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+	}
+
+	return &TagError{
+		TagValue: tag,
+		Message:  message,
+	}
+}
+
+// checks every tag of the given struct type (and its nested structs) for unknown options
+func checkStructTags(cfg *Config, structT reflect.Type) error {
+	var err error
+
+	walkFields(cfg, structT, nil, func(path []int, field reflect.StructField, tag *argonautTag) error {
+		if tagerr := tag.checkUnknownOptions(field.Tag.Get(`argonaut`)); tagerr != nil && err == nil {
+			err = withTagContext(tagerr, structT.String(), field.Name)
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+// returns the tag used for fields that do not have an argonaut tag.  Unless automatic inference is
+// disabled, these are long options named after the field (e.g.: "--field-name"), and inherit the
+// delimiters and joiners of their peers.
+func inferTag(defaults *argonautTag) argonautTag {
+	if defaults.NoAutoInfer {
+		return argonautTag{
+			NoAutoInfer: true,
+		}
+	}
+
+	return argonautTag{
+		Delimiters:    defaults.Delimiters,
+		KeyPartJoiner: defaults.KeyPartJoiner,
+		Joiner:        defaults.Joiner,
+		AutoLong:      defaults.AutoLong,
+		AutoShort:     defaults.AutoShort,
+		LongOption:    defaults.AutoLong || !defaults.AutoShort,
+	}
+}
+
 func parseTag(tag string, defaults *argonautTag) (argonautTag, error) {
 	if tag == `` {
-		return argonautTag{}, nil
+		return inferTag(defaults), nil
 	}
 
-	parts := strings.Split(tag, `,`)
+	parts := splitTagParts(tag)
 
 	if len(parts) > 0 {
 		argonaut := argonautTag{
@@ -376,6 +1140,9 @@ func parseTag(tag string, defaults *argonautTag) (argonautTag, error) {
 			Delimiters:    defaults.Delimiters,
 			KeyPartJoiner: defaults.KeyPartJoiner,
 			Joiner:        defaults.Joiner,
+			AutoLong:      defaults.AutoLong,
+			AutoShort:     defaults.AutoShort,
+			NoAutoInfer:   defaults.NoAutoInfer,
 		}
 
 		for _, tagopt := range parts[1:] {
@@ -384,8 +1151,17 @@ func parseTag(tag string, defaults *argonautTag) (argonautTag, error) {
 			switch optparts[0] {
 			case `required`:
 				argonaut.Required = true
+			case `emit_zero`, `noomitempty`:
+				argonaut.EmitZero = true
+			case `omitempty`:
+				// zero values are omitted by default; this option only serves to document intent
+			case `stdin`:
+				argonaut.Stdin = true
 			case `positional`:
 				argonaut.Positional = true
+			case `positional_safe`:
+				argonaut.Positional = true
+				argonaut.PositionalSafe = true
 			case `long`:
 				argonaut.LongOption = true
 			case `short`:
@@ -395,14 +1171,133 @@ func parseTag(tag string, defaults *argonautTag) (argonautTag, error) {
 				argonaut.SuffixPrevious = true
 			case `skipname`:
 				argonaut.SkipName = true
+			case `repeated_struct_no_cmd`:
+				argonaut.RepeatedStructNoCmd = true
+			case `autopath`:
+				argonaut.AutoPath = true
+			case `clean_env`:
+				argonaut.CleanEnv = true
+			case `no_expand`:
+				argonaut.NoExpand = true
+			case `complex`:
+				argonaut.Complex = true
+			case `skip_empty`:
+				argonaut.SkipEmpty = true
+			case `last_wins`:
+				argonaut.LastWins = true
+			case `trim`:
+				argonaut.Trim = true
+			case `lowercase`:
+				argonaut.Lowercase = true
+			case `clamp`:
+				argonaut.Clamp = true
+			case `composite`:
+				argonaut.Composite = true
 			default:
 				if len(optparts) == 1 {
-					return argonautTag{}, fmt.Errorf("argonaut tag option %q requires an argument", optparts[0])
+					return argonautTag{}, &TagError{
+						TagValue: tag,
+						Message:  fmt.Sprintf("argonaut tag option %q requires an argument", optparts[0]),
+					}
 				}
 
 				switch optparts[0] {
 				case `label`:
 					argonaut.Label = optparts[1]
+				case `value`:
+					argonaut.ArgValue = optparts[1]
+				case `deprecated`:
+					argonaut.Deprecated = optparts[1]
+				case `required_group`:
+					argonaut.RequiredGroup = optparts[1]
+				case `pad_left`, `pad_right`:
+					if n, err := strconv.Atoi(optparts[1]); err == nil && n >= 0 {
+						if optparts[0] == `pad_left` {
+							argonaut.PadLeft = n
+						} else {
+							argonaut.PadRight = n
+						}
+					} else {
+						return argonautTag{}, &TagError{
+							TagValue: tag,
+							Message:  fmt.Sprintf("argonaut tag option %q requires a non-negative integer argument", optparts[0]),
+						}
+					}
+				case `pad_char`:
+					if utf8.RuneCountInString(optparts[1]) == 1 {
+						argonaut.PadChar, _ = utf8.DecodeRuneInString(optparts[1])
+					} else {
+						return argonautTag{}, &TagError{
+							TagValue: tag,
+							Message:  fmt.Sprintf("argonaut tag option %q requires a single character argument", optparts[0]),
+						}
+					}
+				case `emit_if`:
+					argonaut.EmitIf = optparts[1]
+				case `emit_unless`:
+					argonaut.EmitUnless = optparts[1]
+				case `append`:
+					if b, err := strconv.ParseBool(optparts[1]); err == nil {
+						argonaut.Append = b
+					} else {
+						return argonautTag{}, &TagError{
+							TagValue: tag,
+							Message:  fmt.Sprintf("argonaut tag option %q requires a boolean argument", optparts[0]),
+						}
+					}
+				case `help`:
+					argonaut.Help = optparts[1]
+				case `default`:
+					argonaut.Default = optparts[1]
+				case `choices`:
+					argonaut.Choices = sliceutil.CompactString(strings.Split(optparts[1], `|`))
+				case `min`, `max`:
+					if n, err := stringutil.ConvertToFloat(optparts[1]); err == nil {
+						if optparts[0] == `min` {
+							argonaut.Min = &n
+						} else {
+							argonaut.Max = &n
+						}
+					} else {
+						return argonautTag{}, &TagError{
+							TagValue: tag,
+							Message:  fmt.Sprintf("argonaut tag option %q requires a numeric argument", optparts[0]),
+						}
+					}
+				case `nargs`:
+					if spec, err := parseNArgs(optparts[1]); err == nil {
+						argonaut.NArgs = spec
+					} else {
+						return argonautTag{}, &TagError{
+							TagValue: tag,
+							Message:  fmt.Sprintf("argonaut tag option %q: %v", optparts[0], err),
+						}
+					}
+				case `when`:
+					if cond, err := parseCondition(optparts[1]); err == nil {
+						argonaut.When = cond
+					} else {
+						return argonautTag{}, &TagError{
+							TagValue: tag,
+							Message:  fmt.Sprintf("argonaut tag option %q: %v", optparts[0], err),
+						}
+					}
+				case `timefmt`:
+					argonaut.TimeFormat = strings.TrimSuffix(strings.TrimPrefix(optparts[1], `[`), `]`)
+				case `wrap`:
+					v := strings.TrimSuffix(strings.TrimPrefix(optparts[1], `[`), `]`)
+
+					if i := strings.Index(v, `:`); i >= 0 {
+						argonaut.WrapOpen = v[:i]
+						argonaut.WrapClose = v[i+1:]
+					} else {
+						return argonautTag{}, &TagError{
+							TagValue: tag,
+							Message:  fmt.Sprintf("argonaut tag option %q requires an argument of the form open:close", optparts[0]),
+						}
+					}
+				case `alias`:
+					argonaut.Aliases = append(argonaut.Aliases, sliceutil.CompactString(strings.Split(optparts[1], `|`))...)
 				case `delimiters`, `joiner`, `keyjoiner`:
 					v := optparts[1]
 					v = strings.TrimPrefix(v, `[`)
@@ -410,20 +1305,26 @@ func parseTag(tag string, defaults *argonautTag) (argonautTag, error) {
 
 					switch optparts[0] {
 					case `delimiters`:
-						argonaut.Delimiters = strings.Split(v, ``)
+						argonaut.Delimiters = parseDelimiters(v)
 					case `joiner`:
 						argonaut.Joiner = v
 					case `keyjoiner`:
 						argonaut.KeyPartJoiner = v
 					}
+				default:
+					argonaut.UnknownOptions = append(argonaut.UnknownOptions, optparts[0])
 				}
 			}
 		}
 
-		// if long option wasn't specified, but multiple option names were given
-		// assume that the first one is a long option
+		// if long option wasn't specified, use the configured default style; failing that, if
+		// multiple option names were given assume that the first one is a long option
 		if !argonaut.ForceShort && !argonaut.LongOption {
-			if len(argonaut.Options) > 1 {
+			if argonaut.AutoLong {
+				argonaut.LongOption = true
+			} else if argonaut.AutoShort {
+				argonaut.LongOption = false
+			} else if len(argonaut.Options) > 1 {
 				argonaut.LongOption = true
 			}
 		}
@@ -1,6 +1,12 @@
 package argonaut
 
 import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -34,7 +40,7 @@ func TestBasicMarshal(t *testing.T) {
 
 	assert.NoError(err)
 	assert.Equal(`ls --all -l --block-size=1024 --cool-stuff*yep --human-readable /foo /bar/*.txt /baz/`, string(output))
-	t.Logf(string(output))
+	t.Logf("%s", output)
 }
 
 // hateful complexity test 1: ffmpeg
@@ -73,7 +79,790 @@ func TestFfmpegMarshal(t *testing.T) {
 	output, err := Marshal(cmd)
 	assert.NoError(err)
 
-	should := `ffmpeg -loglevel error -i /my/file.avi -codec:v libx264 -preset veryfast -x264opts keyint=24:min-keyint=24:scenecut=-1 -pix_fmt yuv420p -codec:a aac /my/file.mkv`
+	// map keys are emitted in sorted order
+	should := `ffmpeg -loglevel error -i /my/file.avi -codec:v libx264 -pix_fmt yuv420p -preset veryfast -x264opts keyint=24:min-keyint=24:scenecut=-1 -codec:a aac /my/file.mkv`
 
 	assert.Equal(should, string(output))
 }
+
+func TestPositionalSafe(t *testing.T) {
+	assert := require.New(t)
+
+	type rm struct {
+		Command   CommandName `argonaut:"rm"`
+		Force     bool        `argonaut:"f"`
+		Filenames []string    `argonaut:",positional_safe"`
+	}
+
+	output, err := Marshal(&rm{
+		Force:     true,
+		Filenames: []string{`a.txt`, `-b.txt`},
+	})
+
+	assert.NoError(err)
+	assert.Equal(`rm -f -- a.txt -b.txt`, string(output))
+
+	output, err = Marshal(&rm{
+		Filenames: []string{`a.txt`, `b.txt`},
+	})
+
+	assert.NoError(err)
+	assert.Equal(`rm a.txt b.txt`, string(output))
+
+	SetAutoPositionalSeparator(true)
+	defer SetAutoPositionalSeparator(false)
+
+	output, err = Marshal(&ls{
+		Paths: []string{`-foo`},
+	})
+
+	assert.NoError(err)
+	assert.Equal(`ls -- -foo`, string(output))
+}
+
+func TestCommandNameAutoPath(t *testing.T) {
+	assert := require.New(t)
+
+	type shell struct {
+		Command CommandName `argonaut:"sh,autopath"`
+		Script  string      `argonaut:"c"`
+	}
+
+	type missing struct {
+		Command CommandName `argonaut:"argonaut-definitely-not-a-real-command,autopath"`
+	}
+
+	args, err := Parse(&shell{
+		Script: `true`,
+	})
+
+	assert.NoError(err)
+	assert.Len(args, 3)
+	assert.True(strings.HasSuffix(args[0], `/sh`))
+	assert.Equal([]string{`-c`, `true`}, args[1:])
+
+	args, err = Parse(&shell{
+		Command: `bash`,
+	})
+
+	assert.NoError(err)
+	assert.Equal([]string{`bash`}, args)
+
+	_, err = Parse(&missing{})
+	assert.Error(err)
+}
+
+func TestDeprecated(t *testing.T) {
+	assert := require.New(t)
+
+	type tool struct {
+		Command CommandName `argonaut:"tool"`
+		Old     string      `argonaut:"old,deprecated=use --new instead"`
+		New     string      `argonaut:"new"`
+	}
+
+	var buf bytes.Buffer
+
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	output, err := Marshal(&tool{New: `value`})
+	assert.NoError(err)
+	assert.Equal(`tool -new value`, string(output))
+	assert.Empty(buf.String())
+
+	output, err = Marshal(&tool{Old: `value`})
+	assert.NoError(err)
+	assert.Equal(`tool -old value`, string(output))
+	assert.Contains(buf.String(), `[argonaut] DEPRECATED: field Old: use --new instead`)
+}
+
+func TestRepeatedStructs(t *testing.T) {
+	assert := require.New(t)
+
+	type filter struct {
+		Command CommandName `argonaut:"-filter"`
+		Name    string      `argonaut:",positional"`
+	}
+
+	type repeated struct {
+		Command CommandName `argonaut:"process"`
+		Filters []filter
+	}
+
+	type suppressed struct {
+		Command CommandName `argonaut:"process"`
+		Filters []filter    `argonaut:",repeated_struct_no_cmd"`
+	}
+
+	filters := []filter{{Name: `a`}, {Name: `b`}, {Name: `c`}}
+
+	assert.Equal([]string{`process`, `-filter`, `a`, `-filter`, `b`, `-filter`, `c`}, MustParse(&repeated{
+		Filters: filters,
+	}))
+
+	assert.Equal([]string{`process`, `-filter`, `a`, `b`, `c`}, MustParse(&suppressed{
+		Filters: filters,
+	}))
+}
+
+func TestEmitZero(t *testing.T) {
+	assert := require.New(t)
+
+	type server struct {
+		Command CommandName `argonaut:"server"`
+		Port    int         `argonaut:"port,long,joiner=[=],emit_zero"`
+		Workers *int        `argonaut:"workers,long,joiner=[=],emit_zero"`
+		Threads int         `argonaut:"threads,long,joiner=[=]"`
+		Debug   bool        `argonaut:"debug,emit_zero"`
+	}
+
+	assert.Equal([]string{`server`, `--port=0`}, MustParse(&server{}))
+
+	workers := 0
+
+	assert.Equal([]string{`server`, `--port=8080`, `--workers=0`}, MustParse(&server{
+		Port:    8080,
+		Workers: &workers,
+	}))
+
+	// omitempty documents the default behavior, while noomitempty is an alias for emit_zero
+	type explicit struct {
+		Command CommandName `argonaut:"server"`
+		Port    int         `argonaut:"port,long,noomitempty"`
+		Threads int         `argonaut:"threads,long,omitempty"`
+		Name    string      `argonaut:"name,long,omitempty"`
+	}
+
+	assert.Equal([]string{`server`, `--port`, `0`}, MustParse(&explicit{}))
+	assert.Equal([]string{`server`, `--port`, `0`, `--threads`, `4`, `--name`, `x`}, MustParse(&explicit{
+		Threads: 4,
+		Name:    `x`,
+	}))
+	assert.True(Validate(&explicit{}).OK())
+	assert.Empty(Validate(&explicit{}).Warnings)
+}
+
+func TestStdinPlaceholder(t *testing.T) {
+	assert := require.New(t)
+
+	type cat struct {
+		Command CommandName `argonaut:"cat"`
+		Output  interface{} `argonaut:"o,stdin"`
+		Inputs  []string    `argonaut:",positional_safe,stdin,required"`
+	}
+
+	assert.Equal([]string{`cat`, `-o`, `-`, `-`}, MustParse(&cat{
+		Output: os.Stdout,
+		Inputs: []string{`-`},
+	}))
+
+	assert.Equal([]string{`cat`, `--`, `-`, `-file`}, MustParse(&cat{
+		Inputs: []string{`-`, `-file`},
+	}))
+
+	output := &cat{}
+	assert.NoError(Unmarshal([]string{`cat`, `-`}, output))
+	assert.Equal([]string{`-`}, output.Inputs)
+}
+
+type joinerLongSpace struct {
+	Command CommandName `argonaut:"cmd"`
+	Value   string      `argonaut:"value,long"`
+}
+
+type joinerLongEquals struct {
+	Command CommandName `argonaut:"cmd"`
+	Value   string      `argonaut:"value,long,joiner=[=]"`
+}
+
+type joinerShortSpace struct {
+	Command CommandName `argonaut:"cmd"`
+	Value   string      `argonaut:"v,short"`
+}
+
+type joinerShortEquals struct {
+	Command CommandName `argonaut:"cmd"`
+	Value   string      `argonaut:"v,short,joiner=[=]"`
+}
+
+func TestOptionJoiners(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal([]string{`cmd`, `--value`, `x`}, MustParse(&joinerLongSpace{Value: `x`}))
+	assert.Equal([]string{`cmd`, `--value=x`}, MustParse(&joinerLongEquals{Value: `x`}))
+	assert.Equal([]string{`cmd`, `-v`, `x`}, MustParse(&joinerShortSpace{Value: `x`}))
+	assert.Equal([]string{`cmd`, `-v`, `x`}, MustParse(&joinerShortEquals{Value: `x`}))
+
+	// the marshaled form is the same regardless of how the arguments are split
+	output, err := Marshal(&joinerLongSpace{Value: `x`})
+	assert.NoError(err)
+	assert.Equal(`cmd --value x`, string(output))
+}
+
+func BenchmarkOptionLongSpace(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		MustParse(&joinerLongSpace{Value: `x`})
+	}
+}
+
+func BenchmarkOptionLongEquals(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		MustParse(&joinerLongEquals{Value: `x`})
+	}
+}
+
+func BenchmarkOptionShortSpace(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		MustParse(&joinerShortSpace{Value: `x`})
+	}
+}
+
+func BenchmarkOptionShortEquals(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		MustParse(&joinerShortEquals{Value: `x`})
+	}
+}
+
+// builds a pointer to a struct with the given number of populated string options, for measuring
+// how marshaling scales with the number of fields
+func wideCommand(fields int) interface{} {
+	structFields := []reflect.StructField{{
+		Name: `Command`,
+		Type: reflect.TypeOf(CommandName(``)),
+		Tag:  `argonaut:"wide"`,
+	}}
+
+	for i := 0; i < fields; i++ {
+		structFields = append(structFields, reflect.StructField{
+			Name: fmt.Sprintf("Field%d", i),
+			Type: reflect.TypeOf(``),
+			Tag:  reflect.StructTag(fmt.Sprintf("argonaut:\"field-%d,long\"", i)),
+		})
+	}
+
+	wide := reflect.New(reflect.StructOf(structFields))
+
+	for i := 0; i < fields; i++ {
+		wide.Elem().Field(i + 1).SetString(fmt.Sprintf("value-%d", i))
+	}
+
+	return wide.Interface()
+}
+
+// builds a pointer to a struct with the given number of nested structs, each with the given number
+// of populated string options
+func wideNestedCommand(nested int, fields int) interface{} {
+	structFields := []reflect.StructField{{
+		Name: `Command`,
+		Type: reflect.TypeOf(CommandName(``)),
+		Tag:  `argonaut:"wide"`,
+	}}
+
+	for i := 0; i < nested; i++ {
+		structFields = append(structFields, reflect.StructField{
+			Name: fmt.Sprintf("Nested%d", i),
+			Type: reflect.TypeOf(wideCommand(fields)).Elem(),
+		})
+	}
+
+	wide := reflect.New(reflect.StructOf(structFields))
+
+	for i := 0; i < nested; i++ {
+		wide.Elem().Field(i + 1).Set(reflect.ValueOf(wideCommand(fields)).Elem())
+	}
+
+	return wide.Interface()
+}
+
+// These measure how marshaling scales with the number of fields, flat and spread across nested
+// structs.
+func BenchmarkMarshalWide(b *testing.B) {
+	wide := wideCommand(60)
+
+	for i := 0; i < b.N; i++ {
+		MustParse(wide)
+	}
+}
+
+func BenchmarkMarshalWideNested(b *testing.B) {
+	wide := wideNestedCommand(6, 10)
+
+	for i := 0; i < b.N; i++ {
+		MustParse(wide)
+	}
+}
+
+type testFilterGraph []string
+
+func (self testFilterGraph) MarshalArgonautFlag() ([]string, error) {
+	if len(self) == 0 {
+		return nil, nil
+	}
+
+	return []string{`-filter_complex`, strings.Join(self, `;`)}, nil
+}
+
+type testBrokenFlag struct{}
+
+func (self *testBrokenFlag) MarshalArgonautFlag() ([]string, error) {
+	return nil, fmt.Errorf("broken")
+}
+
+func TestArgonautFlag(t *testing.T) {
+	assert := require.New(t)
+
+	type filtered struct {
+		Command CommandName     `argonaut:"ffmpeg"`
+		Filters testFilterGraph `argonaut:"ignored,long"`
+		Broken  *testBrokenFlag
+		Output  string `argonaut:",positional"`
+	}
+
+	assert.Equal([]string{`ffmpeg`, `-filter_complex`, `[0:v]scale=640:-1;[0:a]volume=2`, `out.mkv`}, MustParse(&filtered{
+		Filters: testFilterGraph{`[0:v]scale=640:-1`, `[0:a]volume=2`},
+		Output:  `out.mkv`,
+	}))
+
+	assert.Equal([]string{`ffmpeg`, `out.mkv`}, MustParse(&filtered{
+		Output: `out.mkv`,
+	}))
+
+	_, err := Parse(&filtered{
+		Broken: &testBrokenFlag{},
+	})
+
+	assert.Error(err)
+}
+
+func TestRequiredGroup(t *testing.T) {
+	assert := require.New(t)
+
+	type fetch struct {
+		Command   CommandName `argonaut:"fetch"`
+		InputFile string      `argonaut:"input-file,long,required_group=input"`
+		InputURL  string      `argonaut:"input-url,long,required_group=input"`
+		Verbose   bool        `argonaut:"v"`
+		Output    string      `argonaut:",positional"`
+	}
+
+	assert.Equal([]string{`fetch`, `--input-url`, `http://example.com`, `out.txt`}, MustParse(&fetch{
+		InputURL: `http://example.com`,
+		Output:   `out.txt`,
+	}))
+
+	assert.Equal([]string{`fetch`, `--input-file`, `in.txt`, `--input-url`, `http://example.com`, `out.txt`}, MustParse(&fetch{
+		InputFile: `in.txt`,
+		InputURL:  `http://example.com`,
+		Output:    `out.txt`,
+	}))
+
+	_, err := Parse(&fetch{
+		Verbose: true,
+		Output:  `out.txt`,
+	})
+
+	assert.EqualError(err, `At least one of the fields in required group "input" must be given: InputFile, InputURL`)
+}
+
+func TestExtraArgs(t *testing.T) {
+	assert := require.New(t)
+
+	type grep struct {
+		Command     CommandName `argonaut:"grep"`
+		Passthrough ExtraArgs
+		Recursive   bool     `argonaut:"r"`
+		Pattern     string   `argonaut:",positional"`
+		Paths       []string `argonaut:",positional"`
+	}
+
+	assert.Equal([]string{`grep`, `-r`, `foo`, `.`, `--color=always`, `-n`}, MustParse(&grep{
+		Passthrough: ExtraArgs{`--color=always`, `-n`},
+		Recursive:   true,
+		Pattern:     `foo`,
+		Paths:       []string{`.`},
+	}))
+
+	assert.Equal([]string{`grep`, `foo`}, MustParse(&grep{
+		Pattern: `foo`,
+	}))
+
+	extra := ExtraArgs{`-i`}
+
+	assert.Equal([]string{`grep`, `foo`, `-i`}, MustParse(&struct {
+		Command CommandName `argonaut:"grep"`
+		Extra   *ExtraArgs
+		Pattern string `argonaut:",positional"`
+	}{
+		Extra:   &extra,
+		Pattern: `foo`,
+	}))
+
+	// extra arguments are not recovered when unmarshaling
+	var out grep
+
+	assert.NoError(Unmarshal([]string{`grep`, `-r`, `foo`, `.`}, &out))
+	assert.True(out.Recursive)
+	assert.Nil(out.Passthrough)
+}
+
+func TestComplexNumbers(t *testing.T) {
+	assert := require.New(t)
+
+	type signal struct {
+		Command CommandName `argonaut:"sig"`
+		Pole    complex128  `argonaut:"pole,long,complex"`
+		Zero    complex64   `argonaut:"zero,long"`
+		Gain    *complex128 `argonaut:"gain,long,complex"`
+		Point   complex128  `argonaut:",positional,complex"`
+	}
+
+	gain := complex(0, 1)
+
+	assert.Equal([]string{
+		`sig`,
+		`--pole`, `1.5`, `-2`,
+		`--zero`, `(0.25+1i)`,
+		`--gain`, `0`, `1`,
+		`3`, `4`,
+	}, MustParse(&signal{
+		Pole:  complex(1.5, -2),
+		Zero:  complex64(complex(0.25, 1)),
+		Gain:  &gain,
+		Point: complex(3, 4),
+	}))
+
+	assert.Equal([]string{`sig`}, MustParse(&signal{}))
+
+	result := Validate(&struct {
+		Command CommandName `argonaut:"sig"`
+		Pole    float64     `argonaut:"pole,long,complex"`
+	}{})
+
+	assert.False(result.OK())
+	assert.Equal(DiagnosticTypeMismatch, result.Errors[0].Code)
+	assert.True(Validate(&signal{}).OK())
+}
+
+func TestDelimiters(t *testing.T) {
+	assert := require.New(t)
+	cfg := newConfig()
+	defaults := cfg.defaultTag()
+
+	cases := map[string][]string{
+		`,delimiters=[:]`:      {`:`},
+		`,delimiters=[::]`:     {`::`},
+		`,delimiters=[->]`:     {`->`},
+		`,delimiters=[::,->]`:  {`::`, `->`},
+		`,delimiters=[:,->,=]`: {`:`, `->`, `=`},
+		`,delimiters=[,]`:      {`,`},
+	}
+
+	for input, expected := range cases {
+		tag, err := parseTag(input, &defaults)
+		assert.NoError(err, input)
+		assert.Equal(expected, tag.Delimiters, input)
+	}
+
+	// options following a bracketed value are still parsed
+	tag, err := parseTag(`f,delimiters=[::,->],suffixprev,help=a [bracketed] note`, &defaults)
+	assert.NoError(err)
+	assert.Equal([]string{`::`, `->`}, tag.Delimiters)
+	assert.True(tag.SuffixPrevious)
+	assert.Equal(`a [bracketed] note`, tag.Help)
+	assert.Equal(`::`, cfg.delimiterAt(&tag, 0))
+	assert.Equal(`->`, cfg.delimiterAt(&tag, 1))
+	assert.Equal(`->`, cfg.delimiterAt(&tag, 5))
+
+	// tags without delimiters use the argument delimiter of the Config they are emitted with
+	tag = argonautTag{}
+	assert.Equal(`:`, newConfig(WithDelimiter(`:`)).delimiterAt(&tag, 0))
+
+	type namespaced struct {
+		Command   CommandName `argonaut:"tool"`
+		Name      string      `argonaut:"name,long"`
+		Namespace string      `argonaut:",suffixprev,delimiters=[::]"`
+		Target    string      `argonaut:"target,long"`
+		Arrow     string      `argonaut:",suffixprev,delimiters=[->,:]"`
+	}
+
+	assert.Equal([]string{`tool`, `--name`, `vector::std`, `--target`, `linux->x86`}, MustParse(&namespaced{
+		Name:      `vector`,
+		Namespace: `std`,
+		Target:    `linux`,
+		Arrow:     `x86`,
+	}))
+}
+
+func TestSkipEmpty(t *testing.T) {
+	assert := require.New(t)
+
+	type commit struct {
+		Command CommandName `argonaut:"git"`
+		Message string      `argonaut:"message,long,skip_empty"`
+		Author  *string     `argonaut:"author,long,skip_empty"`
+		Branch  string      `argonaut:"branch,long"`
+	}
+
+	assert.Equal([]string{`git`, `--message`, ``}, MustParse(&commit{}))
+
+	author := ``
+
+	assert.Equal([]string{`git`, `--message`, `fix`, `--author`, ``}, MustParse(&commit{
+		Message: `fix`,
+		Author:  &author,
+	}))
+
+	result := Validate(&struct {
+		Command CommandName `argonaut:"git"`
+		Depth   int         `argonaut:"depth,long,skip_empty"`
+	}{})
+
+	assert.False(result.OK())
+	assert.Equal(DiagnosticTypeMismatch, result.Errors[0].Code)
+}
+
+func TestWrap(t *testing.T) {
+	assert := require.New(t)
+
+	type date struct {
+		Command CommandName `argonaut:"date"`
+		Date    string      `argonaut:"date,long,joiner=[=],wrap=[$(:)]"`
+		Tags    []string    `argonaut:"t,wrap=<:>"`
+		Format  string      `argonaut:",positional,wrap=+:"`
+	}
+
+	assert.Equal([]string{`date`, `--date=$(cal -1)`, `-t`, `<a>`, `-t`, `<b>`, `+%Y`}, MustParse(&date{
+		Date:   `cal -1`,
+		Tags:   []string{`a`, `b`},
+		Format: `%Y`,
+	}))
+
+	// zero values are still omitted
+	assert.Equal([]string{`date`, `+%Y`}, MustParse(&date{Format: `%Y`}))
+
+	_, err := Parse(&struct {
+		Command CommandName `argonaut:"date"`
+		Date    string      `argonaut:"date,wrap=$("`
+	}{
+		Date: `now`,
+	})
+
+	assert.Error(err)
+}
+
+type hookedCommand struct {
+	Command  CommandName `argonaut:"deploy"`
+	Env      string      `argonaut:"env,long"`
+	Token    string      `argonaut:"token,long"`
+	Replicas int         `argonaut:"replicas,long"`
+	Targets  []string    `argonaut:",positional"`
+	called   []string
+}
+
+func (self *hookedCommand) BeforeField(field string, value interface{}) (interface{}, error) {
+	self.called = append(self.called, field)
+
+	switch field {
+	case `Env`:
+		return strings.ToUpper(value.(string)), nil
+	case `Replicas`:
+		if value.(int) < 0 {
+			return nil, fmt.Errorf("replicas cannot be negative")
+		}
+	}
+
+	return value, nil
+}
+
+func (self *hookedCommand) AfterField(field string, tokens []string) ([]string, error) {
+	if field == `Token` && len(tokens) > 0 {
+		return []string{`--token`, `********`}, nil
+	}
+
+	return tokens, nil
+}
+
+func TestHook(t *testing.T) {
+	assert := require.New(t)
+
+	input := &hookedCommand{
+		Env:      `prod`,
+		Token:    `s3cr3t`,
+		Replicas: 3,
+		Targets:  []string{`web`, `api`},
+	}
+
+	assert.Equal([]string{
+		`deploy`, `--env`, `PROD`, `--token`, `********`, `--replicas`, `3`, `web`, `api`,
+	}, MustParse(input))
+
+	assert.Equal([]string{`Command`, `Env`, `Token`, `Replicas`, `Targets`}, input.called)
+
+	// the struct itself is not modified
+	assert.Equal(`prod`, input.Env)
+
+	_, err := Parse(&hookedCommand{Replicas: -1})
+	assert.EqualError(err, `field Replicas: replicas cannot be negative`)
+
+	// hooks are only used when the method set includes them
+	assert.Equal([]string{`deploy`, `--env`, `prod`}, MustParse(hookedCommand{Env: `prod`}))
+}
+
+func TestArgNameValue(t *testing.T) {
+	assert := require.New(t)
+
+	type mode struct {
+		Batch ArgName `argonaut:"batch-mode,value=yes"`
+	}
+
+	type build struct {
+		Command CommandName `argonaut:"build"`
+		Mode    *mode
+		Gui     ArgName `argonaut:"gui,joiner=[=],value=off"`
+		Jobs    ArgName `argonaut:"j,short,value=4"`
+		Target  string  `argonaut:",positional"`
+	}
+
+	assert.Equal([]string{`build`, `--batch-mode`, `yes`, `--gui=off`, `-j`, `4`, `all`}, MustParse(&build{
+		Mode:   &mode{},
+		Target: `all`,
+	}))
+
+	result := Validate(&struct {
+		Command CommandName `argonaut:"build"`
+		Jobs    int         `argonaut:"j,value=4"`
+	}{})
+
+	assert.False(result.OK())
+	assert.Equal(DiagnosticTypeMismatch, result.Errors[0].Code)
+}
+
+func TestAutoInfer(t *testing.T) {
+	assert := require.New(t)
+
+	type output struct {
+		OutputFile string
+		Overwrite  bool
+	}
+
+	type convert struct {
+		Command  CommandName `argonaut:"convert,joiner=[=]"`
+		Quality  int
+		Verbose  bool
+		Strip    bool
+		Label    string
+		Defines  map[string]string
+		Output   *output
+		Inputs   []string `argonaut:",positional"`
+		Explicit string   `argonaut:"x"`
+	}
+
+	input := &convert{
+		Quality: 90,
+		Verbose: true,
+		Label:   `thumb`,
+		Defines: map[string]string{`jpeg:size`: `64x64`},
+		Output: &output{
+			OutputFile: `out.jpg`,
+			Overwrite:  true,
+		},
+		Inputs:   []string{`in.png`},
+		Explicit: `y`,
+	}
+
+	// untagged fields are long options named after the field, and inherit the joiner of their peers
+	// (nested structs start from the defaults again)
+	assert.Equal([]string{
+		`convert`, `--quality=90`, `--verbose`, `--label=thumb`, `--jpeg:size=64x64`, `--output-file`, `out.jpg`, `--overwrite`, `in.png`, `-x`, `y`,
+	}, MustParse(input))
+
+	var out convert
+
+	assert.NoError(Unmarshal(MustParse(input), &out))
+
+	// map fields are not populated by Unmarshal
+	expected := *input
+	expected.Defines = nil
+	assert.Equal(expected, out)
+
+	// short options are used if AutoShort is set
+	assert.Equal([]string{
+		`convert`, `-quality`, `90`, `-verbose`, `-label`, `thumb`, `jpeg:size=64x64`, `-output-file`, `out.jpg`, `-overwrite`, `in.png`, `-x`, `y`,
+	}, MustParse(input, WithAutoShort(true)))
+
+	// without inference, untagged fields are short options that do not inherit any joiners
+	assert.Equal([]string{
+		`convert`, `-quality`, `90`, `-verbose`, `-label`, `thumb`, `jpeg:size64x64`, `-output-file`, `out.jpg`, `-overwrite`, `in.png`, `-x`, `y`,
+	}, MustParse(input, WithNoAutoInfer(true)))
+
+	original := GetGlobalConfig()
+	defer SetGlobalConfig(original)
+
+	SetNoAutoInfer(true)
+	assert.True(NoAutoInfer())
+	assert.Equal([]string{`convert`, `-verbose`}, MustParse(&convert{Verbose: true}))
+}
+
+func TestInterfaceFields(t *testing.T) {
+	assert := require.New(t)
+
+	type server struct {
+		Name string `argonaut:"name,long"`
+	}
+
+	type run struct {
+		Command CommandName `argonaut:"run"`
+		Label   interface{} `argonaut:"label,long"`
+		Port    interface{} `argonaut:"port,long"`
+		Debug   interface{} `argonaut:"debug,long"`
+		Quiet   interface{} `argonaut:"quiet,long"`
+		Tags    interface{} `argonaut:"tag,long"`
+		Server  interface{}
+		Missing interface{} `argonaut:"missing,long"`
+		Args    interface{} `argonaut:",positional"`
+	}
+
+	assert.Equal([]string{
+		`run`, `--label`, `web`, `--port`, `8080`, `--debug`, `--tag`, `a`, `--tag`, `b`, `--name`, `api`, `x`, `2`,
+	}, MustParse(&run{
+		Label:  `web`,
+		Port:   8080,
+		Debug:  true,
+		Quiet:  false,
+		Tags:   []string{`a`, `b`},
+		Server: server{Name: `api`},
+		Args:   []interface{}{`x`, 2},
+	}))
+
+	output, err := MarshalXML(&run{Debug: true, Tags: []string{`a`}})
+	assert.NoError(err)
+	assert.Equal(`<Command name="run"><Flag name="debug"/><Flag name="tag" value="a"/></Command>`, string(output))
+}
+
+func TestTypedSlices(t *testing.T) {
+	assert := require.New(t)
+
+	type encode struct {
+		Command CommandName `argonaut:"encode"`
+		Verbose []bool      `argonaut:"v"`
+		Levels  []int       `argonaut:"level,long,min=0,max=9"`
+		Gains   []float64   `argonaut:"gain,long,min=-1,max=1,clamp"`
+		Inputs  []int       `argonaut:",positional"`
+	}
+
+	assert.Equal([]string{
+		`encode`, `-v`, `-v`, `--level`, `1`, `--level`, `9`, `--gain`, `-1`, `--gain`, `0.5`, `--gain`, `1`, `1`, `2`,
+	}, MustParse(&encode{
+		Verbose: []bool{true, false, true},
+		Levels:  []int{1, 9},
+		Gains:   []float64{-3, 0.5, 2},
+		Inputs:  []int{1, 2},
+	}))
+
+	_, err := Parse(&encode{
+		Levels: []int{3, 10},
+	})
+
+	assert.EqualError(err, `field Levels: 10 is outside of the allowed range [0, 9]`)
+}
@@ -0,0 +1,45 @@
+package argonaut
+
+// Generates the arguments for the given struct using Parse, then builds a new argument list by
+// calling fn with the list so far (starting out empty) and each generated argument (including the
+// command name) in turn.  The slice fn returns becomes the list passed to the next call, and the
+// final one is returned.  This allows for arbitrary transformations, such as merging a flag with
+// the value that follows it.
+func ReduceArgs(v interface{}, fn func(acc []string, token string) []string) ([]string, error) {
+	if args, err := Parse(v); err == nil {
+		acc := make([]string, 0, len(args))
+
+		for _, arg := range args {
+			acc = fn(acc, arg)
+		}
+
+		return acc, nil
+	} else {
+		return nil, err
+	}
+}
+
+// Generates the arguments for the given struct using Parse, then replaces each argument
+// (including the command name) with the result of calling fn on it.  Arguments for which fn returns
+// an empty string are dropped.
+func MapArgs(v interface{}, fn func(string) string) ([]string, error) {
+	return ReduceArgs(v, func(acc []string, arg string) []string {
+		if arg = fn(arg); arg != `` {
+			acc = append(acc, arg)
+		}
+
+		return acc
+	})
+}
+
+// Generates the arguments for the given struct using Parse, keeping only the arguments (including
+// the command name) for which fn returns true.
+func FilterArgs(v interface{}, fn func(string) bool) ([]string, error) {
+	return ReduceArgs(v, func(acc []string, arg string) []string {
+		if fn(arg) {
+			acc = append(acc, arg)
+		}
+
+		return acc
+	})
+}
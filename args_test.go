@@ -0,0 +1,100 @@
+package argonaut
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type argsCopy struct {
+	Command   CommandName `argonaut:"cp"`
+	Recursive bool        `argonaut:"R"`
+	Verbose   bool        `argonaut:"verbose,long"`
+	Mode      string      `argonaut:"mode,long"`
+	Sources   []string    `argonaut:",positional"`
+	Target    string      `argonaut:",positional"`
+}
+
+var argsCopyInput = &argsCopy{
+	Recursive: true,
+	Verbose:   true,
+	Mode:      `0644`,
+	Sources:   []string{`/home/user/a`, `/home/user/SKIP`},
+	Target:    `/tmp`,
+}
+
+func TestMapArgs(t *testing.T) {
+	assert := require.New(t)
+
+	args, err := MapArgs(argsCopyInput, func(arg string) string {
+		if strings.HasSuffix(arg, `SKIP`) {
+			return ``
+		}
+
+		return strings.TrimPrefix(strings.ToUpper(arg), `/HOME/USER/`)
+	})
+
+	assert.NoError(err)
+	assert.Equal([]string{`CP`, `-R`, `--VERBOSE`, `--MODE`, `0644`, `A`, `/TMP`}, args)
+
+	_, err = MapArgs(`cp`, strings.ToUpper)
+	assert.Error(err)
+}
+
+func TestFilterArgs(t *testing.T) {
+	assert := require.New(t)
+
+	args, err := FilterArgs(argsCopyInput, func(arg string) bool {
+		return arg == `cp` || strings.HasPrefix(arg, `-`)
+	})
+
+	assert.NoError(err)
+	assert.Equal([]string{`cp`, `-R`, `--verbose`, `--mode`}, args)
+
+	args, err = FilterArgs(argsCopyInput, func(arg string) bool {
+		return false
+	})
+
+	assert.NoError(err)
+	assert.Empty(args)
+
+	_, err = FilterArgs(nil, func(arg string) bool {
+		return true
+	})
+
+	assert.Error(err)
+}
+
+func TestReduceArgs(t *testing.T) {
+	assert := require.New(t)
+
+	// merge long flags with the value that follows them
+	args, err := ReduceArgs(argsCopyInput, func(acc []string, arg string) []string {
+		if n := len(acc); n > 0 && strings.HasPrefix(acc[n-1], `--mode`) && !strings.Contains(acc[n-1], `=`) {
+			acc[n-1] += `=` + arg
+			return acc
+		}
+
+		return append(acc, arg)
+	})
+
+	assert.NoError(err)
+	assert.Equal([]string{`cp`, `-R`, `--verbose`, `--mode=0644`, `/home/user/a`, `/home/user/SKIP`, `/tmp`}, args)
+
+	// the accumulator starts out empty
+	var calls int
+
+	args, err = ReduceArgs(argsCopyInput, func(acc []string, arg string) []string {
+		if calls == 0 {
+			assert.Empty(acc)
+		}
+
+		calls += 1
+		return acc
+	})
+
+	assert.NoError(err)
+	assert.Equal(len(MustParse(argsCopyInput)), calls)
+	assert.Empty(args)
+}
@@ -0,0 +1,43 @@
+package argonaut
+
+import (
+	"sort"
+	"sync"
+)
+
+var commandRegistry = make(map[string]interface{})
+var commandRegistryLock sync.RWMutex
+
+// Stores the given command struct under the given name, replacing any command previously
+// registered with that name.  This is useful for applications that manage many named command
+// templates.
+func Register(name string, v interface{}) {
+	commandRegistryLock.Lock()
+	defer commandRegistryLock.Unlock()
+
+	commandRegistry[name] = v
+}
+
+// Retrieves the command registered under the given name, and whether one was found.
+func Get(name string) (interface{}, bool) {
+	commandRegistryLock.RLock()
+	defer commandRegistryLock.RUnlock()
+
+	v, ok := commandRegistry[name]
+	return v, ok
+}
+
+// Returns the names of all registered commands, sorted alphabetically.
+func Names() []string {
+	commandRegistryLock.RLock()
+	defer commandRegistryLock.RUnlock()
+
+	names := make([]string, 0, len(commandRegistry))
+
+	for name := range commandRegistry {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
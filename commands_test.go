@@ -0,0 +1,36 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandRegistry(t *testing.T) {
+	assert := require.New(t)
+
+	Register(`list`, &ls{All: true})
+	Register(`greet`, &echo{Words: []string{`hello`}})
+
+	defer func() {
+		commandRegistryLock.Lock()
+		delete(commandRegistry, `list`)
+		delete(commandRegistry, `greet`)
+		commandRegistryLock.Unlock()
+	}()
+
+	assert.Equal([]string{`greet`, `list`}, Names())
+
+	v, ok := Get(`greet`)
+	assert.True(ok)
+	assert.Equal([]string{`echo`, `hello`}, MustParse(v))
+
+	Register(`greet`, &echo{Words: []string{`hi`}})
+	v, ok = Get(`greet`)
+	assert.True(ok)
+	assert.Equal([]string{`echo`, `hi`}, MustParse(v))
+
+	v, ok = Get(`nope`)
+	assert.False(ok)
+	assert.Nil(v)
+}
@@ -0,0 +1,91 @@
+package argonaut
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Describes a field whose value differs between two instances of the same struct.  Field is the Go
+// field name; fields of nested structs are named by joining the names with a dot (e.g.:
+// "Network.Port").  A field is Added if it only has a non-zero value in the new instance, and
+// Removed if it only has a non-zero value in the old one.
+type FieldDiff struct {
+	Field    string
+	OldValue interface{}
+	NewValue interface{}
+	Added    bool
+	Removed  bool
+}
+
+// Compares the fields of two instances of the same struct type, returning a FieldDiff for every
+// field whose value differs (in field declaration order).  Fields within a nil nested struct are
+// treated as zero values, and are then reported with a nil value.
+func Compare(a interface{}, b interface{}) ([]FieldDiff, error) {
+	aT, err := structTypeOf(a)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if bT, err := structTypeOf(b); err != nil {
+		return nil, err
+	} else if aT != bT {
+		return nil, fmt.Errorf("Cannot compare %v with %v", aT, bT)
+	}
+
+	aV := reflect.ValueOf(a)
+	bV := reflect.ValueOf(b)
+	diffs := make([]FieldDiff, 0)
+
+	err = walkFields(newConfig(), aT, nil, func(path []int, field reflect.StructField, tag *argonautTag) error {
+		oldV, oldOk := fieldValueByPath(aV, path)
+		newV, newOk := fieldValueByPath(bV, path)
+		oldZero := !oldOk || oldV.IsZero()
+		newZero := !newOk || newV.IsZero()
+
+		if oldZero && newZero {
+			return nil
+		} else if oldOk && newOk && reflect.DeepEqual(oldV.Interface(), newV.Interface()) {
+			return nil
+		}
+
+		diff := FieldDiff{
+			Field:   fieldPathName(aT, path),
+			Added:   oldZero,
+			Removed: newZero,
+		}
+
+		if oldOk {
+			diff.OldValue = oldV.Interface()
+		}
+
+		if newOk {
+			diff.NewValue = newV.Interface()
+		}
+
+		diffs = append(diffs, diff)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return diffs, nil
+}
+
+// returns the dot-separated Go field names leading to the field at the given index path
+func fieldPathName(structT reflect.Type, path []int) string {
+	names := make([]string, 0, len(path))
+	current := structT
+
+	for _, i := range path {
+		current = derefType(current)
+		field := current.Field(i)
+		names = append(names, field.Name)
+		current = field.Type
+	}
+
+	return strings.Join(names, `.`)
+}
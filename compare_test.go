@@ -0,0 +1,57 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	assert := require.New(t)
+
+	diffs, err := Compare(&ls{
+		All:       true,
+		BlockSize: 1024,
+		Paths:     []string{`/foo`},
+	}, ls{
+		LongFormat: true,
+		BlockSize:  512,
+		Paths:      []string{`/foo`},
+	})
+
+	assert.NoError(err)
+	assert.Equal([]FieldDiff{
+		{Field: `All`, OldValue: true, NewValue: false, Removed: true},
+		{Field: `LongFormat`, OldValue: false, NewValue: true, Added: true},
+		{Field: `BlockSize`, OldValue: 1024, NewValue: 512},
+	}, diffs)
+
+	diffs, err = Compare(&ls{All: true}, &ls{All: true})
+	assert.NoError(err)
+	assert.Empty(diffs)
+
+	type network struct {
+		Host string `argonaut:"host,long"`
+		Port int    `argonaut:"port,long"`
+	}
+
+	type server struct {
+		Command CommandName `argonaut:"serve"`
+		Network *network
+	}
+
+	diffs, err = Compare(&server{}, &server{
+		Network: &network{Port: 8080},
+	})
+
+	assert.NoError(err)
+	assert.Equal([]FieldDiff{
+		{Field: `Network.Port`, OldValue: nil, NewValue: 8080, Added: true},
+	}, diffs)
+
+	_, err = Compare(&ls{}, &server{})
+	assert.Error(err)
+
+	_, err = Compare(`ls`, `ls`)
+	assert.Error(err)
+}
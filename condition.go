@@ -0,0 +1,95 @@
+package argonaut
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ghetzel/go-stockutil/stringutil"
+)
+
+// A condition (specified with the "when=Field:value" tag option) limits a field to only being
+// emitted when the named peer field holds the given value.
+type condition struct {
+	Field string
+	Value string
+}
+
+func parseCondition(spec string) (*condition, error) {
+	if i := strings.Index(spec, `:`); i > 0 {
+		return &condition{
+			Field: spec[:i],
+			Value: spec[i+1:],
+		}, nil
+	}
+
+	return nil, fmt.Errorf("invalid condition %q, expected Field:value", spec)
+}
+
+// reports whether the condition holds, given the values of all fields in the struct (by name)
+func (self *condition) Evaluate(peers map[string]interface{}) (bool, error) {
+	value, ok := peers[self.Field]
+
+	if !ok {
+		return false, fmt.Errorf("condition refers to unknown field %q", self.Field)
+	}
+
+	rV := reflect.ValueOf(value)
+
+	for rV.Kind() == reflect.Ptr {
+		if rV.IsNil() {
+			return self.Value == ``, nil
+		}
+
+		rV = rV.Elem()
+	}
+
+	if rV.IsValid() {
+		return stringutil.MustString(rV.Interface()) == self.Value, nil
+	}
+
+	return self.Value == ``, nil
+}
+
+// reports whether the fields named by the "emit_if" and "emit_unless" options of the tag (if any)
+// are true and false, respectively
+func (self *argonautTag) emitConditionMet(peers map[string]interface{}) (bool, error) {
+	if self.EmitIf != `` {
+		if value, err := peerBool(peers, `emit_if`, self.EmitIf); err != nil || !value {
+			return false, err
+		}
+	}
+
+	if self.EmitUnless != `` {
+		if value, err := peerBool(peers, `emit_unless`, self.EmitUnless); err != nil || value {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// returns the value of the named boolean peer field (nil pointers are false)
+func peerBool(peers map[string]interface{}, option string, name string) (bool, error) {
+	value, ok := peers[name]
+
+	if !ok {
+		return false, fmt.Errorf("%s refers to unknown field %q", option, name)
+	}
+
+	rV := reflect.ValueOf(value)
+
+	for rV.Kind() == reflect.Ptr {
+		if rV.IsNil() {
+			return false, nil
+		}
+
+		rV = rV.Elem()
+	}
+
+	if rV.Kind() != reflect.Bool {
+		return false, fmt.Errorf("%s refers to non-boolean field %q", option, name)
+	}
+
+	return rV.Bool(), nil
+}
@@ -0,0 +1,137 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhenCondition(t *testing.T) {
+	assert := require.New(t)
+
+	type export struct {
+		Command CommandName `argonaut:"export"`
+		Format  string      `argonaut:"format,long"`
+		Output  string      `argonaut:"o,when=Format:json"`
+		Indent  *int        `argonaut:"indent,long,when=Pretty:true"`
+		Pretty  bool        `argonaut:"pretty,long"`
+	}
+
+	indent := 2
+
+	assert.Equal([]string{`export`, `--format`, `json`, `-o`, `out.json`, `--indent`, `2`, `--pretty`}, MustParse(&export{
+		Format: `json`,
+		Output: `out.json`,
+		Indent: &indent,
+		Pretty: true,
+	}))
+
+	assert.Equal([]string{`export`, `--format`, `csv`}, MustParse(&export{
+		Format: `csv`,
+		Output: `out.json`,
+		Indent: &indent,
+	}))
+
+	// conditions referring to fields that don't exist are an error
+	_, err := Parse(&struct {
+		Command CommandName `argonaut:"export"`
+		Output  string      `argonaut:"o,when=Format:json"`
+	}{
+		Output: `out.json`,
+	})
+
+	assert.Error(err)
+
+	// as are malformed conditions
+	_, err = Parse(&struct {
+		Command CommandName `argonaut:"export"`
+		Format  string      `argonaut:"format,long"`
+		Output  string      `argonaut:"o,when=Format"`
+	}{})
+
+	assert.Error(err)
+}
+
+func TestEmitIf(t *testing.T) {
+	assert := require.New(t)
+
+	type build struct {
+		Command  CommandName `argonaut:"build"`
+		Release  bool        `argonaut:"-"`
+		Verbose  *bool       `argonaut:"-"`
+		Optimize int         `argonaut:"O,emit_if=Release,emit_zero"`
+		Debug    bool        `argonaut:"g,emit_unless=Release"`
+		Target   string      `argonaut:"target,long,required,emit_if=Release"`
+		Trace    bool        `argonaut:"trace,long,emit_if=Verbose"`
+	}
+
+	verbose := true
+
+	assert.Equal([]string{`build`, `-O`, `0`, `--target`, `x86_64`, `--trace`}, MustParse(&build{
+		Release: true,
+		Verbose: &verbose,
+		Debug:   true,
+		Target:  `x86_64`,
+		Trace:   true,
+	}))
+
+	// the required Target is not emitted (and so not required) when Release is false
+	assert.Equal([]string{`build`, `-g`}, MustParse(&build{
+		Debug: true,
+		Trace: true,
+	}))
+
+	// conditions must refer to boolean peer fields
+	_, err := Parse(&struct {
+		Command CommandName `argonaut:"build"`
+		Mode    string      `argonaut:"-"`
+		Output  string      `argonaut:"o,emit_if=Mode"`
+	}{})
+
+	assert.EqualError(err, `field Output: emit_if refers to non-boolean field "Mode"`)
+
+	_, err = Parse(&struct {
+		Command CommandName `argonaut:"build"`
+		Output  string      `argonaut:"o,emit_unless=Missing"`
+	}{})
+
+	assert.EqualError(err, `field Output: emit_unless refers to unknown field "Missing"`)
+}
+
+func TestEmitIfCycles(t *testing.T) {
+	assert := require.New(t)
+
+	type cyclic struct {
+		Command  CommandName `argonaut:"cyclic"`
+		Alpha    bool        `argonaut:"alpha,emit_if=Beta"`
+		Beta     bool        `argonaut:"beta,emit_unless=Gamma"`
+		Gamma    bool        `argonaut:"gamma,emit_if=Alpha"`
+		Self     bool        `argonaut:"self,emit_if=Self"`
+		Standard bool        `argonaut:"standard,emit_if=Alpha"`
+		Name     string      `argonaut:"name,emit_if=Missing"`
+	}
+
+	result := Validate(&cyclic{})
+
+	assert.False(result.OK())
+	assert.Equal([]Diagnostic{
+		{
+			Severity: SeverityError,
+			Code:     DiagnosticTypeMismatch,
+			Field:    `Name`,
+			Message:  `emit condition refers to unknown field "Missing"`,
+		}, {
+			Severity:   SeverityError,
+			Code:       DiagnosticCircularCondition,
+			Field:      `Alpha`,
+			Message:    `emit conditions form a cycle: Alpha -> Beta -> Gamma -> Alpha`,
+			Suggestion: `remove the emit_if or emit_unless option from one of these fields`,
+		}, {
+			Severity:   SeverityError,
+			Code:       DiagnosticCircularCondition,
+			Field:      `Self`,
+			Message:    `emit conditions form a cycle: Self -> Self`,
+			Suggestion: `remove the emit_if or emit_unless option from one of these fields`,
+		},
+	}, result.Errors)
+}
@@ -0,0 +1,120 @@
+package argonaut
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Populates the struct pointed to by v from a configuration file of "key = value" lines (as found
+// in many files under /etc), read from r.  Everything following a "#" at the start of a line or
+// after whitespace is a comment.  Keys are matched against the option names, aliases, and field
+// names of v's fields without regard to case, and with hyphens and underscores treated as the same
+// character (e.g.: "Block_Size" matches "block-size").  Everything after the first "=" is the value,
+// including any inner whitespace; surrounding quotes are removed.  A key without a value (and
+// without an "=") is set to true.  Keys that are given more than once become a list of values.
+func ParseConf(r io.Reader, v interface{}) error {
+	vV := reflect.ValueOf(v)
+
+	if vV.Kind() != reflect.Ptr || vV.IsNil() || vV.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("pointer to struct needed, got %T", v)
+	}
+
+	cfg := newConfig()
+	names := make(map[string]string)
+
+	// map the normalized form of every name a field can be given by to the name itself
+	if err := walkFields(cfg, vV.Elem().Type(), nil, func(path []int, field reflect.StructField, tag *argonautTag) error {
+		candidates := []string{cfg.primaryOption(tag, field.Name)}
+		candidates = append(candidates, tag.Options...)
+		candidates = append(candidates, tag.Aliases...)
+		candidates = append(candidates, field.Name)
+
+		for _, name := range candidates {
+			if _, ok := names[normalizeConfKey(name)]; !ok && name != `` {
+				names[normalizeConfKey(name)] = name
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	data := make(map[string]interface{})
+	scanner := bufio.NewScanner(r)
+	lineno := 0
+
+	for scanner.Scan() {
+		lineno += 1
+		line := strings.TrimSpace(stripConfComment(scanner.Text()))
+
+		if line == `` {
+			continue
+		}
+
+		var key string
+		var value interface{}
+
+		if i := strings.Index(line, `=`); i >= 0 {
+			key = strings.TrimSpace(line[:i])
+			value = unquoteConfValue(strings.TrimSpace(line[i+1:]))
+		} else {
+			key = line
+			value = `true`
+		}
+
+		if key == `` {
+			return fmt.Errorf("conf: line %d: expected a key", lineno)
+		}
+
+		if name, ok := names[normalizeConfKey(key)]; ok {
+			key = name
+		}
+
+		// repeated keys become lists of values
+		if existing, ok := data[key]; ok {
+			if list, ok := existing.([]interface{}); ok {
+				data[key] = append(list, value)
+			} else {
+				data[key] = []interface{}{existing, value}
+			}
+		} else {
+			data[key] = value
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return populate(cfg, v, data)
+}
+
+func normalizeConfKey(key string) string {
+	return strings.ToLower(strings.Replace(key, `_`, `-`, -1))
+}
+
+// removes a trailing comment, which starts with a "#" at the beginning of the line or after
+// whitespace
+func stripConfComment(line string) string {
+	for i, r := range line {
+		if r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			return line[:i]
+		}
+	}
+
+	return line
+}
+
+func unquoteConfValue(value string) string {
+	if len(value) >= 2 {
+		if first, last := value[0], value[len(value)-1]; first == last && (first == '"' || first == '\'') {
+			return value[1 : len(value)-1]
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,54 @@
+package argonaut
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConf(t *testing.T) {
+	assert := require.New(t)
+
+	type daemon struct {
+		Command   CommandName `argonaut:"daemon"`
+		BlockSize int         `argonaut:"block-size,long"`
+		Verbose   bool        `argonaut:"verbose|v,long"`
+		Motd      string      `argonaut:"motd,long"`
+		Color     string      `argonaut:"colour,long,alias=color"`
+		LogFile   string
+		Servers   []string `argonaut:"server,long"`
+	}
+
+	var out daemon
+
+	assert.NoError(ParseConf(strings.NewReader(`
+# settings for the daemon
+Block_Size = 4096
+verbose
+motd = Welcome to the  machine   # trailing comment
+COLOR="dark#blue"
+log_file = /var/log/daemon.log
+server = a.example.com
+server = b.example.com
+unknown = ignored
+`), &out))
+
+	assert.Equal(daemon{
+		BlockSize: 4096,
+		Verbose:   true,
+		Motd:      `Welcome to the  machine`,
+		Color:     `dark#blue`,
+		LogFile:   `/var/log/daemon.log`,
+		Servers:   []string{`a.example.com`, `b.example.com`},
+	}, out)
+
+	out = daemon{}
+	assert.NoError(ParseFromReader(bytes.NewBufferString("block-size=512\n"), `conf`, &out))
+	assert.Equal(512, out.BlockSize)
+
+	assert.Error(ParseConf(strings.NewReader(`= nothing`), &daemon{}))
+	assert.Error(ParseConf(strings.NewReader(`block-size = big`), &daemon{}))
+	assert.Error(ParseConf(strings.NewReader(``), daemon{}))
+}
@@ -0,0 +1,398 @@
+package argonaut
+
+import (
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/ghetzel/go-stockutil/stringutil"
+)
+
+// Config holds the settings that control how structs are converted into arguments.  A Config is
+// captured once at the start of each call, so changes to the global configuration made while a
+// command is being generated do not affect it.
+type Config struct {
+	ArgumentDelimiter      string
+	CommandWordSeparator   string
+	ArgumentKeyPartJoiner  string
+	ArgumentKeyValueJoiner string
+
+	// If true, a "--" argument will be inserted before the first positional value of any
+	// positional field whose values could be mistaken for flags (i.e.: they start with "-").
+	AutoPositionalSeparator bool
+
+	// If true, options whose tags do not specify "long" or "short" are emitted as long options
+	// (e.g.: "--name"), regardless of how many names they are given.
+	AutoLong bool
+
+	// If true, options whose tags do not specify "long" or "short" are emitted as short options
+	// (e.g.: "-name"), regardless of how many names they are given.  AutoLong takes precedence.
+	AutoShort bool
+
+	// If true, fields without an argonaut tag are emitted as they were before tags were inferred
+	// for them: as short options (e.g.: "-field-name"), without the delimiters and joiners of their
+	// peer fields.
+	NoAutoInfer bool
+
+	// If true, Unmarshal accepts any unambiguous prefix of a flag's name in place of the full name
+	// (e.g.: "--verb" for "--verbose"), as GNU getopt_long does.
+	AbbreviateFlags bool
+
+	// If true, Unmarshal returns a *TypeMismatchError for values that are not literals of their
+	// field's type (e.g.: "1.5" for an integer), instead of attempting to coerce them.
+	StrictTypes bool
+
+	// If true, struct tags containing options that argonaut does not recognize (e.g.: a misspelled
+	// "requried=true") cause a *TagError, instead of being ignored.
+	StrictTags bool
+
+	// If non-zero, commands created by Command are killed if they are still running once this much
+	// time has passed since they were created.
+	Timeout time.Duration
+
+	// if set by TemplateString, string values are expanded as templates; templateDefs holds the
+	// shared definitions available to them, which are parsed into templateBase when first needed
+	templating   bool
+	templateDefs string
+	templateBase *template.Template
+
+	// used by Preprocess to transform values before they are emitted
+	preprocess PreprocessFunc
+
+	// if set, called with the arguments generated by each field (used by Hash); ordered is true for
+	// arguments whose position is significant (command names and positionals)
+	observe func(tokens []string, ordered bool)
+
+	// if set, called with the value of each top-level field that is processed and the arguments
+	// generated from it (used by Debug)
+	trace func(field string, value interface{}, tokens []string)
+}
+
+// GlobalConfig holds the package-wide defaults that every Config starts from.
+type GlobalConfig Config
+
+var globalConfig = GlobalConfig{
+	ArgumentDelimiter:       ` `,
+	CommandWordSeparator:    `-`,
+	ArgumentKeyPartJoiner:   `.`,
+	ArgumentKeyValueJoiner:  ` `,
+	AutoPositionalSeparator: false,
+}
+
+var globalConfigLock sync.RWMutex
+
+// Replaces the global configuration used by all subsequent calls.
+func SetGlobalConfig(cfg GlobalConfig) {
+	globalConfigLock.Lock()
+	defer globalConfigLock.Unlock()
+
+	globalConfig = cfg
+}
+
+// Returns a copy of the current global configuration.
+func GetGlobalConfig() GlobalConfig {
+	globalConfigLock.RLock()
+	defer globalConfigLock.RUnlock()
+
+	return globalConfig
+}
+
+func updateGlobalConfig(fn func(cfg *GlobalConfig)) {
+	globalConfigLock.Lock()
+	defer globalConfigLock.Unlock()
+
+	fn(&globalConfig)
+}
+
+// Returns a new Config populated from the current global configuration.
+func DefaultConfig() *Config {
+	cfg := Config(GetGlobalConfig())
+	return &cfg
+}
+
+// An Option overrides a setting of the global configuration for a single call (e.g.: to Parse or
+// Command), without affecting any other callers.
+type Option func(cfg *Config)
+
+// Sets the string used to separate arguments when marshaling a command line.
+func WithDelimiter(v string) Option {
+	return func(cfg *Config) {
+		cfg.ArgumentDelimiter = v
+	}
+}
+
+// Sets the string used to separate words when converting field names into option names.
+func WithSeparator(v string) Option {
+	return func(cfg *Config) {
+		cfg.CommandWordSeparator = v
+	}
+}
+
+// Sets the string used to join the parts of nested map keys.
+func WithKeyPartJoiner(v string) Option {
+	return func(cfg *Config) {
+		cfg.ArgumentKeyPartJoiner = v
+	}
+}
+
+// Sets the string used to join option names to their values.
+func WithKeyValueJoiner(v string) Option {
+	return func(cfg *Config) {
+		cfg.ArgumentKeyValueJoiner = v
+	}
+}
+
+// Sets whether a "--" argument is automatically inserted ahead of positional values that could be
+// mistaken for flags.
+func WithAutoPositionalSeparator(v bool) Option {
+	return func(cfg *Config) {
+		cfg.AutoPositionalSeparator = v
+	}
+}
+
+// Sets whether options without an explicit "long" or "short" tag option are emitted as long options.
+func WithAutoLong(v bool) Option {
+	return func(cfg *Config) {
+		cfg.AutoLong = v
+	}
+}
+
+// Sets whether options without an explicit "long" or "short" tag option are emitted as short
+// options.
+func WithAutoShort(v bool) Option {
+	return func(cfg *Config) {
+		cfg.AutoShort = v
+	}
+}
+
+// Sets whether the tags of fields without an argonaut tag are no longer inferred.
+func WithNoAutoInfer(v bool) Option {
+	return func(cfg *Config) {
+		cfg.NoAutoInfer = v
+	}
+}
+
+// Sets whether Unmarshal accepts unambiguous prefixes of flag names in place of the full names.
+func WithAbbreviateFlags(v bool) Option {
+	return func(cfg *Config) {
+		cfg.AbbreviateFlags = v
+	}
+}
+
+// Sets whether Unmarshal rejects values that are not literals of their field's type.
+func WithStrictTypes(v bool) Option {
+	return func(cfg *Config) {
+		cfg.StrictTypes = v
+	}
+}
+
+// Sets whether unrecognized struct tag options cause an error instead of being ignored.
+func WithStrictTags(v bool) Option {
+	return func(cfg *Config) {
+		cfg.StrictTags = v
+	}
+}
+
+// Sets a time limit for commands created by Command (e.g.: Run(v, WithTimeout(30*time.Second))).
+// The command is created with a context that expires once the timeout has passed since the
+// command was created, at which point the process is killed.  The context is released as soon as
+// the command finishes when it's run by Run, ExecWithRetry, WatchAndRun, or a Pipeline; otherwise
+// see ReleaseCommand.
+func WithTimeout(d time.Duration) Option {
+	return func(cfg *Config) {
+		cfg.Timeout = d
+	}
+}
+
+// Expands string field values as text/template templates before they are emitted, with the fields
+// of the struct they belong to as data (e.g.: a value of "{{.OutputDir}}/{{.Basename}}.mp4").  The
+// given template string is parsed first, and any templates it defines (e.g.: with
+// `{{define "ext"}}mp4{{end}}`) can be used by every value; pass an empty string if there are none.
+// Errors parsing or executing a template are returned by the call the option is passed to.
+func TemplateString(tmpl string) Option {
+	return func(cfg *Config) {
+		cfg.templating = true
+		cfg.templateDefs = tmpl
+	}
+}
+
+// returns a Config populated from the current global configuration, with the given options applied
+func newConfig(opts ...Option) *Config {
+	cfg := DefaultConfig()
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cfg)
+		}
+	}
+
+	return cfg
+}
+
+// Returns the string used to separate arguments when marshaling a command line.
+func DefaultArgumentDelimiter() string {
+	return GetGlobalConfig().ArgumentDelimiter
+}
+
+// Sets the string used to separate arguments when marshaling a command line.
+func SetDefaultArgumentDelimiter(v string) {
+	updateGlobalConfig(func(cfg *GlobalConfig) {
+		cfg.ArgumentDelimiter = v
+	})
+}
+
+// Returns the string used to separate words when converting field names into option names.
+func DefaultCommandWordSeparator() string {
+	return GetGlobalConfig().CommandWordSeparator
+}
+
+// Sets the string used to separate words when converting field names into option names.
+func SetDefaultCommandWordSeparator(v string) {
+	updateGlobalConfig(func(cfg *GlobalConfig) {
+		cfg.CommandWordSeparator = v
+	})
+}
+
+// Returns the string used to join the parts of nested map keys.
+func DefaultArgumentKeyPartJoiner() string {
+	return GetGlobalConfig().ArgumentKeyPartJoiner
+}
+
+// Sets the string used to join the parts of nested map keys.
+func SetDefaultArgumentKeyPartJoiner(v string) {
+	updateGlobalConfig(func(cfg *GlobalConfig) {
+		cfg.ArgumentKeyPartJoiner = v
+	})
+}
+
+// Returns the string used to join option names to their values.
+func DefaultArgumentKeyValueJoiner() string {
+	return GetGlobalConfig().ArgumentKeyValueJoiner
+}
+
+// Sets the string used to join option names to their values.
+func SetDefaultArgumentKeyValueJoiner(v string) {
+	updateGlobalConfig(func(cfg *GlobalConfig) {
+		cfg.ArgumentKeyValueJoiner = v
+	})
+}
+
+// Returns whether a "--" argument is automatically inserted ahead of positional values that could
+// be mistaken for flags.
+func AutoPositionalSeparator() bool {
+	return GetGlobalConfig().AutoPositionalSeparator
+}
+
+// Sets whether a "--" argument is automatically inserted ahead of positional values that could be
+// mistaken for flags.
+func SetAutoPositionalSeparator(v bool) {
+	updateGlobalConfig(func(cfg *GlobalConfig) {
+		cfg.AutoPositionalSeparator = v
+	})
+}
+
+// Returns whether options without an explicit "long" or "short" tag option are emitted as long
+// options.
+func AutoLong() bool {
+	return GetGlobalConfig().AutoLong
+}
+
+// Sets whether options without an explicit "long" or "short" tag option are emitted as long
+// options, instead of guessing based on the number of names they are given.
+func SetAutoLong(v bool) {
+	updateGlobalConfig(func(cfg *GlobalConfig) {
+		cfg.AutoLong = v
+	})
+}
+
+// Returns whether options without an explicit "long" or "short" tag option are emitted as short
+// options.
+func AutoShort() bool {
+	return GetGlobalConfig().AutoShort
+}
+
+// Sets whether options without an explicit "long" or "short" tag option are emitted as short
+// options, instead of guessing based on the number of names they are given.
+func SetAutoShort(v bool) {
+	updateGlobalConfig(func(cfg *GlobalConfig) {
+		cfg.AutoShort = v
+	})
+}
+
+// Returns whether unrecognized struct tag options cause an error instead of being ignored.
+func StrictTags() bool {
+	return GetGlobalConfig().StrictTags
+}
+
+// Sets whether unrecognized struct tag options cause an error instead of being ignored.
+func SetStrictTags(v bool) {
+	updateGlobalConfig(func(cfg *GlobalConfig) {
+		cfg.StrictTags = v
+	})
+}
+
+// Returns whether the tags of fields without an argonaut tag are no longer inferred.
+func NoAutoInfer() bool {
+	return GetGlobalConfig().NoAutoInfer
+}
+
+// Sets whether the tags of fields without an argonaut tag are no longer inferred, restoring the
+// behavior of earlier versions (short options that do not inherit the joiners of their peers).
+func SetNoAutoInfer(v bool) {
+	updateGlobalConfig(func(cfg *GlobalConfig) {
+		cfg.NoAutoInfer = v
+	})
+}
+
+func (self *Config) defaultTag() argonautTag {
+	return argonautTag{
+		Delimiters:    []string{self.ArgumentDelimiter},
+		KeyPartJoiner: self.ArgumentKeyPartJoiner,
+		Joiner:        self.ArgumentKeyValueJoiner,
+		AutoLong:      self.AutoLong,
+		AutoShort:     self.AutoShort,
+		NoAutoInfer:   self.NoAutoInfer,
+	}
+}
+
+// returns the i-th of the tag's delimiters (the last one is used for all that follow it), or the
+// argument delimiter if the tag has none
+func (self *Config) delimiterAt(tag *argonautTag, i int) string {
+	if len(tag.Delimiters) == 0 {
+		return self.ArgumentDelimiter
+	} else if i >= len(tag.Delimiters) {
+		return tag.Delimiters[len(tag.Delimiters)-1]
+	} else {
+		return tag.Delimiters[i]
+	}
+}
+
+// for marshaling purposes, the option name is determined as:
+//   - the first value of the tag, or, if that's empty...
+//   - the field name formatted to a common default
+func (self *Config) primaryOption(tag *argonautTag, fieldName string) string {
+	if len(tag.Options) > 0 && tag.Options[0] != `` {
+		return tag.Options[0]
+	} else {
+		return self.fmtCommandWord(fieldName)
+	}
+}
+
+// ArgName fields prefer the tag label over the option name
+func (self *Config) argNameLabel(tag *argonautTag, fieldName string) string {
+	if len(tag.Label) > 0 {
+		return tag.Label
+	} else {
+		return self.primaryOption(tag, fieldName)
+	}
+}
+
+func (self *Config) fmtCommandWord(in string) string {
+	return strings.Replace(
+		stringutil.Underscore(in),
+		`_`,
+		self.CommandWordSeparator,
+		-1,
+	)
+}
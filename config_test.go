@@ -0,0 +1,257 @@
+package argonaut
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigIsolation(t *testing.T) {
+	assert := require.New(t)
+
+	type keyValue struct {
+		Command   CommandName `argonaut:"kv"`
+		SomeThing bool
+		Settings  map[string]interface{} `argonaut:",long"`
+	}
+
+	cfg := DefaultConfig()
+	cfg.CommandWordSeparator = `_`
+	cfg.ArgumentKeyValueJoiner = `=`
+
+	input := &keyValue{
+		SomeThing: true,
+		Settings: map[string]interface{}{
+			`a`: 1,
+		},
+	}
+
+	args, _, err := generateCommand(cfg, input, true, false)
+	assert.NoError(err)
+	assert.Equal([]string{`kv`, `--some_thing`, `--a=1`}, args)
+
+	// the package-level defaults are unaffected
+	assert.Equal([]string{`kv`, `--some-thing`, `--a`, `1`}, MustParse(input))
+}
+
+func TestGlobalConfig(t *testing.T) {
+	assert := require.New(t)
+
+	type keyValue struct {
+		Command   CommandName `argonaut:"kv"`
+		SomeThing bool
+		Settings  map[string]interface{} `argonaut:",long"`
+	}
+
+	input := &keyValue{
+		SomeThing: true,
+		Settings: map[string]interface{}{
+			`a`: 1,
+		},
+	}
+
+	original := GetGlobalConfig()
+	defer SetGlobalConfig(original)
+
+	assert.Equal(` `, DefaultArgumentDelimiter())
+	assert.Equal(`-`, DefaultCommandWordSeparator())
+	assert.Equal(`.`, DefaultArgumentKeyPartJoiner())
+	assert.Equal(` `, DefaultArgumentKeyValueJoiner())
+	assert.False(AutoPositionalSeparator())
+
+	SetDefaultCommandWordSeparator(`_`)
+	SetDefaultArgumentKeyValueJoiner(`=`)
+
+	assert.Equal(`_`, GetGlobalConfig().CommandWordSeparator)
+	assert.Equal([]string{`kv`, `--some_thing`, `--a=1`}, MustParse(input))
+
+	cfg := GetGlobalConfig()
+	cfg.ArgumentDelimiter = `,`
+	SetGlobalConfig(cfg)
+
+	output, err := Marshal(input)
+	assert.NoError(err)
+	assert.Equal(`kv,--some_thing,--a=1`, string(output))
+
+	SetGlobalConfig(original)
+	assert.Equal([]string{`kv`, `--some-thing`, `--a`, `1`}, MustParse(input))
+}
+
+func TestGlobalConfigSnapshot(t *testing.T) {
+	assert := require.New(t)
+
+	type words struct {
+		Command   CommandName `argonaut:"words"`
+		BlockSize int
+		CoolStuff string
+	}
+
+	input := &words{BlockSize: 1, CoolStuff: `x`}
+	dashed := []string{`words`, `--block-size`, `1`, `--cool-stuff`, `x`}
+	underscored := []string{`words`, `--block_size`, `1`, `--cool_stuff`, `x`}
+
+	original := GetGlobalConfig()
+	defer SetGlobalConfig(original)
+
+	done := make(chan struct{})
+	flipped := make(chan struct{})
+
+	go func() {
+		defer close(flipped)
+
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			if i%2 == 0 {
+				SetDefaultCommandWordSeparator(`_`)
+			} else {
+				SetDefaultCommandWordSeparator(`-`)
+			}
+		}
+	}()
+
+	// every call sees a single configuration, however often the global one changes underneath it
+	consistent := func(args []string) bool {
+		return reflect.DeepEqual(dashed, args) || reflect.DeepEqual(underscored, args)
+	}
+
+	for i := 0; i < 200; i++ {
+		args, err := Parse(input)
+		assert.NoError(err)
+		assert.True(consistent(args), args)
+
+		cmd, err := Command(input)
+		assert.NoError(err)
+		assert.True(consistent(cmd.Args), cmd.Args)
+
+		args, err = Substitute(input, nil)
+		assert.NoError(err)
+		assert.True(consistent(args), args)
+
+		args, err = Truncate(input, 1000)
+		assert.NoError(err)
+		assert.True(consistent(args), args)
+	}
+
+	close(done)
+	<-flipped
+}
+
+func TestOptions(t *testing.T) {
+	assert := require.New(t)
+
+	type keyValue struct {
+		Command   CommandName `argonaut:"kv"`
+		SomeThing bool
+		Settings  map[string]interface{} `argonaut:",long"`
+		Args      []string               `argonaut:",positional"`
+	}
+
+	input := &keyValue{
+		SomeThing: true,
+		Settings: map[string]interface{}{
+			`a`: map[string]interface{}{
+				`b`: 1,
+			},
+		},
+		Args: []string{`-x`},
+	}
+
+	assert.Equal([]string{`kv`, `--some_thing`, `--a/b=1`, `--`, `-x`}, MustParse(
+		input,
+		WithSeparator(`_`),
+		WithKeyPartJoiner(`/`),
+		WithKeyValueJoiner(`=`),
+		WithAutoPositionalSeparator(true),
+		nil,
+	))
+
+	output, err := Marshal(input, WithDelimiter(`,`))
+	assert.NoError(err)
+	assert.Equal(`kv,--some-thing,--a.b 1,-x`, string(output))
+
+	cmd, err := Command(input, WithSeparator(`_`))
+	assert.NoError(err)
+	assert.Equal([]string{`kv`, `--some_thing`, `--a.b`, `1`, `-x`}, cmd.Args)
+
+	// per-call options leave the global configuration untouched
+	assert.Equal(`-`, DefaultCommandWordSeparator())
+	assert.Equal([]string{`kv`, `--some-thing`, `--a.b`, `1`, `-x`}, MustParse(input))
+}
+
+func TestAutoLongShort(t *testing.T) {
+	assert := require.New(t)
+
+	type curl struct {
+		Command CommandName `argonaut:"curl"`
+		Verbose bool        `argonaut:"verbose"`
+		Output  string      `argonaut:"output|o"`
+		Header  string      `argonaut:"H,short"`
+		Silent  bool        `argonaut:"s,long"`
+	}
+
+	input := &curl{
+		Verbose: true,
+		Output:  `out.html`,
+		Header:  `Accept: */*`,
+		Silent:  true,
+	}
+
+	assert.Equal([]string{`curl`, `-verbose`, `--output`, `out.html`, `-H`, `Accept: */*`, `--s`}, MustParse(input))
+	assert.Equal([]string{`curl`, `--verbose`, `--output`, `out.html`, `-H`, `Accept: */*`, `--s`}, MustParse(input, WithAutoLong(true)))
+	assert.Equal([]string{`curl`, `-verbose`, `-output`, `out.html`, `-H`, `Accept: */*`, `--s`}, MustParse(input, WithAutoShort(true)))
+
+	original := GetGlobalConfig()
+	defer SetGlobalConfig(original)
+
+	SetAutoLong(true)
+	assert.True(AutoLong())
+	assert.Equal([]string{`curl`, `--verbose`, `--output`, `out.html`, `-H`, `Accept: */*`, `--s`}, MustParse(input))
+
+	// AutoLong takes precedence
+	SetAutoShort(true)
+	assert.True(AutoShort())
+	assert.Equal([]string{`curl`, `--verbose`, `--output`, `out.html`, `-H`, `Accept: */*`, `--s`}, MustParse(input))
+
+	SetAutoLong(false)
+	assert.Equal([]string{`curl`, `-verbose`, `-output`, `out.html`, `-H`, `Accept: */*`, `--s`}, MustParse(input))
+}
+
+func TestStrictTags(t *testing.T) {
+	assert := require.New(t)
+
+	type typo struct {
+		Command CommandName `argonaut:"typo"`
+		Name    string      `argonaut:"name,long,hepl=The name to use"`
+	}
+
+	// unknown options are ignored by default
+	assert.Equal([]string{`typo`, `--name`, `x`}, MustParse(&typo{Name: `x`}))
+	assert.NoError(Unmarshal([]string{`typo`, `--name`, `x`}, &typo{}))
+
+	_, err := Parse(&typo{Name: `x`}, WithStrictTags(true))
+	assert.Error(err)
+	assert.IsType(&TagError{}, err)
+	assert.Equal(`Name`, err.(*TagError).FieldName)
+	assert.Equal(`unknown argonaut tag option "hepl" (did you mean "help"?)`, err.Error())
+
+	assert.IsType(&TagError{}, Unmarshal([]string{`typo`}, &typo{}, WithStrictTags(true)))
+	assert.IsType(&TagError{}, UnmarshalStrict([]string{`typo`}, &typo{}, WithStrictTags(true)))
+
+	original := GetGlobalConfig()
+	defer SetGlobalConfig(original)
+
+	SetStrictTags(true)
+	assert.True(StrictTags())
+
+	_, err = Parse(&typo{})
+	assert.Error(err)
+
+	_, err = Parse(&ls{All: true})
+	assert.NoError(err)
+}
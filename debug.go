@@ -0,0 +1,143 @@
+package argonaut
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ghetzel/go-stockutil/typeutil"
+)
+
+// Returns a verbose, multi-line description of how the given struct is converted into arguments,
+// for use while developing argonaut structs.  For each top-level field, the output shows its raw
+// argonaut tag, the options parsed from that tag, the value used, the decision that was made
+// (whether the field was emitted, skipped, or omitted so that its documented default applies), and
+// the arguments generated from it.  Any error encountered while generating the command is included
+// at the end of the output.
+func Debug(v interface{}, opts ...Option) string {
+	var out strings.Builder
+
+	structT, err := structTypeOf(v)
+
+	if err != nil {
+		return fmt.Sprintf("error: %v\n", err)
+	}
+
+	type traced struct {
+		value  interface{}
+		tokens []string
+	}
+
+	cfg := newConfig(opts...)
+	fields := make(map[string]traced)
+
+	cfg.trace = func(field string, value interface{}, tokens []string) {
+		fields[field] = traced{
+			value:  value,
+			tokens: tokens,
+		}
+	}
+
+	args, _, genErr := generateCommand(cfg, v, true, false)
+	structV := reflect.Indirect(reflect.ValueOf(v))
+	defaults := cfg.defaultTag()
+
+	out.WriteString(fmt.Sprintf("%s (%T)\n", structTypeName(v), v))
+
+	for i := 0; i < structT.NumField(); i++ {
+		field := structT.Field(i)
+
+		if field.PkgPath != `` {
+			continue
+		}
+
+		raw := field.Tag.Get(`argonaut`)
+		out.WriteString(fmt.Sprintf("\n%s\n", field.Name))
+		out.WriteString(fmt.Sprintf("  tag:      %q\n", raw))
+
+		var tag argonautTag
+
+		if raw != `-` {
+			if parsed, err := parseTag(raw, &defaults); err == nil {
+				tag = parsed
+				out.WriteString(fmt.Sprintf("  parsed:   %s\n", debugTag(&tag)))
+			} else {
+				out.WriteString(fmt.Sprintf("  parsed:   error: %v\n", err))
+			}
+		}
+
+		var value interface{}
+		var decision string
+		trace, processed := fields[field.Name]
+
+		if processed {
+			value = trace.value
+		} else if structV.IsValid() {
+			value = structV.Field(i).Interface()
+		}
+
+		switch {
+		case processed && len(trace.tokens) > 0:
+			decision = `emitted`
+		case tag.Default != `` && typeutil.IsZero(value):
+			decision = `defaulted (` + tag.Default + `)`
+		default:
+			decision = `skipped`
+		}
+
+		out.WriteString(fmt.Sprintf("  value:    %v\n", debugValue(value)))
+		out.WriteString(fmt.Sprintf("  decision: %s\n", decision))
+
+		if processed {
+			out.WriteString(fmt.Sprintf("  tokens:   %q\n", trace.tokens))
+		}
+	}
+
+	if genErr == nil {
+		out.WriteString(fmt.Sprintf("\nresult: %q\n", args))
+	} else {
+		out.WriteString(fmt.Sprintf("\nerror: %v\n", genErr))
+	}
+
+	return out.String()
+}
+
+// lists the non-zero options of a parsed tag (e.g.: `{Options:["all" "a"] LongOption:true}`)
+func debugTag(tag *argonautTag) string {
+	tagV := reflect.ValueOf(tag).Elem()
+	parts := make([]string, 0)
+
+	for i := 0; i < tagV.NumField(); i++ {
+		if fieldV := tagV.Field(i); !fieldV.IsZero() {
+			name := tagV.Type().Field(i).Name
+
+			// strings are quoted to make delimiters and joiners visible
+			if fieldV.Kind() == reflect.String || (fieldV.Kind() == reflect.Slice && fieldV.Type().Elem().Kind() == reflect.String) {
+				parts = append(parts, fmt.Sprintf("%s:%q", name, fieldV.Interface()))
+			} else {
+				parts = append(parts, fmt.Sprintf("%s:%v", name, debugValue(fieldV.Interface())))
+			}
+		}
+	}
+
+	return `{` + strings.Join(parts, ` `) + `}`
+}
+
+// dereferences pointers, so that values are shown instead of their addresses
+func debugValue(value interface{}) interface{} {
+	rV := reflect.ValueOf(value)
+
+	for rV.Kind() == reflect.Ptr {
+		if rV.IsNil() {
+			return nil
+		}
+
+		rV = rV.Elem()
+	}
+
+	if rV.IsValid() && rV.CanInterface() {
+		return rV.Interface()
+	}
+
+	return value
+}
@@ -0,0 +1,74 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebug(t *testing.T) {
+	assert := require.New(t)
+
+	type rm struct {
+		Command   CommandName `argonaut:"rm"`
+		Force     bool        `argonaut:"f"`
+		Recursive bool        `argonaut:"recursive|r,long"`
+		Mode      string      `argonaut:"mode,long,default=interactive"`
+		Internal  string      `argonaut:"-"`
+		Paths     []string    `argonaut:",positional"`
+	}
+
+	assert.Equal(`argonaut.rm (*argonaut.rm)
+
+Command
+  tag:      "rm"
+  parsed:   {Options:["rm"] Delimiters:[" "] KeyPartJoiner:"." Joiner:" "}
+  value:    
+  decision: emitted
+  tokens:   ["rm"]
+
+Force
+  tag:      "f"
+  parsed:   {Options:["f"] Delimiters:[" "] KeyPartJoiner:"." Joiner:" "}
+  value:    true
+  decision: emitted
+  tokens:   ["-f"]
+
+Recursive
+  tag:      "recursive|r,long"
+  parsed:   {Options:["recursive" "r"] LongOption:true Delimiters:[" "] KeyPartJoiner:"." Joiner:" "}
+  value:    false
+  decision: skipped
+  tokens:   []
+
+Mode
+  tag:      "mode,long,default=interactive"
+  parsed:   {Options:["mode"] Default:"interactive" LongOption:true Delimiters:[" "] KeyPartJoiner:"." Joiner:" "}
+  value:    
+  decision: defaulted (interactive)
+  tokens:   []
+
+Internal
+  tag:      "-"
+  value:    
+  decision: skipped
+
+Paths
+  tag:      ",positional"
+  parsed:   {Options:[] Positional:true Delimiters:[" "] KeyPartJoiner:"." Joiner:" "}
+  value:    [/tmp/a]
+  decision: emitted
+  tokens:   ["/tmp/a"]
+
+result: ["rm" "-f" "/tmp/a"]
+`, Debug(&rm{Force: true, Paths: []string{`/tmp/a`}}))
+
+	// generation errors are reported rather than returned
+	type cp struct {
+		Command CommandName `argonaut:"cp"`
+		Source  string      `argonaut:",positional,requried"`
+	}
+
+	assert.Contains(Debug(cp{}, WithStrictTags(true)), "\nerror: ")
+	assert.Equal("error: struct needed, got string\n", Debug(`nope`))
+}
@@ -0,0 +1,343 @@
+package argonaut
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/ghetzel/go-stockutil/stringutil"
+)
+
+// Populates the struct pointed to by v from configuration data read from r.  The format must be
+// one of "json", "yaml" (or "yml"), "toml", "csv", "conf", or "argfile"; any other value returns
+// an *UnsupportedFormatError.
+//
+// For the json, yaml, and toml formats, the data is a document whose keys are the option names of
+// the struct's fields (as specified in their argonaut tags), or the field names themselves.  The
+// csv format is a header row of keys followed by a single row of values.  The conf format is a list
+// of "key = value" lines, as read by ParseConf.  The argfile format is a list of command line
+// arguments without the command name, as read by ReadArgFile.
+func ParseFromReader(r io.Reader, format string, v interface{}) error {
+	switch strings.ToLower(format) {
+	case `json`:
+		return ParseJSON(r, v)
+	case `yaml`, `yml`:
+		return ParseYAML(r, v)
+	case `toml`:
+		return ParseTOML(r, v)
+	case `csv`:
+		return parseCSV(r, v)
+	case `conf`:
+		return ParseConf(r, v)
+	case `argfile`:
+		return parseArgFile(r, v)
+	default:
+		return &UnsupportedFormatError{
+			Format: format,
+		}
+	}
+}
+
+// Parses each file matching the given glob pattern into a new instance of v's type, returning the
+// populated instances (in the order the files are matched).  If v is a pointer, each element is a
+// pointer to a new struct; otherwise, each element is a struct value.  Every instance starts as a
+// copy of v, so v can be used to provide default values.  The format of each file is determined
+// by its extension (e.g.: ".json", ".yml", ".yaml", or ".toml"), as supported by ParseFromReader.
+func ParseGlob(pattern string, v interface{}) ([]interface{}, error) {
+	vV := reflect.ValueOf(v)
+	isPtr := (vV.Kind() == reflect.Ptr)
+
+	if (isPtr && vV.IsNil()) || reflect.Indirect(vV).Kind() != reflect.Struct {
+		return nil, fmt.Errorf("struct or pointer to struct needed, got %T", v)
+	}
+
+	filenames, err := filepath.Glob(pattern)
+
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]interface{}, 0, len(filenames))
+
+	for _, filename := range filenames {
+		instance := reflect.New(reflect.Indirect(vV).Type())
+		instance.Elem().Set(reflect.Indirect(vV))
+
+		if err := parseFile(filename, instance.Interface()); err != nil {
+			return nil, fmt.Errorf("%s: %v", filename, err)
+		}
+
+		if isPtr {
+			results = append(results, instance.Interface())
+		} else {
+			results = append(results, instance.Elem().Interface())
+		}
+	}
+
+	return results, nil
+}
+
+func parseFile(filename string, v interface{}) error {
+	if file, err := os.Open(filename); err == nil {
+		defer file.Close()
+
+		return ParseFromReader(file, strings.TrimPrefix(filepath.Ext(filename), `.`), v)
+	} else {
+		return err
+	}
+}
+
+// Re-reads the given configuration file into the struct pointed to by v, which is useful for
+// long-running services that want to pick up changes to their configuration without restarting.
+// The format is determined by the file's extension (e.g.: ".json", ".yaml", or ".toml"), as
+// supported by ParseFromReader.  Fields that do not appear in the file keep their current values.
+//
+// The update is atomic: the file is parsed into a copy of v, which is only copied back into v if
+// the entire file was parsed successfully.  On error, v is left unchanged.
+func Reload(filename string, v interface{}) error {
+	vV := reflect.ValueOf(v)
+
+	if vV.Kind() != reflect.Ptr || vV.IsNil() || vV.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("pointer to struct needed, got %T", v)
+	}
+
+	reloaded := reflect.New(vV.Elem().Type())
+	reloaded.Elem().Set(vV.Elem())
+	cloneNestedStructs(reloaded.Elem())
+
+	if err := parseFile(filename, reloaded.Interface()); err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+
+	vV.Elem().Set(reloaded.Elem())
+	return nil
+}
+
+// Populates the struct pointed to by v from a JSON object read from r.
+func ParseJSON(r io.Reader, v interface{}) error {
+	var data map[string]interface{}
+
+	if err := json.NewDecoder(r).Decode(&data); err == nil {
+		return populate(newConfig(), v, data)
+	} else {
+		return err
+	}
+}
+
+// Populates the struct pointed to by v from a YAML document read from r.  Only a subset of YAML
+// is supported: block and flow mappings and sequences, comments, and plain or quoted scalars.
+func ParseYAML(r io.Reader, v interface{}) error {
+	if data, err := ioutil.ReadAll(r); err == nil {
+		if doc, err := parseYAML(string(data)); err == nil {
+			if doc == nil {
+				return populate(newConfig(), v, nil)
+			} else if m, ok := doc.(map[string]interface{}); ok {
+				return populate(newConfig(), v, m)
+			} else {
+				return fmt.Errorf("YAML document must be a mapping, got %T", doc)
+			}
+		} else {
+			return err
+		}
+	} else {
+		return err
+	}
+}
+
+// Populates the struct pointed to by v from a TOML document read from r.  Multi-line strings are
+// not supported.
+func ParseTOML(r io.Reader, v interface{}) error {
+	if data, err := ioutil.ReadAll(r); err == nil {
+		if doc, err := parseTOML(string(data)); err == nil {
+			return populate(newConfig(), v, doc)
+		} else {
+			return err
+		}
+	} else {
+		return err
+	}
+}
+
+func parseCSV(r io.Reader, v interface{}) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+
+	if err != nil {
+		return err
+	} else if len(records) == 0 {
+		return populate(newConfig(), v, nil)
+	} else if len(records) > 2 {
+		return fmt.Errorf("CSV data must contain a header and a single row of values, got %d rows", len(records))
+	}
+
+	data := make(map[string]interface{})
+
+	if len(records) == 2 {
+		for i, key := range records[0] {
+			if i >= len(records[1]) {
+				break
+			}
+
+			// repeated columns become lists of values
+			if existing, ok := data[key]; ok {
+				if list, ok := existing.([]interface{}); ok {
+					data[key] = append(list, records[1][i])
+				} else {
+					data[key] = []interface{}{existing, records[1][i]}
+				}
+			} else {
+				data[key] = records[1][i]
+			}
+		}
+	}
+
+	return populate(newConfig(), v, data)
+}
+
+func parseArgFile(r io.Reader, v interface{}) error {
+	if args, err := ReadArgFile(r); err == nil {
+		if vV, index, err := prepareUnmarshal(newConfig(), v); err == nil {
+			return unmarshalArgs(args, vV, index, false, nil)
+		} else {
+			return err
+		}
+	} else {
+		return err
+	}
+}
+
+// populates the struct pointed to by v from the given data, keyed on option or field name
+func populate(cfg *Config, v interface{}, data map[string]interface{}) error {
+	vV := reflect.ValueOf(v)
+
+	if vV.Kind() != reflect.Ptr || vV.IsNil() || vV.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("pointer to struct needed, got %T", v)
+	}
+
+	return populateStruct(cfg, vV.Elem(), data)
+}
+
+func populateStruct(cfg *Config, structV reflect.Value, data map[string]interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return walkFields(cfg, structV.Type(), nil, func(path []int, field reflect.StructField, tag *argonautTag) error {
+		if fieldT := derefType(field.Type); fieldT == commandNameType || fieldT == argNameType {
+			return nil
+		}
+
+		if value, ok := lookupFieldData(cfg, data, field.Name, tag); ok && value != nil {
+			if err := setFieldFromInterface(cfg, fieldByPath(structV, path), value); err != nil {
+				return fmt.Errorf("field %s: %v", field.Name, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// locates the value for a field using its primary option name, any alternate names or aliases,
+// and finally the field name itself
+func lookupFieldData(cfg *Config, data map[string]interface{}, fieldName string, tag *argonautTag) (interface{}, bool) {
+	names := []string{cfg.primaryOption(tag, fieldName)}
+	names = append(names, tag.Options...)
+	names = append(names, tag.Aliases...)
+	names = append(names, fieldName)
+
+	for _, name := range names {
+		if value, ok := data[name]; ok {
+			return value, true
+		}
+	}
+
+	return nil, false
+}
+
+// sets the target to the given (decoded) value, converting it to the target type as necessary
+func setFieldFromInterface(cfg *Config, target reflect.Value, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	if target.Kind() == reflect.Ptr {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+
+		return setFieldFromInterface(cfg, target.Elem(), value)
+	}
+
+	valueV := reflect.ValueOf(value)
+	targetT := target.Type()
+
+	switch {
+	case valueV.Type().AssignableTo(targetT):
+		target.Set(valueV)
+		return nil
+
+	case isLeafType(targetT):
+		return setFieldValue(target, stringutil.MustString(value))
+
+	case target.Kind() == reflect.Slice && (valueV.Kind() == reflect.Slice || valueV.Kind() == reflect.Array):
+		slice := reflect.MakeSlice(targetT, 0, valueV.Len())
+
+		for i := 0; i < valueV.Len(); i++ {
+			elem := reflect.New(targetT.Elem()).Elem()
+
+			if err := setFieldFromInterface(cfg, elem, valueV.Index(i).Interface()); err != nil {
+				return err
+			}
+
+			slice = reflect.Append(slice, elem)
+		}
+
+		target.Set(slice)
+		return nil
+
+	case target.Kind() == reflect.Struct && valueV.Kind() == reflect.Map:
+		if m, ok := value.(map[string]interface{}); ok {
+			return populateStruct(cfg, target, m)
+		}
+
+	case target.Kind() == reflect.Map && valueV.Kind() == reflect.Map:
+		m := reflect.MakeMapWithSize(targetT, valueV.Len())
+
+		for _, key := range valueV.MapKeys() {
+			keyV := reflect.New(targetT.Key()).Elem()
+			elemV := reflect.New(targetT.Elem()).Elem()
+
+			if err := setFieldValue(keyV, fmt.Sprintf("%v", key.Interface())); err != nil {
+				return err
+			} else if err := setFieldFromInterface(cfg, elemV, valueV.MapIndex(key).Interface()); err != nil {
+				return err
+			}
+
+			m.SetMapIndex(keyV, elemV)
+		}
+
+		target.Set(m)
+		return nil
+
+	case valueV.Kind() != reflect.String && valueV.Type().ConvertibleTo(targetT) && target.Kind() != reflect.String:
+		target.Set(valueV.Convert(targetT))
+		return nil
+
+	case valueV.Kind() != reflect.Slice && valueV.Kind() != reflect.Map:
+		if target.Kind() == reflect.Slice {
+			target.Set(reflect.MakeSlice(targetT, 0, 1))
+		}
+
+		return setFieldValue(target, stringutil.MustString(value))
+	}
+
+	return fmt.Errorf("cannot assign %T to %v", value, targetT)
+}
@@ -0,0 +1,253 @@
+package argonaut
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type decodeTarget struct {
+	Command CommandName `argonaut:"encoder"`
+	Preset  string      `argonaut:"preset"`
+	Threads int         `argonaut:"threads|t"`
+	Quality float64     `argonaut:"q"`
+	Verbose bool        `argonaut:"verbose,long"`
+	Tags    []string    `argonaut:"tag"`
+	Name    string      `argonaut:",alias=title"`
+	Output  string      `argonaut:",positional"`
+}
+
+func TestParseFromReader(t *testing.T) {
+	assert := require.New(t)
+
+	expected := decodeTarget{
+		Preset:  `fast`,
+		Threads: 4,
+		Quality: 0.5,
+		Verbose: true,
+		Tags:    []string{`a`, `b`},
+		Name:    `test`,
+		Output:  `out.mp4`,
+	}
+
+	for format, input := range map[string]string{
+		`json`:    `{"preset": "fast", "t": 4, "q": 0.5, "verbose": true, "tag": ["a", "b"], "title": "test", "Output": "out.mp4"}`,
+		`yaml`:    "# comment\npreset: fast\nthreads: 4\nq: 0.5\nverbose: true\ntag:\n  - a\n  - b\ntitle: 'test'\nOutput: out.mp4\n",
+		`YML`:     "preset: \"fast\"\nthreads: 4\nq: 0.5\nverbose: yes\ntag: [a, b]\nname: test # comment\noutput: out.mp4\n",
+		`toml`:    "preset = \"fast\" # comment\nthreads = 4\nq = 0.5\nverbose = true\ntag = [\n  \"a\",\n  'b',\n]\ntitle = \"test\"\nOutput = \"out.mp4\"\n",
+		`csv`:     "preset,threads,q,verbose,tag,tag,title,Output\nfast,4,0.5,true,a,b,test,out.mp4\n",
+		`argfile`: "--preset\nfast\n\n--threads=4\n--q\n0.5\n--verbose\n--tag\na\n--tag\nb\n--title\ntest\nout.mp4\n",
+	} {
+		var actual decodeTarget
+
+		assert.NoError(ParseFromReader(strings.NewReader(input), format, &actual), format)
+		assert.Equal(expected, actual, format)
+	}
+}
+
+func TestParseFromReaderErrors(t *testing.T) {
+	assert := require.New(t)
+
+	var target decodeTarget
+
+	err := ParseFromReader(strings.NewReader(``), `ini`, &target)
+	assert.Error(err)
+	assert.IsType(&UnsupportedFormatError{}, err)
+	assert.Equal(`unsupported format "ini"`, err.Error())
+
+	assert.Error(ParseFromReader(strings.NewReader(`{}`), `json`, target))
+	assert.Error(ParseFromReader(strings.NewReader("a,b\n1,2\n3,4\n"), `csv`, &target))
+	assert.Error(ParseFromReader(strings.NewReader(`- a`), `yaml`, &target))
+	assert.Error(ParseFromReader(strings.NewReader("preset: |\n  fast\n"), `yaml`, &target))
+	assert.Error(ParseFromReader(strings.NewReader(`preset = """fast"""`), `toml`, &target))
+	assert.Error(ParseFromReader(strings.NewReader(`threads = "four"`), `toml`, &target))
+}
+
+func TestParseTOMLRoundTrip(t *testing.T) {
+	assert := require.New(t)
+
+	type stream struct {
+		Index int    `argonaut:"index"`
+		Codec string `argonaut:"codec"`
+	}
+
+	type encoder struct {
+		Command CommandName `argonaut:"encoder"`
+		Preset  string      `argonaut:"preset"`
+		Threads int         `argonaut:"threads|t"`
+		Quality float64     `argonaut:"q"`
+		Tags    []string    `argonaut:"tag"`
+		Output  string      `argonaut:",positional"`
+		Streams []stream    `argonaut:"stream"`
+	}
+
+	input := &encoder{
+		Preset:  `fast`,
+		Threads: 4,
+		Quality: 1,
+		Tags:    []string{`a`, "quoted \"b\""},
+		Output:  `out.mp4`,
+		Streams: []stream{
+			{Index: 0, Codec: `libx264`},
+			{Index: 1, Codec: `aac`},
+		},
+	}
+
+	data, err := MarshalTOML(input)
+	assert.NoError(err)
+
+	var output encoder
+
+	assert.NoError(ParseFromReader(bytes.NewReader(data), `toml`, &output))
+	assert.Equal(input, &output)
+}
+
+func TestParseTOML(t *testing.T) {
+	assert := require.New(t)
+
+	doc, err := parseTOML(`
+title = 'literal \n string'
+"quoted key" = 0x1F_FF
+a.b = 1_000
+inline = { x = 1, y.z = [true, false] }
+big = 1e3
+neg = -inf
+
+[server]
+host = "example.com"
+
+[[server.listen]]
+port = 80
+
+[[server.listen]]
+port = 443
+`)
+
+	assert.NoError(err)
+	assert.Equal(`literal \n string`, doc[`title`])
+	assert.Equal(int64(0x1FFF), doc[`quoted key`])
+	assert.Equal(map[string]interface{}{`b`: int64(1000)}, doc[`a`])
+	assert.Equal(map[string]interface{}{
+		`x`: int64(1),
+		`y`: map[string]interface{}{
+			`z`: []interface{}{true, false},
+		},
+	}, doc[`inline`])
+	assert.Equal(float64(1000), doc[`big`])
+	assert.Equal(map[string]interface{}{
+		`host`: `example.com`,
+		`listen`: []interface{}{
+			map[string]interface{}{`port`: int64(80)},
+			map[string]interface{}{`port`: int64(443)},
+		},
+	}, doc[`server`])
+
+	_, err = parseTOML("a = 1\na = 2")
+	assert.Error(err)
+}
+
+func TestParseYAML(t *testing.T) {
+	assert := require.New(t)
+
+	doc, err := parseYAML(`---
+name: "quoted # not a comment"
+count: 3
+ratio: .5
+empty:
+list:
+  - one
+  - key: value
+    other: 2
+  -
+    - nested
+flow: {a: 1, b: [x, "y"]}
+`)
+
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{
+		`name`:  `quoted # not a comment`,
+		`count`: int64(3),
+		`ratio`: 0.5,
+		`empty`: nil,
+		`list`: []interface{}{
+			`one`,
+			map[string]interface{}{`key`: `value`, `other`: int64(2)},
+			[]interface{}{`nested`},
+		},
+		`flow`: map[string]interface{}{
+			`a`: int64(1),
+			`b`: []interface{}{`x`, `y`},
+		},
+	}, doc)
+}
+
+func TestParseGlob(t *testing.T) {
+	assert := require.New(t)
+
+	dir, err := ioutil.TempDir(``, `argonaut-glob-`)
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, `a.json`), []byte(`{"preset": "fast", "t": 2}`), 0644))
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, `b.yml`), []byte("preset: slow\n"), 0644))
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, `c.txt`), []byte(`ignored`), 0644))
+
+	results, err := ParseGlob(filepath.Join(dir, `*.[jy]*`), &decodeTarget{
+		Threads: 8,
+	})
+
+	assert.NoError(err)
+	assert.Equal([]interface{}{
+		&decodeTarget{Preset: `fast`, Threads: 2},
+		&decodeTarget{Preset: `slow`, Threads: 8},
+	}, results)
+
+	results, err = ParseGlob(filepath.Join(dir, `b.*`), decodeTarget{})
+	assert.NoError(err)
+	assert.Equal([]interface{}{
+		decodeTarget{Preset: `slow`},
+	}, results)
+
+	results, err = ParseGlob(filepath.Join(dir, `*.nope`), &decodeTarget{})
+	assert.NoError(err)
+	assert.Empty(results)
+
+	_, err = ParseGlob(filepath.Join(dir, `*.txt`), &decodeTarget{})
+	assert.Error(err)
+
+	_, err = ParseGlob(filepath.Join(dir, `*`), `nope`)
+	assert.Error(err)
+}
+
+func TestReload(t *testing.T) {
+	assert := require.New(t)
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, `encoder.json`)
+	target := &decodeTarget{
+		Threads: 8,
+		Output:  `out.mp4`,
+	}
+
+	assert.NoError(ioutil.WriteFile(filename, []byte(`{"preset": "fast", "t": 2}`), 0644))
+	assert.NoError(Reload(filename, target))
+	assert.Equal(&decodeTarget{Preset: `fast`, Threads: 2, Output: `out.mp4`}, target)
+
+	// a file that fails partway through leaves the target untouched
+	assert.NoError(ioutil.WriteFile(filename, []byte(`{"preset": "slow", "t": "many"}`), 0644))
+	assert.Error(Reload(filename, target))
+	assert.Equal(&decodeTarget{Preset: `fast`, Threads: 2, Output: `out.mp4`}, target)
+
+	tomlFile := filepath.Join(dir, `encoder.toml`)
+	assert.NoError(ioutil.WriteFile(tomlFile, []byte("preset = \"slow\"\n"), 0644))
+	assert.NoError(Reload(tomlFile, target))
+	assert.Equal(`slow`, target.Preset)
+
+	assert.Error(Reload(filepath.Join(dir, `missing.yaml`), target))
+	assert.Error(Reload(tomlFile, decodeTarget{}))
+}
@@ -0,0 +1,47 @@
+package argonaut
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Returns the arguments generated from the given struct as a Dockerfile CMD instruction in its
+// JSON ("exec") form (e.g.: `CMD ["ls", "-l", "/tmp"]`).  Values are escaped as JSON strings, so
+// arguments containing quotes or backslashes are preserved.
+func GenerateDockerfile(v interface{}) (string, error) {
+	return dockerInstruction(`CMD`, v)
+}
+
+// Returns the arguments generated from the given struct as a Dockerfile ENTRYPOINT instruction in
+// its JSON ("exec") form (e.g.: `ENTRYPOINT ["ls", "-l", "/tmp"]`).
+func GenerateDockerEntrypoint(v interface{}) (string, error) {
+	return dockerInstruction(`ENTRYPOINT`, v)
+}
+
+func dockerInstruction(instruction string, v interface{}) (string, error) {
+	args, err := Parse(v)
+
+	if err != nil {
+		return ``, err
+	}
+
+	quoted := make([]string, len(args))
+
+	for i, arg := range args {
+		var buf bytes.Buffer
+
+		// HTML escaping would turn characters like "<" and "&" into \u sequences, which Docker
+		// reads correctly but are needlessly hard to read
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+
+		if err := encoder.Encode(arg); err != nil {
+			return ``, err
+		}
+
+		quoted[i] = strings.TrimSuffix(buf.String(), "\n")
+	}
+
+	return instruction + ` [` + strings.Join(quoted, `, `) + `]`, nil
+}
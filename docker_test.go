@@ -0,0 +1,31 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateDockerfile(t *testing.T) {
+	assert := require.New(t)
+
+	type sh struct {
+		Command CommandName `argonaut:"sh"`
+		Script  string      `argonaut:"c"`
+	}
+
+	input := &sh{
+		Script: `echo "hello" > C:\out && cat <in`,
+	}
+
+	cmd, err := GenerateDockerfile(input)
+	assert.NoError(err)
+	assert.Equal(`CMD ["sh", "-c", "echo \"hello\" > C:\\out && cat <in"]`, cmd)
+
+	entrypoint, err := GenerateDockerEntrypoint(&ls{All: true, Paths: []string{`/`}})
+	assert.NoError(err)
+	assert.Equal(`ENTRYPOINT ["ls", "--all", "/"]`, entrypoint)
+
+	_, err = GenerateDockerfile(`nope`)
+	assert.Error(err)
+}
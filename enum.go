@@ -0,0 +1,79 @@
+package argonaut
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// An Enum holds one of a fixed set of string values.  When used as a field, the current value
+// (Values[Current]) is emitted as the field's value; an Enum without any values is omitted.
+type Enum struct {
+	Values  []string
+	Current int
+}
+
+// Creates a new Enum with the given values, initially set to the first one.
+func NewEnum(values ...string) *Enum {
+	return &Enum{
+		Values: values,
+	}
+}
+
+// Returns the current value, or an empty string if the Enum has no values.
+func (self *Enum) String() string {
+	if self.Current >= 0 && self.Current < len(self.Values) {
+		return self.Values[self.Current]
+	}
+
+	return ``
+}
+
+// Advances to the next value, wrapping around to the first value after the last one.
+func (self *Enum) Next() string {
+	if len(self.Values) > 0 {
+		self.Current = (self.Current + 1) % len(self.Values)
+	}
+
+	return self.String()
+}
+
+// Moves to the previous value, wrapping around to the last value before the first one.
+func (self *Enum) Prev() string {
+	if len(self.Values) > 0 {
+		self.Current = (self.Current - 1 + len(self.Values)) % len(self.Values)
+	}
+
+	return self.String()
+}
+
+// Sets the current value, returning an error if it is not one of the Enum's values.
+func (self *Enum) Set(value string) error {
+	for i, v := range self.Values {
+		if v == value {
+			self.Current = i
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid value %q, expected one of: %s", value, strings.Join(self.Values, `, `))
+}
+
+// Implements encoding.TextUnmarshaler so that Unmarshal can set the current value.
+func (self *Enum) UnmarshalText(text []byte) error {
+	return self.Set(string(text))
+}
+
+func init() {
+	RegisterType(reflect.TypeOf(Enum{}), func(v interface{}) (string, error) {
+		enum := v.(Enum)
+
+		if len(enum.Values) == 0 {
+			return ``, nil
+		} else if enum.Current < 0 || enum.Current >= len(enum.Values) {
+			return ``, fmt.Errorf("Enum index %d is out of range (%d values)", enum.Current, len(enum.Values))
+		}
+
+		return enum.Values[enum.Current], nil
+	})
+}
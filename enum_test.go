@@ -0,0 +1,56 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnum(t *testing.T) {
+	assert := require.New(t)
+
+	type compress struct {
+		Command CommandName `argonaut:"compress"`
+		Mode    *Enum       `argonaut:"mode,long"`
+		Level   Enum        `argonaut:"l"`
+		Files   []string    `argonaut:",positional"`
+	}
+
+	input := &compress{
+		Mode:  NewEnum(`fast`, `balanced`, `best`),
+		Files: []string{`a.txt`},
+	}
+
+	assert.Equal([]string{`compress`, `--mode`, `fast`, `a.txt`}, MustParse(input))
+
+	assert.Equal(`balanced`, input.Mode.Next())
+	assert.Equal(`best`, input.Mode.Next())
+	assert.Equal(`fast`, input.Mode.Next())
+	assert.Equal(`best`, input.Mode.Prev())
+	assert.Equal([]string{`compress`, `--mode`, `best`, `a.txt`}, MustParse(input))
+
+	assert.NoError(input.Mode.Set(`balanced`))
+	assert.Equal(1, input.Mode.Current)
+	assert.Error(input.Mode.Set(`slow`))
+	assert.Equal(`balanced`, input.Mode.String())
+
+	input.Level = Enum{Values: []string{`1`, `9`}, Current: 1}
+	assert.Equal([]string{`compress`, `--mode`, `balanced`, `-l`, `9`, `a.txt`}, MustParse(input))
+
+	input.Level.Current = 5
+	_, err := Parse(input)
+	assert.Error(err)
+
+	// values are set on existing Enums when unmarshaling
+	output := &compress{
+		Mode: NewEnum(`fast`, `balanced`, `best`),
+	}
+
+	assert.NoError(Unmarshal([]string{`compress`, `--mode`, `best`, `b.txt`}, output))
+	assert.Equal(`best`, output.Mode.String())
+	assert.Error(Unmarshal([]string{`compress`, `--mode`, `slow`}, output))
+
+	var empty Enum
+	assert.Equal(``, empty.Next())
+	assert.Equal(``, empty.Prev())
+}
@@ -0,0 +1,137 @@
+package argonaut
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ghetzel/go-stockutil/sliceutil"
+	"github.com/ghetzel/go-stockutil/stringutil"
+)
+
+// Returns the field values of the given struct as a set of environment variables, which can be
+// used to pass them across a boundary that only preserves the environment (e.g.: "docker exec").
+// Each variable is named by joining the prefix and the field name in upper snake case (e.g.:
+// "MYAPP_BLOCK_SIZE"); fields of nested structs include the name of each struct along the way
+// (e.g.: "MYAPP_NETWORK_PORT").  Slices are joined with commas, and zero values are omitted.
+// Command names, maps, and slices of structs are not included.  Use DeserializeEnv to read the
+// variables back into a struct.
+func SerializeEnv(prefix string, v interface{}) (map[string]string, error) {
+	structT, err := structTypeOf(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := newConfig()
+	structV := reflect.ValueOf(v)
+	env := make(map[string]string)
+
+	err = walkFields(cfg, structT, nil, func(path []int, field reflect.StructField, tag *argonautTag) error {
+		if !isEnvField(field.Type) {
+			return nil
+		}
+
+		fieldV, ok := fieldValueByPath(structV, path)
+
+		if !ok || fieldV.IsZero() {
+			return nil
+		}
+
+		value, err := jsonValue(cfg, fieldV)
+
+		if err != nil {
+			return fmt.Errorf("field %s: %v", field.Name, err)
+		}
+
+		if items, ok := value.([]interface{}); ok {
+			env[envVarName(prefix, structT, path)] = strings.Join(sliceutil.Stringify(items), `,`)
+		} else if value != nil {
+			env[envVarName(prefix, structT, path)] = stringutil.MustString(value)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+// Populates the struct pointed to by v from environment variables named as by SerializeEnv.
+// Variables for slice fields are split on commas.  Variables that do not correspond to a field
+// are ignored.
+func DeserializeEnv(prefix string, env map[string]string, v interface{}) error {
+	vV := reflect.ValueOf(v)
+
+	if vV.Kind() != reflect.Ptr || vV.IsNil() || vV.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("pointer to struct needed, got %T", v)
+	}
+
+	cfg := newConfig()
+	structV := vV.Elem()
+
+	return walkFields(cfg, structV.Type(), nil, func(path []int, field reflect.StructField, tag *argonautTag) error {
+		if !isEnvField(field.Type) {
+			return nil
+		}
+
+		str, ok := env[envVarName(prefix, structV.Type(), path)]
+
+		if !ok {
+			return nil
+		}
+
+		var value interface{} = str
+
+		if fieldT := derefType(field.Type); fieldT.Kind() == reflect.Slice && !isLeafType(fieldT) {
+			items := make([]interface{}, 0)
+
+			if str != `` {
+				for _, item := range strings.Split(str, `,`) {
+					items = append(items, item)
+				}
+			}
+
+			value = items
+		}
+
+		if err := setFieldFromInterface(cfg, fieldByPath(structV, path), value); err != nil {
+			return fmt.Errorf("field %s: %v", field.Name, err)
+		}
+
+		return nil
+	})
+}
+
+// whether the value of a field of the given type can be represented as an environment variable
+func isEnvField(fieldT reflect.Type) bool {
+	fieldT = derefType(fieldT)
+
+	switch {
+	case fieldT == commandNameType, fieldT == argNameType, fieldT == optionSetType, fieldT == extraArgsType:
+		return false
+	case isMapType(fieldT):
+		return false
+	}
+
+	_, isStructSlice := structSliceElem(fieldT)
+	return !isStructSlice
+}
+
+// returns the environment variable name for the field at the given index path
+func envVarName(prefix string, structT reflect.Type, path []int) string {
+	parts := make([]string, 0, len(path)+1)
+
+	if prefix != `` {
+		parts = append(parts, prefix)
+	}
+
+	for _, name := range strings.Split(fieldPathName(structT, path), `.`) {
+		parts = append(parts, stringutil.Underscore(name))
+	}
+
+	return strings.ToUpper(strings.Join(parts, `_`))
+}
@@ -0,0 +1,61 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerializeEnv(t *testing.T) {
+	assert := require.New(t)
+
+	type network struct {
+		Host string `argonaut:"host,long"`
+		Port int    `argonaut:"port,long"`
+	}
+
+	type server struct {
+		Command   CommandName `argonaut:"serve"`
+		BlockSize int         `argonaut:"block-size,long"`
+		Debug     bool        `argonaut:"debug,long"`
+		Scale     float64     `argonaut:"scale,long"`
+		Tags      []string    `argonaut:"tag,long"`
+		Network   *network
+		Labels    map[string]string `argonaut:"label,long"`
+	}
+
+	input := &server{
+		BlockSize: 1024,
+		Debug:     true,
+		Scale:     1.5,
+		Tags:      []string{`a`, `b`},
+		Network:   &network{Port: 8080},
+		Labels:    map[string]string{`x`: `y`},
+	}
+
+	env, err := SerializeEnv(`myapp`, input)
+	assert.NoError(err)
+	assert.Equal(map[string]string{
+		`MYAPP_BLOCK_SIZE`:   `1024`,
+		`MYAPP_DEBUG`:        `true`,
+		`MYAPP_SCALE`:        `1.5`,
+		`MYAPP_TAGS`:         `a,b`,
+		`MYAPP_NETWORK_PORT`: `8080`,
+	}, env)
+
+	var output server
+
+	assert.NoError(DeserializeEnv(`myapp`, env, &output))
+	input.Labels = nil
+	assert.Equal(input, &output)
+
+	env, err = SerializeEnv(``, &server{Debug: true})
+	assert.NoError(err)
+	assert.Equal(map[string]string{`DEBUG`: `true`}, env)
+
+	assert.Error(DeserializeEnv(`myapp`, map[string]string{`MYAPP_BLOCK_SIZE`: `big`}, &server{}))
+	assert.Error(DeserializeEnv(`myapp`, env, server{}))
+
+	_, err = SerializeEnv(`myapp`, `nope`)
+	assert.Error(err)
+}
@@ -0,0 +1,103 @@
+package argonaut
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Returned when an argonaut struct tag cannot be parsed.
+type TagError struct {
+	StructType string
+	FieldName  string
+	TagValue   string
+	Message    string
+}
+
+func (self *TagError) Error() string {
+	return self.Message
+}
+
+// attaches the struct and field a tag error occurred on (if the error is a *TagError)
+func withTagContext(err error, structType string, fieldName string) error {
+	if terr, ok := err.(*TagError); ok {
+		terr.StructType = structType
+		terr.FieldName = fieldName
+	}
+
+	return err
+}
+
+// Describes the position within an argument list being processed by Unmarshal.
+type Cursor struct {
+	Position  int
+	Token     string
+	FieldName string
+}
+
+// Returned when Unmarshal fails to process a specific argument.
+type UnmarshalError struct {
+	Cursor Cursor
+	Err    error
+}
+
+func newUnmarshalError(cursor Cursor, err error) *UnmarshalError {
+	return &UnmarshalError{
+		Cursor: cursor,
+		Err:    err,
+	}
+}
+
+func (self *UnmarshalError) Error() string {
+	if self.Cursor.FieldName != `` {
+		return fmt.Sprintf("argument %d (%q), field %s: %v", self.Cursor.Position, self.Cursor.Token, self.Cursor.FieldName, self.Err)
+	} else {
+		return fmt.Sprintf("argument %d (%q): %v", self.Cursor.Position, self.Cursor.Token, self.Err)
+	}
+}
+
+func (self *UnmarshalError) Unwrap() error {
+	return self.Err
+}
+
+// Returned by ParseFromReader when asked to decode a format it does not support.
+type UnsupportedFormatError struct {
+	Format string
+}
+
+func (self *UnsupportedFormatError) Error() string {
+	return fmt.Sprintf("unsupported format %q", self.Format)
+}
+
+// Returned by UnmarshalStrict when one or more flags do not correspond to any field.
+type UnknownFlagsError struct {
+	Flags []string
+}
+
+func (self *UnknownFlagsError) Error() string {
+	return fmt.Sprintf("unrecognized flags: %s", strings.Join(self.Flags, `, `))
+}
+
+// Returned (wrapped in an *UnmarshalError) by Unmarshal when the StrictTypes option is enabled and
+// a value is not a literal of its field's type.
+type TypeMismatchError struct {
+	Field        string
+	ExpectedKind reflect.Kind
+	GotValue     string
+}
+
+func (self *TypeMismatchError) Error() string {
+	return fmt.Sprintf("%q is not a valid %v value", self.GotValue, self.ExpectedKind)
+}
+
+// Returned by Truncate (along with the shortened arguments) when values had to be dropped to fit
+// the command line within MaxLength characters.  Fields lists the names of the fields that values
+// were dropped from, in the order they were shortened.
+type TruncatedError struct {
+	MaxLength int
+	Fields    []string
+}
+
+func (self *TruncatedError) Error() string {
+	return fmt.Sprintf("command truncated to %d characters, dropped values of: %s", self.MaxLength, strings.Join(self.Fields, `, `))
+}
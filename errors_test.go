@@ -0,0 +1,31 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagError(t *testing.T) {
+	assert := require.New(t)
+
+	type broken struct {
+		Command CommandName `argonaut:"broken"`
+		Name    string      `argonaut:"name,label"`
+	}
+
+	_, err := Parse(&broken{Name: `x`})
+	assert.Error(err)
+	assert.Equal(`argonaut tag option "label" requires an argument`, err.Error())
+
+	terr, ok := err.(*TagError)
+	assert.True(ok)
+	assert.Equal(`argonaut.broken`, terr.StructType)
+	assert.Equal(`Name`, terr.FieldName)
+	assert.Equal(`name,label`, terr.TagValue)
+
+	err = Unmarshal([]string{`broken`}, &broken{})
+	terr, ok = err.(*TagError)
+	assert.True(ok)
+	assert.Equal(`Name`, terr.FieldName)
+}
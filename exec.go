@@ -0,0 +1,292 @@
+package argonaut
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// A WorkDir field specifies the working directory of the *exec.Cmd returned by Command.  Its value
+// does not appear in the generated arguments.
+type WorkDir string
+
+// A single environment variable.
+type EnvVar struct {
+	Key   string
+	Value string
+}
+
+// An Env field specifies environment variables to set on the *exec.Cmd returned by Command.  The
+// variables are added to the current process' environment, unless the field's tag specifies the
+// "clean_env" option, in which case they replace it.  The variables do not appear in the generated
+// arguments.
+type Env []EnvVar
+
+// A Redirect field connects the standard streams of the *exec.Cmd returned by Command to files.
+// Each member is the path of the file to use for that stream; an empty path leaves the stream
+// inherited from the parent.  Stdout and Stderr files are truncated unless the field's tag
+// specifies "append=true".  The files are opened when the command is started (not by Command
+// itself), and closed once it has exited.  Redirects do not appear in the generated arguments.
+type Redirect struct {
+	Stdin  string
+	Stdout string
+	Stderr string
+}
+
+var workDirType = reflect.TypeOf(WorkDir(``))
+var envType = reflect.TypeOf(Env{})
+var redirectType = reflect.TypeOf(Redirect{})
+
+// settings collected from a struct that configure an *exec.Cmd rather than its arguments
+type execOptions struct {
+	Dir      string
+	Env      []string
+	CleanEnv bool
+	NoExpand bool
+	Redirect Redirect
+	Append   bool
+}
+
+// whether fields of the given type configure the *exec.Cmd instead of generating arguments
+func isExecOptionType(t reflect.Type) bool {
+	return t == workDirType || t == envType || t == redirectType
+}
+
+// gathers the execOptions from the given struct (and any nested structs)
+func collectExecOptions(cfg *Config, structV reflect.Value, opts *execOptions) error {
+	for structV.Kind() == reflect.Ptr {
+		if structV.IsNil() {
+			return nil
+		}
+
+		structV = structV.Elem()
+	}
+
+	return collectExecOptionsFrom(cfg, structV, opts, true)
+}
+
+func collectExecOptionsFrom(cfg *Config, structV reflect.Value, opts *execOptions, toplevel bool) error {
+	structT := structV.Type()
+	defaults := cfg.defaultTag()
+
+	for i := 0; i < structT.NumField(); i++ {
+		field := structT.Field(i)
+		rawTag := field.Tag.Get(`argonaut`)
+
+		if field.PkgPath != `` || rawTag == `-` {
+			continue
+		}
+
+		tag, err := parseTag(rawTag, &defaults)
+
+		if err != nil {
+			return withTagContext(err, structT.String(), field.Name)
+		}
+
+		fieldV := reflect.Indirect(structV.Field(i))
+
+		if !fieldV.IsValid() {
+			continue
+		}
+
+		switch fieldT := fieldV.Type(); {
+		case fieldT == commandNameType:
+			// only the toplevel command name is used as the command to execute
+			if toplevel && tag.NoExpand {
+				opts.NoExpand = true
+			}
+
+		case fieldT == workDirType:
+			if dir := fieldV.String(); dir != `` && opts.Dir == `` {
+				opts.Dir = dir
+			}
+
+		case fieldT == envType:
+			for _, envvar := range fieldV.Interface().(Env) {
+				opts.Env = append(opts.Env, envvar.Key+`=`+envvar.Value)
+			}
+
+			if tag.CleanEnv {
+				opts.CleanEnv = true
+			}
+
+		case fieldT == redirectType:
+			redirect := fieldV.Interface().(Redirect)
+
+			if redirect.Stdin != `` {
+				opts.Redirect.Stdin = redirect.Stdin
+			}
+
+			if redirect.Stdout != `` {
+				opts.Redirect.Stdout = redirect.Stdout
+			}
+
+			if redirect.Stderr != `` {
+				opts.Redirect.Stderr = redirect.Stderr
+			}
+
+			if tag.Append {
+				opts.Append = true
+			}
+
+		case fieldT.Kind() == reflect.Struct && !isLeafType(fieldT):
+			if err := collectExecOptionsFrom(cfg, fieldV, opts, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// expands a leading "~" in the command name to the current user's home directory (unless the
+// CommandName field specifies the "no_expand" option)
+func (self *execOptions) expandCommand(name string) (string, error) {
+	if self.NoExpand || (name != `~` && !strings.HasPrefix(name, `~/`)) {
+		return name, nil
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, strings.TrimPrefix(name, `~`)), nil
+	} else {
+		return ``, fmt.Errorf("Cannot expand command %q: %v", name, err)
+	}
+}
+
+func (self *execOptions) apply(cmd *exec.Cmd) error {
+	if self.Dir != `` {
+		cmd.Dir = self.Dir
+	}
+
+	if self.CleanEnv {
+		cmd.Env = append([]string{}, self.Env...)
+	} else if len(self.Env) > 0 {
+		cmd.Env = append(os.Environ(), self.Env...)
+	}
+
+	return self.openRedirects(cmd)
+}
+
+// attaches the files named by the Redirect options to the command's standard streams.  The files
+// are not opened until the command is started, and are closed once the command's streams have
+// been copied to or from them (i.e.: once Wait returns).  Missing input files are reported here.
+func (self *execOptions) openRedirects(cmd *exec.Cmd) error {
+	outflag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+
+	if self.Append {
+		outflag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+
+	if path := self.Redirect.Stdin; path != `` {
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("Cannot open redirect: %v", err)
+		}
+
+		cmd.Stdin = &redirectInput{
+			path: path,
+		}
+	}
+
+	if path := self.Redirect.Stdout; path != `` {
+		cmd.Stdout = &redirectOutput{
+			path: path,
+			flag: outflag,
+		}
+	}
+
+	if path := self.Redirect.Stderr; path != `` {
+		if path == self.Redirect.Stdout {
+			// share a single file (like "> file 2>&1") so writes to both streams don't clobber each other
+			cmd.Stderr = cmd.Stdout
+		} else {
+			cmd.Stderr = &redirectOutput{
+				path: path,
+				flag: outflag,
+			}
+		}
+	}
+
+	return nil
+}
+
+// the standard input of a command, read from a file that is only open while it is being copied
+type redirectInput struct {
+	path string
+	file *os.File
+}
+
+// copies the whole file to w (this is how exec.Cmd reads the command's input), closing it afterwards
+func (self *redirectInput) WriteTo(w io.Writer) (int64, error) {
+	if file, err := os.Open(self.path); err == nil {
+		defer file.Close()
+		return io.Copy(w, file)
+	} else {
+		return 0, fmt.Errorf("Cannot open redirect: %v", err)
+	}
+}
+
+func (self *redirectInput) Read(p []byte) (int, error) {
+	if self.file == nil {
+		if file, err := os.Open(self.path); err == nil {
+			self.file = file
+		} else {
+			return 0, fmt.Errorf("Cannot open redirect: %v", err)
+		}
+	}
+
+	n, err := self.file.Read(p)
+
+	if err != nil {
+		self.file.Close()
+	}
+
+	return n, err
+}
+
+// the standard output (or error) of a command, written to a file that is only open while it is
+// being copied to.  The file is truncated (unless appending) when the first copy starts.
+type redirectOutput struct {
+	path    string
+	flag    int
+	started bool
+}
+
+func (self *redirectOutput) open() (*os.File, error) {
+	flag := self.flag
+
+	if self.started {
+		flag = (flag &^ os.O_TRUNC) | os.O_APPEND
+	}
+
+	self.started = true
+
+	if file, err := os.OpenFile(self.path, flag, 0644); err == nil {
+		return file, nil
+	} else {
+		return nil, fmt.Errorf("Cannot open redirect: %v", err)
+	}
+}
+
+// copies everything from r to the file (this is how exec.Cmd writes the command's output), closing
+// it afterwards
+func (self *redirectOutput) ReadFrom(r io.Reader) (int64, error) {
+	if file, err := self.open(); err == nil {
+		defer file.Close()
+		return io.Copy(file, r)
+	} else {
+		return 0, err
+	}
+}
+
+func (self *redirectOutput) Write(p []byte) (int, error) {
+	if file, err := self.open(); err == nil {
+		defer file.Close()
+		return file.Write(p)
+	} else {
+		return 0, err
+	}
+}
@@ -0,0 +1,311 @@
+package argonaut
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkDir(t *testing.T) {
+	assert := require.New(t)
+
+	type build struct {
+		Command CommandName `argonaut:"make"`
+		Dir     WorkDir
+		Jobs    int    `argonaut:"j"`
+		Target  string `argonaut:",positional"`
+	}
+
+	input := &build{
+		Dir:    `/tmp`,
+		Jobs:   4,
+		Target: `all`,
+	}
+
+	assert.Equal([]string{`make`, `-j`, `4`, `all`}, MustParse(input))
+
+	cmd, err := Command(input)
+	assert.NoError(err)
+	assert.Equal(`/tmp`, cmd.Dir)
+	assert.Equal([]string{`make`, `-j`, `4`, `all`}, cmd.Args)
+
+	cmd, err = Command(&build{
+		Target: `all`,
+	})
+
+	assert.NoError(err)
+	assert.Equal(``, cmd.Dir)
+
+	// nested structs may also specify the working directory
+	type wrapper struct {
+		Command CommandName `argonaut:"make"`
+		Options *struct {
+			Dir *WorkDir
+		}
+	}
+
+	dir := WorkDir(`/var`)
+	wrapped := &wrapper{}
+	wrapped.Options = &struct{ Dir *WorkDir }{Dir: &dir}
+
+	cmd, err = Command(wrapped)
+	assert.NoError(err)
+	assert.Equal(`/var`, cmd.Dir)
+	assert.Equal([]string{`make`}, cmd.Args)
+
+	names, err := FieldNames(&build{})
+	assert.NoError(err)
+	assert.Equal([]string{`-j`}, names)
+}
+
+func TestEnv(t *testing.T) {
+	assert := require.New(t)
+
+	type build struct {
+		Command CommandName `argonaut:"make"`
+		Env     Env
+		Target  string `argonaut:",positional"`
+	}
+
+	type cleanBuild struct {
+		Command CommandName `argonaut:"make"`
+		Env     Env         `argonaut:",clean_env"`
+		Target  string      `argonaut:",positional"`
+	}
+
+	env := Env{
+		{Key: `CC`, Value: `clang`},
+		{Key: `CFLAGS`, Value: `-O2 -g`},
+	}
+
+	assert.Equal([]string{`make`, `all`}, MustParse(&build{
+		Env:    env,
+		Target: `all`,
+	}))
+
+	cmd, err := Command(&build{
+		Env:    env,
+		Target: `all`,
+	})
+
+	assert.NoError(err)
+	assert.Equal([]string{`make`, `all`}, cmd.Args)
+	assert.Equal(len(os.Environ())+2, len(cmd.Env))
+	assert.Equal([]string{`CC=clang`, `CFLAGS=-O2 -g`}, cmd.Env[len(cmd.Env)-2:])
+
+	cmd, err = Command(&cleanBuild{
+		Env:    env,
+		Target: `all`,
+	})
+
+	assert.NoError(err)
+	assert.Equal([]string{`CC=clang`, `CFLAGS=-O2 -g`}, cmd.Env)
+
+	// an empty clean environment is still applied
+	cmd, err = Command(&cleanBuild{
+		Target: `all`,
+	})
+
+	assert.NoError(err)
+	assert.NotNil(cmd.Env)
+	assert.Empty(cmd.Env)
+
+	// no Env fields leave the environment alone
+	cmd, err = Command(&build{
+		Target: `all`,
+	})
+
+	assert.NoError(err)
+	assert.Nil(cmd.Env)
+}
+
+func TestCommandHomeExpansion(t *testing.T) {
+	assert := require.New(t)
+
+	type tool struct {
+		Command CommandName `argonaut:"tool"`
+		Verbose bool        `argonaut:"v"`
+	}
+
+	type literal struct {
+		Command CommandName `argonaut:"tool,no_expand"`
+		Verbose bool        `argonaut:"v"`
+	}
+
+	home, err := os.UserHomeDir()
+	assert.NoError(err)
+
+	input := &tool{
+		Command: `~/bin/my-tool`,
+		Verbose: true,
+	}
+
+	// Parse and Marshal never expand the command name
+	assert.Equal([]string{`~/bin/my-tool`, `-v`}, MustParse(input))
+
+	cmd, err := Command(input)
+	assert.NoError(err)
+	assert.Equal(filepath.Join(home, `bin`, `my-tool`), cmd.Path)
+	assert.Equal([]string{filepath.Join(home, `bin`, `my-tool`), `-v`}, cmd.Args)
+
+	cmd, err = Command(&literal{
+		Command: `~/bin/my-tool`,
+	})
+
+	assert.NoError(err)
+	assert.Equal([]string{`~/bin/my-tool`}, cmd.Args)
+
+	// only a leading "~/" is expanded
+	cmd, err = Command(&tool{
+		Command: `~other/bin/tool`,
+	})
+
+	assert.NoError(err)
+	assert.Equal([]string{`~other/bin/tool`}, cmd.Args)
+}
+
+func TestRedirect(t *testing.T) {
+	assert := require.New(t)
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, `input.txt`)
+	output := filepath.Join(dir, `output.txt`)
+
+	assert.NoError(os.WriteFile(input, []byte("hello\n"), 0644))
+
+	type cat struct {
+		Command CommandName `argonaut:"cat"`
+		Streams Redirect
+		Number  bool `argonaut:"n"`
+	}
+
+	cmd, err := Command(&cat{
+		Streams: Redirect{
+			Stdin:  input,
+			Stdout: output,
+		},
+	})
+
+	assert.NoError(err)
+	assert.Equal([]string{`cat`}, cmd.Args)
+	assert.Nil(cmd.Stderr)
+	assert.NoError(cmd.Run())
+
+	data, err := os.ReadFile(output)
+	assert.NoError(err)
+	assert.Equal("hello\n", string(data))
+
+	// output files are truncated by default...
+	cmd, err = Command(&cat{
+		Streams: Redirect{
+			Stdin:  input,
+			Stdout: output,
+		},
+	})
+
+	assert.NoError(err)
+	assert.NoError(cmd.Run())
+
+	data, err = os.ReadFile(output)
+	assert.NoError(err)
+	assert.Equal("hello\n", string(data))
+
+	// ...unless the append option is given
+	type appender struct {
+		Command CommandName `argonaut:"cat"`
+		Streams *Redirect   `argonaut:",append=true"`
+	}
+
+	cmd, err = Command(&appender{
+		Streams: &Redirect{
+			Stdin:  input,
+			Stdout: output,
+		},
+	})
+
+	assert.NoError(err)
+	assert.Equal([]string{`cat`}, cmd.Args)
+	assert.NoError(cmd.Run())
+
+	data, err = os.ReadFile(output)
+	assert.NoError(err)
+	assert.Equal("hello\nhello\n", string(data))
+
+	// missing input files are reported by Command
+	_, err = Command(&cat{
+		Streams: Redirect{
+			Stdin: filepath.Join(dir, `missing.txt`),
+		},
+	})
+
+	assert.Error(err)
+
+	_, err = Parse(&struct {
+		Command CommandName `argonaut:"cat"`
+		Streams Redirect    `argonaut:",append=maybe"`
+	}{})
+
+	assert.Error(err)
+}
+
+func TestRedirectFilesClosed(t *testing.T) {
+	assert := require.New(t)
+
+	if _, err := os.Stat(`/proc/self/fd`); err != nil {
+		t.Skip("open file descriptors cannot be counted on this platform")
+	}
+
+	openFiles := func() int {
+		entries, err := os.ReadDir(`/proc/self/fd`)
+		assert.NoError(err)
+		return len(entries)
+	}
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, `input.txt`)
+	output := filepath.Join(dir, `output.txt`)
+	stderr := filepath.Join(dir, `stderr.txt`)
+
+	assert.NoError(os.WriteFile(input, []byte("hello\n"), 0644))
+
+	type cat struct {
+		Command CommandName `argonaut:"cat"`
+		Streams Redirect
+	}
+
+	redirected := &cat{
+		Streams: Redirect{
+			Stdin:  input,
+			Stdout: output,
+			Stderr: stderr,
+		},
+	}
+
+	before := openFiles()
+
+	for i := 0; i < 20; i++ {
+		cmd, err := Command(redirected)
+		assert.NoError(err)
+		assert.NoError(cmd.Run())
+	}
+
+	assert.Equal(before, openFiles())
+
+	data, err := os.ReadFile(output)
+	assert.NoError(err)
+	assert.Equal("hello\n", string(data))
+
+	// commands that are never started do not open (or truncate) their files
+	for i := 0; i < 20; i++ {
+		_, err := Command(redirected)
+		assert.NoError(err)
+	}
+
+	assert.Equal(before, openFiles())
+
+	data, err = os.ReadFile(output)
+	assert.NoError(err)
+	assert.Equal("hello\n", string(data))
+}
@@ -0,0 +1,249 @@
+package argonaut
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var commandNameType = reflect.TypeOf(CommandName(``))
+var argNameType = reflect.TypeOf(ArgName(``))
+
+// called for each field visited by walkFields, along with the index path leading to that field
+type fieldVisitor func(path []int, field reflect.StructField, tag *argonautTag) error
+
+// walks the exported, non-skipped fields of the given struct type in declaration order, descending
+// into nested (or embedded) structs.  Fields that refer back to a struct type that is already being
+// walked (e.g.: a "Child *Node" or "Children []Node" field of Node) are skipped, since the fields
+// they hold cannot be enumerated from the type alone.
+func walkFields(cfg *Config, structT reflect.Type, path []int, fn fieldVisitor) error {
+	return walkFieldsWithin(cfg, nil, structT, path, fn)
+}
+
+// walks the fields of structT as walkFields does, from within a walk of the given enclosing struct
+// types (outermost first), as when walking the elements of a slice of structs (see enclosingTypes)
+func walkFieldsWithin(cfg *Config, enclosing []reflect.Type, structT reflect.Type, path []int, fn fieldVisitor) error {
+	enclosing = append(enclosing[:len(enclosing):len(enclosing)], structT)
+	defaults := cfg.defaultTag()
+
+	for i := 0; i < structT.NumField(); i++ {
+		field := structT.Field(i)
+		rawTag := field.Tag.Get(`argonaut`)
+
+		if field.PkgPath != `` || rawTag == `-` {
+			continue
+		}
+
+		tag, err := parseTag(rawTag, &defaults)
+
+		if err != nil {
+			return withTagContext(err, structT.String(), field.Name)
+		}
+
+		fieldPath := append(append([]int{}, path...), i)
+		fieldT := derefType(field.Type)
+
+		if isExecOptionType(fieldT) || refersToEnclosing(enclosing, fieldT) {
+			continue
+		}
+
+		if fieldT == commandNameType {
+			// CommandName tags set the defaults for all subsequent peer fields
+			defaults.Delimiters = tag.Delimiters
+			defaults.Joiner = tag.Joiner
+			defaults.KeyPartJoiner = tag.KeyPartJoiner
+		} else if fieldT.Kind() == reflect.Struct && !isLeafType(fieldT) && fieldT != optionSetType {
+			if err := walkFieldsWithin(cfg, enclosing, fieldT, fieldPath, fn); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := fn(fieldPath, field, &tag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// returns whether the given field type is (or is a slice of) one of the given struct types
+func refersToEnclosing(enclosing []reflect.Type, fieldT reflect.Type) bool {
+	if elemT, ok := structSliceElem(fieldT); ok {
+		fieldT = elemT
+	}
+
+	for _, structT := range enclosing {
+		if fieldT == structT {
+			return true
+		}
+	}
+
+	return false
+}
+
+// returns the given enclosing struct types followed by structT and the nested structs within it that
+// lead to the field at the given index path, for walking the elements of a slice of structs held by
+// that field
+func enclosingTypes(enclosing []reflect.Type, structT reflect.Type, path []int) []reflect.Type {
+	types := append(make([]reflect.Type, 0, len(enclosing)+len(path)), enclosing...)
+	current := structT
+
+	for _, i := range path {
+		types = append(types, current)
+		current = derefType(current.Field(i).Type)
+	}
+
+	return types
+}
+
+// returns the struct type that v is or points to
+func structTypeOf(v interface{}) (reflect.Type, error) {
+	if vT := reflect.TypeOf(v); vT != nil {
+		if vT = derefType(vT); vT.Kind() == reflect.Struct {
+			return vT, nil
+		}
+	}
+
+	return nil, fmt.Errorf("struct needed, got %T", v)
+}
+
+// retrieves the field at the given index path; returns false if a nil pointer is encountered
+func fieldValueByPath(structV reflect.Value, path []int) (reflect.Value, bool) {
+	current := structV
+
+	for _, i := range path {
+		for current.Kind() == reflect.Ptr {
+			if current.IsNil() {
+				return reflect.Value{}, false
+			}
+
+			current = current.Elem()
+		}
+
+		current = current.Field(i)
+	}
+
+	return current, true
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// returns the elem type of slices of structs that are processed one group of flags per element
+func structSliceElem(t reflect.Type) (reflect.Type, bool) {
+	if t = derefType(t); t == orderedMapType {
+		return nil, false
+	} else if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		if elemT := derefType(t.Elem()); elemT.Kind() == reflect.Struct && !isLeafType(elemT) {
+			return elemT, true
+		}
+	}
+
+	return nil, false
+}
+
+// returns the kind of the elements of slices of booleans and numbers, which are emitted element by
+// element with the same handling as scalar fields of that kind
+func scalarSliceElem(t reflect.Type) (reflect.Kind, bool) {
+	if t == nil {
+		return reflect.Invalid, false
+	} else if t = derefType(t); (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && !isLeafType(t) {
+		if elemT := derefType(t.Elem()); !isLeafType(elemT) {
+			if kind := elemT.Kind(); kind == reflect.Bool || isNumericKind(kind) {
+				return kind, true
+			}
+		}
+	}
+
+	return reflect.Invalid, false
+}
+
+// Returns the flag names (including the leading "-" or "--") of all fields in the given struct, in
+// field declaration order.  Nested structs are included; command names, positional arguments, and
+// fields that modify other arguments (e.g.: "suffixprev" and "skipname") are not.
+func FieldNames(v interface{}) ([]string, error) {
+	if structT, err := structTypeOf(v); err == nil {
+		names := make([]string, 0)
+
+		if err := collectFieldNames(newConfig(), nil, structT, &names); err == nil {
+			return names, nil
+		} else {
+			return nil, err
+		}
+	} else {
+		return nil, err
+	}
+}
+
+func collectFieldNames(cfg *Config, enclosing []reflect.Type, structT reflect.Type, names *[]string) error {
+	return walkFieldsWithin(cfg, enclosing, structT, nil, func(path []int, field reflect.StructField, tag *argonautTag) error {
+		fieldT := derefType(field.Type)
+
+		if elemT, ok := structSliceElem(fieldT); ok {
+			return collectFieldNames(cfg, enclosingTypes(enclosing, structT, path), elemT, names)
+		}
+
+		switch {
+		case fieldT == commandNameType, fieldT == optionSetType, fieldT == extraArgsType, isMapType(fieldT):
+			return nil
+		case tag.Positional, tag.SuffixPrevious, tag.SkipName:
+			return nil
+		case fieldT == argNameType:
+			*names = append(*names, tag.ArgNamePrefix()+cfg.argNameLabel(tag, field.Name))
+		default:
+			*names = append(*names, tag.OptionPrefix()+cfg.primaryOption(tag, field.Name))
+		}
+
+		return nil
+	})
+}
+
+// Calls fn for each field of the given struct that holds a non-zero value, in field declaration
+// order (including the fields of nested structs).  The function receives the Go field name, the
+// flag name the field is emitted with (including the leading "-" or "--"), and the field's value.
+// The flag name is empty for positional arguments and fields that modify other arguments (e.g.:
+// "suffixprev" and "skipname").  Command names are not included.  If fn returns an error,
+// iteration stops and the error is returned.
+func ForEach(v interface{}, fn func(field string, flag string, value interface{}) error) error {
+	structT, err := structTypeOf(v)
+
+	if err != nil {
+		return err
+	}
+
+	cfg := newConfig()
+	structV := reflect.ValueOf(v)
+
+	return walkFields(cfg, structT, nil, func(path []int, field reflect.StructField, tag *argonautTag) error {
+		fieldT := derefType(field.Type)
+
+		if fieldT == commandNameType {
+			return nil
+		}
+
+		fieldV, ok := fieldValueByPath(structV, path)
+
+		if !ok || fieldV.IsZero() {
+			return nil
+		}
+
+		var flag string
+
+		switch {
+		case tag.Positional, tag.SuffixPrevious, tag.SkipName:
+			flag = ``
+		case fieldT == argNameType:
+			flag = tag.ArgNamePrefix() + cfg.argNameLabel(tag, field.Name)
+		default:
+			flag = tag.OptionPrefix() + cfg.primaryOption(tag, field.Name)
+		}
+
+		return fn(field.Name, flag, fieldV.Interface())
+	})
+}
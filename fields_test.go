@@ -0,0 +1,129 @@
+package argonaut
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldNames(t *testing.T) {
+	assert := require.New(t)
+
+	names, err := FieldNames(&ls{})
+	assert.NoError(err)
+	assert.Equal([]string{
+		`--all`,
+		`-l`,
+		`--block-size`,
+		`--cool-stuff`,
+		`--human-readable`,
+	}, names)
+
+	names, err = FieldNames(CodecOptions{})
+	assert.NoError(err)
+	assert.Equal([]string{`-codec`}, names)
+
+	names, err = FieldNames(InputOptions{})
+	assert.NoError(err)
+	assert.Equal([]string{`-f`, `-codec`, `-t`, `-ss`, `-sseof`, `-itsoffset`, `-metadata`, `--key`, `--value`, `-i`}, names)
+
+	_, err = FieldNames(`nope`)
+	assert.Error(err)
+}
+
+type treeNode struct {
+	Command  CommandName `argonaut:"tree"`
+	Name     string      `argonaut:"name,long"`
+	Parent   *treeNode
+	Children []treeNode
+	Links    []treeLink
+}
+
+type treeLink struct {
+	Target string `argonaut:"target,long"`
+	Nodes  []*treeNode
+}
+
+func TestRecursiveTypes(t *testing.T) {
+	assert := require.New(t)
+
+	// fields that refer back to a struct being walked are skipped rather than walked forever
+	names, err := FieldNames(&treeNode{})
+	assert.NoError(err)
+	assert.Equal([]string{`--name`, `--target`}, names)
+
+	assert.Len(Inspect(&treeNode{}), 2)
+
+	schema, err := Schema(&treeNode{})
+	assert.NoError(err)
+	assert.Len(schema.Properties, 2)
+
+	_, err = MarshalTOML(&treeNode{Name: `root`})
+	assert.NoError(err)
+
+	_, err = MarshalYAML(&treeNode{Name: `root`})
+	assert.NoError(err)
+
+	_, err = GenerateHelp(&treeNode{})
+	assert.NoError(err)
+
+	var node treeNode
+
+	assert.NoError(ParseJSON(strings.NewReader(`{"name": "root"}`), &node))
+	assert.Equal(`root`, node.Name)
+}
+
+func TestForEach(t *testing.T) {
+	assert := require.New(t)
+
+	type visit struct {
+		Field string
+		Flag  string
+		Value interface{}
+	}
+
+	var visits []visit
+
+	assert.NoError(ForEach(&ls{
+		All:       true,
+		BlockSize: 1024,
+		Paths:     []string{`/foo`},
+	}, func(field string, flag string, value interface{}) error {
+		visits = append(visits, visit{field, flag, value})
+		return nil
+	}))
+
+	assert.Equal([]visit{
+		{`All`, `--all`, true},
+		{`BlockSize`, `--block-size`, 1024},
+		{`Paths`, ``, []string{`/foo`}},
+	}, visits)
+
+	visits = nil
+
+	assert.NoError(ForEach(CodecOptions{
+		Stream: `a`,
+		Codec:  `copy`,
+	}, func(field string, flag string, value interface{}) error {
+		visits = append(visits, visit{field, flag, value})
+		return nil
+	}))
+
+	assert.Equal([]visit{
+		{`Stream`, ``, `a`},
+		{`Codec`, ``, `copy`},
+	}, visits)
+
+	// errors stop the iteration
+	calls := 0
+
+	assert.EqualError(ForEach(&ls{All: true, LongFormat: true}, func(field string, flag string, value interface{}) error {
+		calls += 1
+		return fmt.Errorf("stop at %s", flag)
+	}), `stop at --all`)
+
+	assert.Equal(1, calls)
+	assert.Error(ForEach(`nope`, nil))
+}
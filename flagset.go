@@ -0,0 +1,29 @@
+package argonaut
+
+import (
+	"flag"
+	"fmt"
+)
+
+// Populates the struct pointed to by v from the flags of the given (parsed) flag.FlagSet, which
+// allows argonaut struct definitions to be used alongside the standard flag package.  Each field is
+// matched to the flag named after its option names, aliases, or field name, as with ParseJSON.
+// Every flag defined in the set is used, so fields are set to the default value of any flag that
+// does not appear on the command line.
+func ParseFromFlagSet(fs *flag.FlagSet, v interface{}) error {
+	if fs == nil {
+		return fmt.Errorf("A flag set is required")
+	}
+
+	data := make(map[string]interface{})
+
+	fs.VisitAll(func(f *flag.Flag) {
+		if getter, ok := f.Value.(flag.Getter); ok {
+			data[f.Name] = getter.Get()
+		} else {
+			data[f.Name] = f.Value.String()
+		}
+	})
+
+	return populate(newConfig(), v, data)
+}
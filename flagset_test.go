@@ -0,0 +1,62 @@
+package argonaut
+
+import (
+	"flag"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type flagSetList []string
+
+func (self *flagSetList) String() string {
+	return strings.Join(*self, `,`)
+}
+
+func (self *flagSetList) Set(value string) error {
+	*self = append(*self, value)
+	return nil
+}
+
+func TestParseFromFlagSet(t *testing.T) {
+	assert := require.New(t)
+
+	type fetch struct {
+		Command CommandName   `argonaut:"fetch"`
+		URL     string        `argonaut:"url,long"`
+		Retries int           `argonaut:"retries|r,long"`
+		Timeout time.Duration `argonaut:"timeout,long"`
+		Verbose bool          `argonaut:"v,alias=verbose"`
+		Headers string        `argonaut:"header,long"`
+		Output  string
+	}
+
+	fs := flag.NewFlagSet(`fetch`, flag.ContinueOnError)
+	fs.String(`url`, ``, `the URL to fetch`)
+	fs.Int(`r`, 3, `number of retries`)
+	fs.Duration(`timeout`, 0, `request timeout`)
+	fs.Bool(`verbose`, false, `verbose output`)
+	fs.Var(&flagSetList{}, `header`, `request headers`)
+	fs.String(`Output`, `out.html`, `output file`)
+
+	assert.NoError(fs.Parse([]string{
+		`-url`, `https://example.com`, `-timeout`, `5s`, `-verbose`, `-header`, `A: 1`, `-header`, `B: 2`,
+	}))
+
+	var out fetch
+
+	assert.NoError(ParseFromFlagSet(fs, &out))
+	assert.Equal(fetch{
+		URL:     `https://example.com`,
+		Retries: 3,
+		Timeout: 5 * time.Second,
+		Verbose: true,
+		Headers: `A: 1,B: 2`,
+		Output:  `out.html`,
+	}, out)
+
+	assert.Error(ParseFromFlagSet(nil, &out))
+	assert.Error(ParseFromFlagSet(fs, out))
+}
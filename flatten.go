@@ -0,0 +1,136 @@
+package argonaut
+
+import (
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Returns the values of the given struct's fields keyed by their resolved flag names (without any
+// leading dashes), for use in places like template engines.  Values are returned as native Go
+// types rather than strings: booleans as bool, integers as int64 (or uint64 for unsigned values
+// larger than math.MaxInt64), floating-point numbers as float64, strings (including named string
+// types) as string, and slices as []interface{}.  Nil pointers map to nil.  Positional fields are keyed by their formatted field name, map fields
+// contribute one entry per (joined) key, and an OptionSet contributes its active alternative.
+// Fields within nil nested structs, repeated structs, and fields that modify other arguments are
+// not included.
+func Flatten(v interface{}) (map[string]interface{}, error) {
+	structT, err := structTypeOf(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := newConfig()
+	structV := reflect.ValueOf(v)
+	out := make(map[string]interface{})
+
+	err = walkFields(cfg, structT, nil, func(path []int, field reflect.StructField, tag *argonautTag) error {
+		fieldT := derefType(field.Type)
+
+		if _, ok := structSliceElem(fieldT); ok {
+			return nil
+		}
+
+		switch {
+		case fieldT == commandNameType, fieldT == argNameType, fieldT == extraArgsType:
+			return nil
+		case tag.SuffixPrevious, tag.SkipName:
+			return nil
+		}
+
+		fieldV, ok := fieldValueByPath(structV, path)
+
+		if !ok {
+			return nil
+		}
+
+		for fieldV.Kind() == reflect.Ptr && !fieldV.IsNil() {
+			fieldV = fieldV.Elem()
+		}
+
+		var name string
+
+		if tag.Positional {
+			name = cfg.fmtCommandWord(field.Name)
+		} else {
+			name = cfg.primaryOption(tag, field.Name)
+		}
+
+		switch {
+		case fieldV.Kind() == reflect.Ptr:
+			out[name] = nil
+
+		case fieldT == optionSetType:
+			set, _ := asOptionSet(fieldV.Interface())
+
+			if alt, ok, err := set.Active(); err != nil {
+				return err
+			} else if ok {
+				out[alt.Key] = flattenValue(reflect.ValueOf(alt.Value))
+			}
+
+		case isMapType(fieldT):
+			return walkMapArguments(fieldV.Interface(), nil, func(key []string, value interface{}) error {
+				out[strings.Join(key, tag.KeyPartJoiner)] = flattenValue(reflect.ValueOf(value))
+				return nil
+			})
+
+		default:
+			out[name] = flattenValue(fieldV)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// converts the given value into the native type used by Flatten
+func flattenValue(rV reflect.Value) interface{} {
+	for rV.Kind() == reflect.Ptr || rV.Kind() == reflect.Interface {
+		if rV.IsNil() {
+			return nil
+		}
+
+		rV = rV.Elem()
+	}
+
+	switch rV.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Bool:
+		return rV.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rV.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		// unsigned values too large for an int64 are kept as uint64 rather than wrapping around
+		if n := rV.Uint(); n <= math.MaxInt64 {
+			return int64(n)
+		} else {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		return rV.Float()
+	case reflect.String:
+		return rV.String()
+	case reflect.Slice, reflect.Array:
+		if rV.Kind() == reflect.Slice && rV.IsNil() {
+			return nil
+		}
+
+		values := make([]interface{}, rV.Len())
+
+		for i := 0; i < rV.Len(); i++ {
+			values[i] = flattenValue(rV.Index(i))
+		}
+
+		return values
+	default:
+		return rV.Interface()
+	}
+}
@@ -0,0 +1,89 @@
+package argonaut
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlatten(t *testing.T) {
+	assert := require.New(t)
+
+	type network struct {
+		Host string `argonaut:"host,long"`
+		Port uint16 `argonaut:"port|p"`
+	}
+
+	type server struct {
+		Command  CommandName `argonaut:"serve"`
+		Verbose  bool        `argonaut:"verbose|v"`
+		Quiet    bool        `argonaut:"q"`
+		Workers  int         `argonaut:"workers,long"`
+		Ratio    float32     `argonaut:"ratio,long"`
+		Timeout  *int        `argonaut:"timeout,long"`
+		Tags     []string    `argonaut:"tag,long"`
+		Network  network
+		Extra    *network
+		Settings map[string]interface{} `argonaut:",long"`
+		Mode     OptionSet
+		Roots    []string `argonaut:",positional"`
+	}
+
+	mode := NewOptionSet(`dev`, `prod`)
+	assert.NoError(mode.Set(`prod`, true))
+
+	flat, err := Flatten(&server{
+		Verbose: true,
+		Workers: 8,
+		Ratio:   0.5,
+		Tags:    []string{`a`, `b`},
+		Network: network{
+			Host: `localhost`,
+			Port: 8080,
+		},
+		Settings: map[string]interface{}{
+			`log`: map[string]interface{}{
+				`level`: `debug`,
+			},
+		},
+		Mode:  *mode,
+		Roots: []string{`/srv`},
+	})
+
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{
+		`verbose`:   true,
+		`q`:         false,
+		`workers`:   int64(8),
+		`ratio`:     float64(0.5),
+		`timeout`:   nil,
+		`tag`:       []interface{}{`a`, `b`},
+		`host`:      `localhost`,
+		`port`:      int64(8080),
+		`log.level`: `debug`,
+		`prod`:      true,
+		`roots`:     []interface{}{`/srv`},
+	}, flat)
+
+	_, err = Flatten(`serve`)
+	assert.Error(err)
+
+	// unsigned values that do not fit in an int64 do not wrap around
+	flat, err = Flatten(&struct {
+		Small uint64   `argonaut:"small"`
+		Large uint64   `argonaut:"large"`
+		Sizes []uint64 `argonaut:"size"`
+	}{
+		Small: 42,
+		Large: math.MaxUint64,
+		Sizes: []uint64{1, math.MaxInt64 + 1},
+	})
+
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{
+		`small`: int64(42),
+		`large`: uint64(math.MaxUint64),
+		`size`:  []interface{}{int64(1), uint64(math.MaxInt64 + 1)},
+	}, flat)
+}
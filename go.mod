@@ -1,8 +1,23 @@
 module github.com/ghetzel/argonaut
 
+go 1.18
+
 require (
 	github.com/fatih/structs v1.1.0
 	github.com/ghetzel/go-stockutil v1.5.53
 	github.com/stretchr/testify v1.2.2
 	golang.org/x/tools v0.0.0-20181101071927-45ff765b4815 // indirect
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/ghetzel/uuid v0.0.0-20171129191014-dec09d789f3d // indirect
+	github.com/hashicorp/errwrap v1.0.0 // indirect
+	github.com/hashicorp/go-multierror v1.0.0 // indirect
+	github.com/jbenet/go-base58 v0.0.0-20150317085156-6237cf65f3a6 // indirect
+	github.com/jdkato/prose v1.1.0 // indirect
+	github.com/juliangruber/go-intersect v1.0.0 // indirect
+	github.com/mitchellh/mapstructure v1.0.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/neurosnap/sentences.v1 v1.0.6 // indirect
+)
@@ -0,0 +1,70 @@
+package argonaut
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Returns a hex-encoded SHA-256 hash of the arguments generated for the given struct, suitable for
+// caching and deduplicating commands.  The hash does not depend on the order in which options
+// appear within the same (sub)command: the arguments generated by each flag (e.g.: "--size" and
+// its value) are sorted as a unit among the flags between the same two command names or
+// positional arguments, whose order is significant and which are kept in place.  Two structs that
+// generate the same logical command therefore produce the same hash, even if their fields are
+// declared in a different order, while moving a flag to another subcommand changes it.
+func Hash(v interface{}) (string, error) {
+	var segments []hashSegment
+	var flags [][]string
+
+	// each run of flags between two ordered arguments is sorted on its own
+	flush := func() {
+		if len(flags) > 0 {
+			sort.SliceStable(flags, func(i, j int) bool {
+				return strings.Join(flags[i], "\x00") < strings.Join(flags[j], "\x00")
+			})
+
+			segments = append(segments, hashSegment{
+				Groups: flags,
+			})
+
+			flags = nil
+		}
+	}
+
+	cfg := newConfig()
+	cfg.observe = func(tokens []string, isOrdered bool) {
+		if isOrdered {
+			flush()
+
+			segments = append(segments, hashSegment{
+				Ordered: true,
+				Groups:  [][]string{tokens},
+			})
+		} else {
+			flags = append(flags, tokens)
+		}
+	}
+
+	if _, _, err := generateCommand(cfg, v, true, false); err != nil {
+		return ``, err
+	}
+
+	flush()
+
+	if data, err := json.Marshal(segments); err == nil {
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:]), nil
+	} else {
+		return ``, err
+	}
+}
+
+// a run of arguments hashed by Hash: either a single group of ordered arguments (a command name or
+// positional values), or the sorted groups of the flags between them
+type hashSegment struct {
+	Ordered bool
+	Groups  [][]string
+}
@@ -0,0 +1,114 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHash(t *testing.T) {
+	assert := require.New(t)
+
+	type resizeA struct {
+		Command CommandName `argonaut:"convert"`
+		Width   int         `argonaut:"width,long"`
+		Height  int         `argonaut:"height,long"`
+		Strip   bool        `argonaut:"strip,long"`
+		Files   []string    `argonaut:",positional"`
+	}
+
+	type resizeB struct {
+		Command CommandName `argonaut:"convert"`
+		Strip   bool        `argonaut:"strip,long"`
+		Height  int         `argonaut:"height,long"`
+		Width   int         `argonaut:"width,long"`
+		Files   []string    `argonaut:",positional"`
+	}
+
+	type resizeC struct {
+		Command CommandName `argonaut:"convert"`
+		Strip   bool        `argonaut:"strip,long"`
+		Height  int         `argonaut:"height,long"`
+		Files   []string    `argonaut:",positional"`
+		Width   int         `argonaut:"width,long"`
+	}
+
+	a, err := Hash(&resizeA{Width: 640, Height: 480, Strip: true, Files: []string{`in.png`, `out.png`}})
+	assert.NoError(err)
+	assert.Len(a, 64)
+
+	// the order of flags does not matter
+	b, err := Hash(&resizeB{Width: 640, Height: 480, Strip: true, Files: []string{`in.png`, `out.png`}})
+	assert.NoError(err)
+	assert.Equal(a, b)
+
+	// but flags are not moved across positional arguments
+	bc, err := Hash(&resizeC{Width: 640, Height: 480, Strip: true, Files: []string{`in.png`, `out.png`}})
+	assert.NoError(err)
+	assert.NotEqual(a, bc)
+
+	// flag values stay attached to their flags
+	c, err := Hash(&resizeA{Width: 480, Height: 640, Strip: true, Files: []string{`in.png`, `out.png`}})
+	assert.NoError(err)
+	assert.NotEqual(a, c)
+
+	// the order of positional arguments does
+	d, err := Hash(&resizeA{Width: 640, Height: 480, Strip: true, Files: []string{`out.png`, `in.png`}})
+	assert.NoError(err)
+	assert.NotEqual(a, d)
+
+	// the hash is stable
+	e, err := Hash(&resizeA{Width: 640, Height: 480, Strip: true, Files: []string{`in.png`, `out.png`}})
+	assert.NoError(err)
+	assert.Equal(a, e)
+
+	// nested structs contribute their own flags
+	f, err := Hash(&FFMPEG{InputOptions: &InputOptions{URL: `a.avi`}})
+	assert.NoError(err)
+
+	g, err := Hash(&FFMPEG{InputOptions: &InputOptions{URL: `b.avi`}})
+	assert.NoError(err)
+	assert.NotEqual(f, g)
+
+	// flags are only reordered within the same subcommand
+	type commit struct {
+		Command CommandName `argonaut:"commit"`
+		Verbose bool        `argonaut:"v"`
+		Message string      `argonaut:"m"`
+	}
+
+	type git struct {
+		Command CommandName `argonaut:"git"`
+		Verbose bool        `argonaut:"v"`
+		Commit  *commit
+	}
+
+	assert.Equal([]string{`git`, `-v`, `commit`, `-m`, `x`}, MustParse(&git{Verbose: true, Commit: &commit{Message: `x`}}))
+	assert.Equal([]string{`git`, `commit`, `-v`, `-m`, `x`}, MustParse(&git{Commit: &commit{Verbose: true, Message: `x`}}))
+
+	h, err := Hash(&git{Verbose: true, Commit: &commit{Message: `x`}})
+	assert.NoError(err)
+
+	i, err := Hash(&git{Commit: &commit{Verbose: true, Message: `x`}})
+	assert.NoError(err)
+	assert.NotEqual(h, i)
+
+	type commitReordered struct {
+		Command CommandName `argonaut:"commit"`
+		Message string      `argonaut:"m"`
+		Verbose bool        `argonaut:"v"`
+	}
+
+	type gitReordered struct {
+		Command CommandName `argonaut:"git"`
+		Verbose bool        `argonaut:"v"`
+		Commit  *commitReordered
+	}
+
+	j, err := Hash(&gitReordered{Commit: &commitReordered{Verbose: true, Message: `x`}})
+	assert.NoError(err)
+	assert.Equal(i, j)
+
+	_, err = Hash(`nope`)
+	assert.Error(err)
+}
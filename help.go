@@ -0,0 +1,303 @@
+package argonaut
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// HelpConfig controls the layout of the option and argument lists generated by
+// GenerateHelpWithConfig.
+type HelpConfig struct {
+	// The width that option and argument names are padded to, so that their descriptions line up
+	// in a column.  Descriptions of names that are longer than this start on the following line.
+	// If zero, the width of the longest name is used.
+	NameWidth int
+
+	// The string written at the start of each option and argument (defaults to two spaces).
+	Indent string
+
+	// If greater than zero, descriptions are wrapped so that lines are at most this many
+	// characters wide (where possible), with continuation lines indented to the start of the
+	// description column.
+	LineWidth int
+}
+
+// the number of spaces between the name column and the description column of help entries
+const helpColumnGap = 2
+
+type helpEntry struct {
+	name        string
+	description string
+}
+
+// Generates usage documentation for the given struct, listing each of its options and positional
+// arguments along with their descriptions (from the "help" tag option), default values, allowed
+// values, and whether they are required or deprecated.  Descriptions are aligned in a column
+// following the longest option or argument name.
+func GenerateHelp(v interface{}) (string, error) {
+	return GenerateHelpWithConfig(v, HelpConfig{})
+}
+
+// Generates usage documentation as GenerateHelp does, with the layout of the option and argument
+// lists controlled by the given HelpConfig.
+func GenerateHelpWithConfig(v interface{}, config HelpConfig) (string, error) {
+	structT, err := structTypeOf(v)
+
+	if err != nil {
+		return ``, err
+	}
+
+	cfg := newConfig()
+	infos := make([]*FieldInfo, 0)
+
+	if err := collectFieldInfo(cfg, nil, structT, &infos); err != nil {
+		return ``, err
+	}
+
+	var options []*FieldInfo
+	var positionals []*FieldInfo
+	var out strings.Builder
+
+	for _, info := range infos {
+		if info.Positional {
+			positionals = append(positionals, info)
+		} else {
+			options = append(options, info)
+		}
+	}
+
+	out.WriteString(`Usage: ` + helpCommandName(cfg, v, structT))
+
+	if len(options) > 0 {
+		out.WriteString(` [OPTIONS]`)
+	}
+
+	for _, info := range positionals {
+		out.WriteString(` ` + helpPositionalName(cfg, info))
+	}
+
+	out.WriteString("\n")
+
+	optionEntries := make([]helpEntry, 0, len(options))
+	positionalEntries := make([]helpEntry, 0, len(positionals))
+
+	for _, info := range options {
+		names := make([]string, 0, 2)
+
+		if info.ShortName != `` {
+			names = append(names, info.ShortName)
+		}
+
+		if info.LongName != `` {
+			names = append(names, info.LongName)
+		}
+
+		if len(names) == 0 {
+			names = append(names, info.ResolvedFlagName)
+		}
+
+		name := strings.Join(names, `, `)
+
+		if placeholder := helpPlaceholder(info); placeholder != `` {
+			name += ` ` + placeholder
+		}
+
+		optionEntries = append(optionEntries, helpEntry{
+			name:        name,
+			description: helpDescription(info),
+		})
+	}
+
+	for _, info := range positionals {
+		positionalEntries = append(positionalEntries, helpEntry{
+			name:        helpPositionalName(cfg, info),
+			description: helpDescription(info),
+		})
+	}
+
+	if config.Indent == `` {
+		config.Indent = `  `
+	}
+
+	// both lists share the same description column
+	if config.NameWidth <= 0 {
+		for _, entries := range [][]helpEntry{optionEntries, positionalEntries} {
+			for _, entry := range entries {
+				if len(entry.name) > config.NameWidth {
+					config.NameWidth = len(entry.name)
+				}
+			}
+		}
+	}
+
+	if len(optionEntries) > 0 {
+		out.WriteString("\nOptions:\n")
+
+		for _, entry := range optionEntries {
+			writeHelpEntry(&out, entry, config)
+		}
+	}
+
+	if len(positionalEntries) > 0 {
+		out.WriteString("\nArguments:\n")
+
+		for _, entry := range positionalEntries {
+			writeHelpEntry(&out, entry, config)
+		}
+	}
+
+	return out.String(), nil
+}
+
+// Writes the output of GenerateHelp for the given struct to w.
+func PrintHelp(v interface{}, w io.Writer) error {
+	if help, err := GenerateHelp(v); err == nil {
+		_, err := io.WriteString(w, help)
+		return err
+	} else {
+		return err
+	}
+}
+
+// Writes the output of GenerateHelp for the given struct to standard error.
+func PrintHelpTo(v interface{}) error {
+	return PrintHelp(v, os.Stderr)
+}
+
+func writeHelpEntry(out *strings.Builder, entry helpEntry, config HelpConfig) {
+	out.WriteString(config.Indent + entry.name)
+
+	if entry.description == `` {
+		out.WriteString("\n")
+		return
+	}
+
+	column := len(config.Indent) + config.NameWidth + helpColumnGap
+	lines := wrapHelpText(entry.description, config.LineWidth-column)
+
+	if len(entry.name) > config.NameWidth {
+		out.WriteString("\n" + strings.Repeat(` `, column))
+	} else {
+		out.WriteString(strings.Repeat(` `, column-len(config.Indent)-len(entry.name)))
+	}
+
+	out.WriteString(strings.Join(lines, "\n"+strings.Repeat(` `, column)) + "\n")
+}
+
+// splits text into lines of at most width characters, breaking on spaces; words longer than width
+// are kept whole.  If width is zero or less, the text is returned as a single line.
+func wrapHelpText(text string, width int) []string {
+	if width <= 0 {
+		return []string{text}
+	}
+
+	lines := make([]string, 0)
+	line := ``
+
+	for _, word := range strings.Fields(text) {
+		if line == `` {
+			line = word
+		} else if len(line)+1+len(word) <= width {
+			line += ` ` + word
+		} else {
+			lines = append(lines, line)
+			line = word
+		}
+	}
+
+	return append(lines, line)
+}
+
+func helpDescription(info *FieldInfo) string {
+	parts := make([]string, 0)
+
+	if info.Deprecated != `` {
+		parts = append(parts, `[DEPRECATED: `+info.Deprecated+`]`)
+	}
+
+	if info.Help != `` {
+		parts = append(parts, info.Help)
+	}
+
+	details := make([]string, 0)
+
+	if info.Required {
+		details = append(details, `required`)
+	}
+
+	if info.Default != `` {
+		details = append(details, `default: `+info.Default)
+	}
+
+	if len(info.Choices) > 0 {
+		details = append(details, `choices: `+strings.Join(info.Choices, `, `))
+	}
+
+	if len(details) > 0 {
+		parts = append(parts, `(`+strings.Join(details, `; `)+`)`)
+	}
+
+	return strings.Join(parts, ` `)
+}
+
+// the placeholder describing the value an option accepts
+func helpPlaceholder(info *FieldInfo) string {
+	switch info.Type {
+	case reflect.Bool:
+		return ``
+	case reflect.Slice, reflect.Array:
+		return `value...`
+	case reflect.Struct, reflect.Interface:
+		return `value`
+	default:
+		return info.Type.String()
+	}
+}
+
+func helpPositionalName(cfg *Config, info *FieldInfo) string {
+	name := strings.ToUpper(cfg.fmtCommandWord(info.FieldName))
+
+	if info.Type == reflect.Slice || info.Type == reflect.Array {
+		name += `...`
+	}
+
+	if !info.Required {
+		name = `[` + name + `]`
+	}
+
+	return name
+}
+
+// determines the command name for usage purposes, as it would be determined by Parse
+func helpCommandName(cfg *Config, v interface{}, structT reflect.Type) string {
+	name := cfg.fmtCommandWord(structT.Name())
+	structV := reflect.Indirect(reflect.ValueOf(v))
+
+	walkFields(cfg, structT, nil, func(path []int, field reflect.StructField, tag *argonautTag) error {
+		if len(path) != 1 || derefType(field.Type) != commandNameType {
+			return nil
+		}
+
+		var value string
+
+		if structV.IsValid() {
+			if fieldV, ok := fieldValueByPath(structV, path); ok && reflect.Indirect(fieldV).IsValid() {
+				value = reflect.Indirect(fieldV).String()
+			}
+		}
+
+		if value != `` {
+			name = value
+		} else if tag.Label != `` {
+			name = tag.Label
+		} else {
+			name = cfg.primaryOption(tag, field.Name)
+		}
+
+		return nil
+	})
+
+	return name
+}
@@ -0,0 +1,107 @@
+package argonaut
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type helpEncoder struct {
+	Command CommandName `argonaut:"encoder"`
+	Preset  string      `argonaut:"preset|p,default=medium,choices=fast|medium|slow,help=Encoding speed"`
+	Threads int         `argonaut:"threads,long,required"`
+	Verbose bool        `argonaut:"v,help=Verbose output"`
+	Old     string      `argonaut:"old,deprecated=use --preset instead"`
+	Tags    []string    `argonaut:"tag"`
+	Input   string      `argonaut:",positional,required,help=Input file"`
+	Outputs []string    `argonaut:",positional"`
+}
+
+func TestGenerateHelp(t *testing.T) {
+	assert := require.New(t)
+
+	help, err := GenerateHelp(&helpEncoder{})
+	assert.NoError(err)
+	assert.Equal(`Usage: encoder [OPTIONS] INPUT [OUTPUTS...]
+
+Options:
+  -p, --preset string  Encoding speed (default: medium; choices: fast, medium, slow)
+  --threads int        (required)
+  -v                   Verbose output
+  -old string          [DEPRECATED: use --preset instead]
+  -tag value...
+
+Arguments:
+  INPUT                Input file (required)
+  [OUTPUTS...]
+`, help)
+
+	// the command name follows the same rules as Parse
+	help, err = GenerateHelp(helpEncoder{Command: `/usr/bin/encoder`})
+	assert.NoError(err)
+	assert.Contains(help, `Usage: /usr/bin/encoder [OPTIONS]`)
+
+	help, err = GenerateHelp((*helpEncoder)(nil))
+	assert.NoError(err)
+	assert.Contains(help, `Usage: encoder [OPTIONS]`)
+
+	_, err = GenerateHelp(`nope`)
+	assert.Error(err)
+}
+
+func TestGenerateHelpWithConfig(t *testing.T) {
+	assert := require.New(t)
+
+	help, err := GenerateHelpWithConfig(&helpEncoder{}, HelpConfig{
+		NameWidth: 10,
+		Indent:    ` `,
+		LineWidth: 40,
+	})
+
+	assert.NoError(err)
+	assert.Equal(`Usage: encoder [OPTIONS] INPUT [OUTPUTS...]
+
+Options:
+ -p, --preset string
+             Encoding speed (default:
+             medium; choices: fast,
+             medium, slow)
+ --threads int
+             (required)
+ -v          Verbose output
+ -old string
+             [DEPRECATED: use --preset
+             instead]
+ -tag value...
+
+Arguments:
+ INPUT       Input file (required)
+ [OUTPUTS...]
+`, help)
+
+	assert.Equal([]string{`a b`, `ccccc`, `d`}, wrapHelpText(`a b ccccc d`, 3))
+	assert.Equal([]string{`a b ccccc d`}, wrapHelpText(`a b ccccc d`, 0))
+}
+
+type failingWriter struct{}
+
+func (self failingWriter) Write(p []byte) (int, error) {
+	return 0, fmt.Errorf("write failed")
+}
+
+func TestPrintHelp(t *testing.T) {
+	assert := require.New(t)
+
+	var buf bytes.Buffer
+
+	assert.NoError(PrintHelp(&helpEncoder{}, &buf))
+
+	expected, err := GenerateHelp(&helpEncoder{})
+	assert.NoError(err)
+	assert.Equal(expected, buf.String())
+
+	assert.EqualError(PrintHelp(&helpEncoder{}, failingWriter{}), `write failed`)
+	assert.Error(PrintHelp(`nope`, &buf))
+}
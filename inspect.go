@@ -0,0 +1,95 @@
+package argonaut
+
+import (
+	"reflect"
+)
+
+// Describes a single field of an argonaut struct, as interpreted from its type and argonaut tag.
+type FieldInfo struct {
+	FieldName        string
+	ResolvedFlagName string
+	ShortName        string
+	LongName         string
+	Required         bool
+	Positional       bool
+	Type             reflect.Kind
+	Default          string
+	Choices          []string
+	Help             string
+	Deprecated       string
+}
+
+// Returns metadata describing the fields of the given struct in field declaration order, suitable
+// for generating documentation, shell completion, or validation rules.  As with FieldNames, nested
+// structs are included, while command names and fields that modify other arguments are not.
+// Positional fields are included, but have no flag names.  Returns nil if v is not a struct or its
+// tags cannot be parsed.
+func Inspect(v interface{}) []*FieldInfo {
+	if structT, err := structTypeOf(v); err == nil {
+		infos := make([]*FieldInfo, 0)
+
+		if err := collectFieldInfo(newConfig(), nil, structT, &infos); err == nil {
+			return infos
+		}
+	}
+
+	return nil
+}
+
+func collectFieldInfo(cfg *Config, enclosing []reflect.Type, structT reflect.Type, infos *[]*FieldInfo) error {
+	return walkFieldsWithin(cfg, enclosing, structT, nil, func(path []int, field reflect.StructField, tag *argonautTag) error {
+		fieldT := derefType(field.Type)
+
+		if elemT, ok := structSliceElem(fieldT); ok {
+			return collectFieldInfo(cfg, enclosingTypes(enclosing, structT, path), elemT, infos)
+		}
+
+		switch {
+		case fieldT == commandNameType, fieldT == optionSetType, fieldT == extraArgsType, isMapType(fieldT):
+			return nil
+		case tag.SuffixPrevious, tag.SkipName:
+			return nil
+		}
+
+		info := &FieldInfo{
+			FieldName:  field.Name,
+			Required:   tag.Required,
+			Positional: tag.Positional,
+			Type:       fieldT.Kind(),
+			Default:    tag.Default,
+			Choices:    tag.Choices,
+			Help:       tag.Help,
+			Deprecated: tag.Deprecated,
+		}
+
+		if !tag.Positional {
+			var prefix string
+			var names []string
+
+			if fieldT == argNameType {
+				prefix = tag.ArgNamePrefix()
+				names = []string{cfg.argNameLabel(tag, field.Name)}
+			} else {
+				prefix = tag.OptionPrefix()
+				names = []string{cfg.primaryOption(tag, field.Name)}
+				names = append(names, tag.Options...)
+				names = append(names, tag.Aliases...)
+			}
+
+			info.ResolvedFlagName = prefix + names[0]
+
+			for _, name := range names {
+				if len([]rune(name)) == 1 {
+					if info.ShortName == `` {
+						info.ShortName = `-` + name
+					}
+				} else if name != `` && info.LongName == `` {
+					info.LongName = prefix + name
+				}
+			}
+		}
+
+		*infos = append(*infos, info)
+		return nil
+	})
+}
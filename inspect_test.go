@@ -0,0 +1,61 @@
+package argonaut
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect(t *testing.T) {
+	assert := require.New(t)
+
+	type encoder struct {
+		Command CommandName `argonaut:"encoder"`
+		Preset  string      `argonaut:"preset|p,long,default=medium,choices=fast|medium|slow,help=Encoding speed"`
+		Threads int         `argonaut:"t,alias=threads,required"`
+		Verbose bool
+		Output  string `argonaut:",positional,help=Output file"`
+		Extra   map[string]interface{}
+	}
+
+	assert.Equal([]*FieldInfo{
+		{
+			FieldName:        `Preset`,
+			ResolvedFlagName: `--preset`,
+			ShortName:        `-p`,
+			LongName:         `--preset`,
+			Type:             reflect.String,
+			Default:          `medium`,
+			Choices:          []string{`fast`, `medium`, `slow`},
+			Help:             `Encoding speed`,
+		}, {
+			FieldName:        `Threads`,
+			ResolvedFlagName: `-t`,
+			ShortName:        `-t`,
+			LongName:         `-threads`,
+			Required:         true,
+			Type:             reflect.Int,
+		}, {
+			FieldName:        `Verbose`,
+			ResolvedFlagName: `--verbose`,
+			LongName:         `--verbose`,
+			Type:             reflect.Bool,
+		}, {
+			FieldName:  `Output`,
+			Positional: true,
+			Type:       reflect.String,
+			Help:       `Output file`,
+		},
+	}, Inspect(encoder{}))
+
+	infos := Inspect(&FFMPEG{})
+	assert.NotEmpty(infos)
+	assert.Equal(`ForceOverwrite`, infos[0].FieldName)
+	assert.Equal(`-y`, infos[0].ResolvedFlagName)
+
+	assert.Nil(Inspect(`nope`))
+	assert.Nil(Inspect(struct {
+		Bad string `argonaut:",min=abc"`
+	}{}))
+}
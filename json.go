@@ -0,0 +1,199 @@
+package argonaut
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// a JSON object whose members are written in the order they were added
+type jsonObject []jsonMember
+
+type jsonMember struct {
+	Key   string
+	Value interface{}
+}
+
+func (self jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString(`{`)
+
+	for i, member := range self {
+		if i > 0 {
+			buf.WriteString(`,`)
+		}
+
+		if key, err := json.Marshal(member.Key); err == nil {
+			buf.Write(key)
+			buf.WriteString(`:`)
+		} else {
+			return nil, err
+		}
+
+		if value, err := json.Marshal(member.Value); err == nil {
+			buf.Write(value)
+		} else {
+			return nil, err
+		}
+	}
+
+	buf.WriteString(`}`)
+
+	return buf.Bytes(), nil
+}
+
+// Serializes the field values of the given struct (not the command line it generates) as an
+// indented JSON object that can be read back with ParseJSON.  As with MarshalYAML, keys are the
+// primary option name of each field (in declaration order) and zero-valued fields are omitted.
+// Values keep their types: booleans and numbers are written as JSON booleans and numbers, slices as
+// arrays, and maps and slices of structs as nested objects.
+func MarshalPrettyJSON(v interface{}) ([]byte, error) {
+	vV := reflect.ValueOf(v)
+
+	for vV.Kind() == reflect.Ptr {
+		vV = vV.Elem()
+	}
+
+	if vV.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("struct needed, got %T", v)
+	}
+
+	if object, err := jsonStruct(newConfig(), vV); err == nil {
+		return json.MarshalIndent(object, ``, `  `)
+	} else {
+		return nil, err
+	}
+}
+
+func jsonStruct(cfg *Config, structV reflect.Value) (jsonObject, error) {
+	object := make(jsonObject, 0)
+
+	err := walkFields(cfg, structV.Type(), nil, func(path []int, field reflect.StructField, tag *argonautTag) error {
+		fieldT := derefType(field.Type)
+
+		if fieldT == commandNameType || fieldT == argNameType {
+			return nil
+		}
+
+		fieldV, ok := fieldValueByPath(structV, path)
+
+		if !ok || fieldV.IsZero() {
+			return nil
+		}
+
+		if value, err := jsonValue(cfg, fieldV); err == nil {
+			object = append(object, jsonMember{
+				Key:   cfg.primaryOption(tag, field.Name),
+				Value: value,
+			})
+		} else {
+			return fmt.Errorf("field %s: %v", field.Name, err)
+		}
+
+		return nil
+	})
+
+	return object, err
+}
+
+// converts a field value into a value that encoding/json will write with the appropriate JSON type
+func jsonValue(cfg *Config, value reflect.Value) (interface{}, error) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, nil
+		}
+
+		value = value.Elem()
+	}
+
+	if value.CanInterface() {
+		if t, ok := value.Interface().(time.Time); ok {
+			return t.Format(time.RFC3339Nano), nil
+		} else if fn, rvalue, ok := registeredSerializer(value.Interface()); ok {
+			return fn(rvalue)
+		} else if marshaler, ok := value.Interface().(encoding.TextMarshaler); ok {
+			if text, err := marshaler.MarshalText(); err == nil {
+				return string(text), nil
+			} else {
+				return nil, err
+			}
+		} else if om, ok := value.Interface().(OrderedMap); ok {
+			object := make(jsonObject, 0, len(om))
+
+			for _, kv := range om {
+				if item, err := jsonValue(cfg, reflect.ValueOf(&kv.Value).Elem()); err == nil {
+					object = append(object, jsonMember{
+						Key:   kv.Key,
+						Value: item,
+					})
+				} else {
+					return nil, err
+				}
+			}
+
+			return object, nil
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), nil
+
+	case reflect.Bool:
+		return value.Bool(), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int(), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return value.Uint(), nil
+
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), nil
+
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, value.Len())
+
+		for i := 0; i < value.Len(); i++ {
+			if item, err := jsonValue(cfg, value.Index(i)); err == nil {
+				items[i] = item
+			} else {
+				return nil, err
+			}
+		}
+
+		return items, nil
+
+	case reflect.Map:
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprintf("%v", keys[i].Interface()) < fmt.Sprintf("%v", keys[j].Interface())
+		})
+
+		object := make(jsonObject, 0, len(keys))
+
+		for _, k := range keys {
+			if item, err := jsonValue(cfg, value.MapIndex(k)); err == nil {
+				object = append(object, jsonMember{
+					Key:   fmt.Sprintf("%v", k.Interface()),
+					Value: item,
+				})
+			} else {
+				return nil, err
+			}
+		}
+
+		return object, nil
+
+	case reflect.Struct:
+		return jsonStruct(cfg, value)
+
+	default:
+		return nil, fmt.Errorf("cannot represent %v as JSON", value.Type())
+	}
+}
@@ -0,0 +1,84 @@
+package argonaut
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalPrettyJSON(t *testing.T) {
+	assert := require.New(t)
+
+	type stream struct {
+		Index int    `argonaut:"index"`
+		Codec string `argonaut:"codec"`
+	}
+
+	type encoder struct {
+		Command CommandName       `argonaut:"encoder"`
+		Preset  string            `argonaut:"preset"`
+		Threads int               `argonaut:"threads|t"`
+		Quality float64           `argonaut:"q"`
+		Verbose bool              `argonaut:"verbose"`
+		Tags    []string          `argonaut:"tag"`
+		Labels  map[string]string `argonaut:"label"`
+		Output  string            `argonaut:",positional"`
+		Streams []stream          `argonaut:"stream"`
+	}
+
+	input := &encoder{
+		Preset:  `fast`,
+		Threads: 4,
+		Quality: 1.5,
+		Verbose: true,
+		Tags:    []string{`a`, `true`},
+		Labels: map[string]string{
+			`b`: `2`,
+			`a`: `one`,
+		},
+		Output: `out.mp4`,
+		Streams: []stream{
+			{Index: 0, Codec: `libx264`},
+			{Index: 1},
+		},
+	}
+
+	data, err := MarshalPrettyJSON(input)
+	assert.NoError(err)
+	assert.Equal(`{
+  "preset": "fast",
+  "threads": 4,
+  "q": 1.5,
+  "verbose": true,
+  "tag": [
+    "a",
+    "true"
+  ],
+  "label": {
+    "a": "one",
+    "b": "2"
+  },
+  "output": "out.mp4",
+  "stream": [
+    {
+      "codec": "libx264"
+    },
+    {
+      "index": 1
+    }
+  ]
+}`, string(data))
+
+	var output encoder
+
+	assert.NoError(ParseFromReader(bytes.NewReader(data), `json`, &output))
+	assert.Equal(input, &output)
+
+	data, err = MarshalPrettyJSON(&encoder{})
+	assert.NoError(err)
+	assert.Equal(`{}`, string(data))
+
+	_, err = MarshalPrettyJSON(`nope`)
+	assert.Error(err)
+}
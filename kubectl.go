@@ -0,0 +1,54 @@
+package argonaut
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Returns the arguments for running the given command inside of a Kubernetes pod using
+// "kubectl exec" (e.g.: "kubectl exec mypod -c mycontainer -- ls -l").  The command is generated
+// using Parse.  If container is empty, the "-c" option is omitted and kubectl will use the pod's
+// default container.
+func MarshalKubectl(pod string, container string, cmd interface{}) ([]string, error) {
+	if pod == `` {
+		return nil, fmt.Errorf("A pod name is required")
+	}
+
+	if cmdargs, err := Parse(cmd); err == nil {
+		args := []string{`kubectl`, `exec`, pod}
+
+		if container != `` {
+			args = append(args, `-c`, container)
+		}
+
+		args = append(args, `--`)
+		args = append(args, cmdargs...)
+
+		return args, nil
+	} else {
+		return nil, err
+	}
+}
+
+// Populates the struct pointed to by v from a set of Kubernetes annotations (e.g.: those of the
+// pod a tool is running in).  Only annotations whose keys start with the given prefix and a slash
+// are used (e.g.: "example.com/port" for the prefix "example.com"); the remainder of each key is
+// matched against the option names, aliases, and field names of v's fields, as with ParseJSON.  If
+// prefix is empty, only annotations without a prefix are used.  Values are converted to the type
+// of the field they are assigned to.
+func ParseKubernetesArgs(prefix string, annotations map[string]string, v interface{}) error {
+	data := make(map[string]interface{})
+	prefix = strings.TrimSuffix(prefix, `/`)
+
+	for key, value := range annotations {
+		if prefix == `` {
+			if !strings.Contains(key, `/`) {
+				data[key] = value
+			}
+		} else if strings.HasPrefix(key, prefix+`/`) {
+			data[strings.TrimPrefix(key, prefix+`/`)] = value
+		}
+	}
+
+	return populate(newConfig(), v, data)
+}
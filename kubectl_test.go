@@ -0,0 +1,78 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalKubectl(t *testing.T) {
+	assert := require.New(t)
+
+	type ls struct {
+		Command CommandName `argonaut:"ls"`
+		Long    bool        `argonaut:"l"`
+		Paths   []string    `argonaut:",positional"`
+	}
+
+	input := &ls{
+		Long:  true,
+		Paths: []string{`/var/log`},
+	}
+
+	args, err := MarshalKubectl(`web-0`, `nginx`, input)
+	assert.NoError(err)
+	assert.Equal([]string{`kubectl`, `exec`, `web-0`, `-c`, `nginx`, `--`, `ls`, `-l`, `/var/log`}, args)
+
+	args, err = MarshalKubectl(`web-0`, ``, input)
+	assert.NoError(err)
+	assert.Equal([]string{`kubectl`, `exec`, `web-0`, `--`, `ls`, `-l`, `/var/log`}, args)
+
+	_, err = MarshalKubectl(``, `nginx`, input)
+	assert.Error(err)
+
+	_, err = MarshalKubectl(`web-0`, `nginx`, `ls`)
+	assert.Error(err)
+}
+
+func TestParseKubernetesArgs(t *testing.T) {
+	assert := require.New(t)
+
+	type server struct {
+		Command CommandName `argonaut:"serve"`
+		Port    int         `argonaut:"port,long"`
+		Debug   bool        `argonaut:"debug,long"`
+		Name    string      `argonaut:"name|n,long"`
+		Tags    []string    `argonaut:"tag,long"`
+	}
+
+	annotations := map[string]string{
+		`example.com/port`:            `8080`,
+		`example.com/debug`:           `true`,
+		`example.com/Name`:            `web`,
+		`example.com/tag`:             `blue`,
+		`other.example.com/port`:      `9090`,
+		`kubernetes.io/config.source`: `api`,
+		`port`:                        `7070`,
+	}
+
+	var out server
+
+	assert.NoError(ParseKubernetesArgs(`example.com`, annotations, &out))
+	assert.Equal(server{
+		Port:  8080,
+		Debug: true,
+		Name:  `web`,
+		Tags:  []string{`blue`},
+	}, out)
+
+	out = server{}
+	assert.NoError(ParseKubernetesArgs(``, annotations, &out))
+	assert.Equal(server{Port: 7070}, out)
+
+	assert.Error(ParseKubernetesArgs(`example.com`, map[string]string{
+		`example.com/port`: `eighty`,
+	}, &server{}))
+
+	assert.Error(ParseKubernetesArgs(`example.com`, annotations, server{}))
+}
@@ -0,0 +1,98 @@
+package argonaut
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Layers the non-zero fields of overrides on top of a copy of defaults and returns the result, which
+// is a pointer if defaults is a pointer and a struct value otherwise.  This is useful when the values
+// from a configuration file should be overridden by those given explicitly (e.g.: on the command
+// line).  Nested structs are merged field by field rather than replaced.  Neither argument is
+// modified, and both must be of the same struct type.
+func MergeDefaults(defaults interface{}, overrides interface{}) (interface{}, error) {
+	defaultsV := reflect.ValueOf(defaults)
+	overridesV := reflect.ValueOf(overrides)
+
+	for _, vV := range []*reflect.Value{&defaultsV, &overridesV} {
+		for vV.Kind() == reflect.Ptr {
+			if vV.IsNil() {
+				return nil, fmt.Errorf("Cannot merge a nil value")
+			}
+
+			*vV = vV.Elem()
+		}
+
+		if vV.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("struct needed, got %v", vV.Kind())
+		}
+	}
+
+	if defaultsV.Type() != overridesV.Type() {
+		return nil, fmt.Errorf("Cannot merge %v into %v", overridesV.Type(), defaultsV.Type())
+	}
+
+	merged := reflect.New(defaultsV.Type())
+	merged.Elem().Set(defaultsV)
+	cloneNestedStructs(merged.Elem())
+
+	overlayNonZero(merged.Elem(), overridesV)
+
+	if reflect.TypeOf(defaults).Kind() == reflect.Ptr {
+		return merged.Interface(), nil
+	}
+
+	return merged.Elem().Interface(), nil
+}
+
+// Copies the non-zero fields of src into the struct pointed to by dst, leaving the other fields of
+// dst as they are.  This is useful for applying a "patch" (a struct with only the changed fields
+// set) onto a fully-populated base struct.  As with MergeDefaults, nested structs are copied field
+// by field rather than replaced, only exported fields are copied, and both arguments must be of the
+// same struct type.
+func CopyNonZero(src interface{}, dst interface{}) error {
+	srcV := reflect.ValueOf(src)
+	dstV := reflect.ValueOf(dst)
+
+	if dstV.Kind() != reflect.Ptr || dstV.IsNil() || dstV.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("pointer to struct needed, got %T", dst)
+	}
+
+	for srcV.Kind() == reflect.Ptr {
+		if srcV.IsNil() {
+			return fmt.Errorf("Cannot copy from a nil value")
+		}
+
+		srcV = srcV.Elem()
+	}
+
+	if srcV.Type() != dstV.Elem().Type() {
+		return fmt.Errorf("Cannot copy %v into %v", srcV.Type(), dstV.Elem().Type())
+	}
+
+	overlayNonZero(dstV.Elem(), srcV)
+	return nil
+}
+
+// replaces every pointer to a nested struct in structV with a pointer to a copy, so that merging
+// into structV cannot modify the structs it shares with the value it was copied from
+func cloneNestedStructs(structV reflect.Value) {
+	for i := 0; i < structV.NumField(); i++ {
+		if structV.Type().Field(i).PkgPath != `` {
+			continue
+		}
+
+		fieldV := structV.Field(i)
+
+		switch {
+		case fieldV.Kind() == reflect.Struct && !isLeafType(fieldV.Type()):
+			cloneNestedStructs(fieldV)
+
+		case fieldV.Kind() == reflect.Ptr && !fieldV.IsNil() && fieldV.Elem().Kind() == reflect.Struct && !isLeafType(fieldV.Elem().Type()):
+			clone := reflect.New(fieldV.Elem().Type())
+			clone.Elem().Set(fieldV.Elem())
+			cloneNestedStructs(clone.Elem())
+			fieldV.Set(clone)
+		}
+	}
+}
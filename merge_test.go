@@ -0,0 +1,102 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeDefaults(t *testing.T) {
+	assert := require.New(t)
+
+	defaults := &ls{
+		All:       true,
+		BlockSize: 1024,
+		Paths:     []string{`/home`},
+	}
+
+	merged, err := MergeDefaults(defaults, ls{
+		LongFormat: true,
+		BlockSize:  512,
+	})
+
+	assert.NoError(err)
+	assert.Equal(&ls{
+		All:        true,
+		LongFormat: true,
+		BlockSize:  512,
+		Paths:      []string{`/home`},
+	}, merged)
+
+	// the defaults are not modified
+	assert.Equal(1024, defaults.BlockSize)
+	assert.False(defaults.LongFormat)
+
+	// non-pointer defaults yield a non-pointer result
+	merged, err = MergeDefaults(ls{All: true}, &ls{Paths: []string{`/tmp`}})
+	assert.NoError(err)
+	assert.Equal(ls{All: true, Paths: []string{`/tmp`}}, merged)
+
+	// nested structs are merged field by field
+	type network struct {
+		Host string `argonaut:"host,long"`
+		Port int    `argonaut:"port,long"`
+	}
+
+	type server struct {
+		Command CommandName `argonaut:"serve"`
+		Network *network
+	}
+
+	base := &server{
+		Network: &network{Host: `localhost`, Port: 8080},
+	}
+
+	merged, err = MergeDefaults(base, &server{
+		Network: &network{Port: 9090},
+	})
+
+	assert.NoError(err)
+	assert.Equal(&network{Host: `localhost`, Port: 9090}, merged.(*server).Network)
+	assert.Equal(&network{Host: `localhost`, Port: 8080}, base.Network)
+
+	_, err = MergeDefaults(&ls{}, &server{})
+	assert.Error(err)
+
+	_, err = MergeDefaults((*ls)(nil), &ls{})
+	assert.Error(err)
+
+	_, err = MergeDefaults(`ls`, `ls`)
+	assert.Error(err)
+}
+
+func TestCopyNonZero(t *testing.T) {
+	assert := require.New(t)
+
+	base := &ls{
+		All:       true,
+		BlockSize: 1024,
+		Paths:     []string{`/home`},
+	}
+
+	patch := ls{
+		LongFormat: true,
+		Paths:      []string{`/tmp`},
+	}
+
+	assert.NoError(CopyNonZero(patch, base))
+	assert.Equal(&ls{
+		All:        true,
+		LongFormat: true,
+		BlockSize:  1024,
+		Paths:      []string{`/tmp`},
+	}, base)
+
+	// zero-valued fields in the patch never clear the base
+	assert.NoError(CopyNonZero(&ls{}, base))
+	assert.Equal(1024, base.BlockSize)
+
+	assert.Error(CopyNonZero(patch, *base))
+	assert.Error(CopyNonZero((*ls)(nil), base))
+	assert.Error(CopyNonZero(&echo{}, base))
+}
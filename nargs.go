@@ -0,0 +1,86 @@
+package argonaut
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// the number of values a flag accepts, as specified by the "nargs" tag option; a Max of -1 means
+// there is no upper limit
+type nargsSpec struct {
+	Min int
+	Max int
+}
+
+// parses the value of the "nargs" tag option: an exact count ("N"), an inclusive range ("N:M"),
+// zero or one ("?"), zero or more ("*"), or one or more ("+")
+func parseNArgs(spec string) (*nargsSpec, error) {
+	switch spec {
+	case `?`:
+		return &nargsSpec{Min: 0, Max: 1}, nil
+	case `*`:
+		return &nargsSpec{Min: 0, Max: -1}, nil
+	case `+`:
+		return &nargsSpec{Min: 1, Max: -1}, nil
+	}
+
+	if bounds := strings.SplitN(spec, `:`, 2); len(bounds) == 2 {
+		min, err := strconv.Atoi(bounds[0])
+
+		if err != nil || min < 0 {
+			return nil, fmt.Errorf("invalid nargs range %q", spec)
+		}
+
+		max, err := strconv.Atoi(bounds[1])
+
+		if err != nil || max < min {
+			return nil, fmt.Errorf("invalid nargs range %q", spec)
+		}
+
+		return &nargsSpec{Min: min, Max: max}, nil
+	} else if n, err := strconv.Atoi(spec); err == nil && n >= 0 {
+		return &nargsSpec{Min: n, Max: n}, nil
+	}
+
+	return nil, fmt.Errorf("invalid nargs value %q", spec)
+}
+
+func (self *nargsSpec) String() string {
+	switch {
+	case self.Min == self.Max:
+		return strconv.Itoa(self.Min)
+	case self.Max < 0:
+		return fmt.Sprintf("at least %d", self.Min)
+	default:
+		return fmt.Sprintf("%d to %d", self.Min, self.Max)
+	}
+}
+
+// returns an error if n values is not an acceptable number of values
+func (self *nargsSpec) Check(n int) error {
+	if n < self.Min || (self.Max >= 0 && n > self.Max) {
+		return fmt.Errorf("expected %v values, got %d", self, n)
+	}
+
+	return nil
+}
+
+// whether more values can be accepted after n have been consumed
+func (self *nargsSpec) More(n int) bool {
+	return self.Max < 0 || n < self.Max
+}
+
+// whether the given argument can be consumed as a value for a flag with nargs set: flags (other
+// than negative numbers and the "-" placeholder) and the "--" terminator are not values
+func isNArgValue(token string) bool {
+	if token == `--` {
+		return false
+	} else if token == `-` || !strings.HasPrefix(token, `-`) {
+		return true
+	} else if _, err := strconv.ParseFloat(token, 64); err == nil {
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,138 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type plotter struct {
+	Command CommandName `argonaut:"plot"`
+	Coords  []float64   `argonaut:"coords,long,nargs=2"`
+	Range   []int       `argonaut:"range,long,nargs=1:2"`
+	Labels  []string    `argonaut:"labels,long,nargs=+"`
+	Style   string      `argonaut:"style,long,nargs=?"`
+	Extra   []string    `argonaut:"extra,long,nargs=*"`
+	Files   []string    `argonaut:",positional"`
+}
+
+func TestNArgsMarshal(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal([]string{
+		`plot`,
+		`--coords`, `1.5`, `-2`,
+		`--range`, `1`, `10`,
+		`--labels`, `a`, `b`, `c`,
+		`--style`, `dots`,
+		`data.csv`,
+	}, MustParse(&plotter{
+		Coords: []float64{1.5, -2},
+		Range:  []int{1, 10},
+		Labels: []string{`a`, `b`, `c`},
+		Style:  `dots`,
+		Files:  []string{`data.csv`},
+	}))
+
+	// empty fields are omitted entirely
+	assert.Equal([]string{`plot`, `data.csv`}, MustParse(&plotter{
+		Files: []string{`data.csv`},
+	}))
+
+	_, err := Parse(&plotter{
+		Coords: []float64{1, 2, 3},
+	})
+
+	assert.EqualError(err, `field Coords: expected 2 values, got 3`)
+
+	_, err = Parse(&plotter{
+		Range: []int{1, 2, 3},
+	})
+
+	assert.EqualError(err, `field Range: expected 1 to 2 values, got 3`)
+}
+
+func TestNArgsUnmarshal(t *testing.T) {
+	assert := require.New(t)
+
+	output := &plotter{}
+
+	assert.NoError(Unmarshal([]string{
+		`plot`,
+		`--coords`, `1.5`, `-2`,
+		`--range`, `5`,
+		`--labels`, `a`, `b`, `c`,
+		`--style`,
+		`--extra`,
+		`--`, `data.csv`,
+	}, output))
+
+	assert.Equal(&plotter{
+		Coords: []float64{1.5, -2},
+		Range:  []int{5},
+		Labels: []string{`a`, `b`, `c`},
+		Files:  []string{`data.csv`},
+	}, output)
+
+	// values stop being consumed once the maximum is reached
+	output = &plotter{}
+	assert.NoError(Unmarshal([]string{`plot`, `--coords`, `1`, `2`, `data.csv`, `--style=x`}, output))
+	assert.Equal(&plotter{
+		Coords: []float64{1, 2},
+		Style:  `x`,
+		Files:  []string{`data.csv`},
+	}, output)
+
+	// round trip
+	input := &plotter{
+		Coords: []float64{3, 4},
+		Range:  []int{1, 10},
+		Labels: []string{`x`},
+		Extra:  []string{`-`, `y`},
+	}
+
+	output = &plotter{}
+	assert.NoError(Unmarshal(MustParse(input), output))
+	assert.Equal(input, output)
+
+	// as with argparse, flags without an upper limit consume any positional arguments that follow
+	output = &plotter{}
+	assert.NoError(Unmarshal([]string{`plot`, `--extra`, `y`, `a.csv`}, output))
+	assert.Equal(&plotter{
+		Extra: []string{`y`, `a.csv`},
+	}, output)
+
+	err := Unmarshal([]string{`plot`, `--coords`, `1`, `--labels`, `a`}, &plotter{})
+	assert.Error(err)
+	assert.Equal(`argument 1 ("--coords"), field Coords: expected 2 values, got 1`, err.Error())
+
+	assert.Error(Unmarshal([]string{`plot`, `--labels`}, &plotter{}))
+}
+
+func TestParseNArgs(t *testing.T) {
+	assert := require.New(t)
+
+	for spec, expected := range map[string]nargsSpec{
+		`3`:   {3, 3},
+		`0`:   {0, 0},
+		`1:4`: {1, 4},
+		`?`:   {0, 1},
+		`*`:   {0, -1},
+		`+`:   {1, -1},
+	} {
+		actual, err := parseNArgs(spec)
+		assert.NoError(err, spec)
+		assert.Equal(expected, *actual, spec)
+	}
+
+	for _, spec := range []string{``, `-1`, `x`, `4:1`, `1:`, `:2`} {
+		_, err := parseNArgs(spec)
+		assert.Error(err, spec)
+	}
+
+	_, err := Parse(&struct {
+		Bad []string `argonaut:"bad,nargs=lots"`
+	}{})
+
+	assert.EqualError(err, `argonaut tag option "nargs": invalid nargs value "lots"`)
+}
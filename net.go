@@ -0,0 +1,73 @@
+package argonaut
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+)
+
+// functions used by Unmarshal to convert strings into values of built-in types
+var builtinParsers = map[reflect.Type]func(string) (interface{}, error){
+	reflect.TypeOf(net.IP{}): func(in string) (interface{}, error) {
+		if ip := net.ParseIP(in); ip != nil {
+			return ip, nil
+		} else {
+			return nil, fmt.Errorf("invalid IP address %q", in)
+		}
+	},
+	reflect.TypeOf(net.IPNet{}): func(in string) (interface{}, error) {
+		if _, ipnet, err := net.ParseCIDR(in); err == nil {
+			return *ipnet, nil
+		} else {
+			return nil, err
+		}
+	},
+	reflect.TypeOf(net.TCPAddr{}): func(in string) (interface{}, error) {
+		if addr, err := net.ResolveTCPAddr(`tcp`, in); err == nil {
+			return *addr, nil
+		} else {
+			return nil, err
+		}
+	},
+	reflect.TypeOf(net.UDPAddr{}): func(in string) (interface{}, error) {
+		if addr, err := net.ResolveUDPAddr(`udp`, in); err == nil {
+			return *addr, nil
+		} else {
+			return nil, err
+		}
+	},
+}
+
+func init() {
+	RegisterType(reflect.TypeOf(net.IP{}), func(v interface{}) (string, error) {
+		if ip := v.(net.IP); len(ip) > 0 {
+			return ip.String(), nil
+		}
+
+		return ``, nil
+	})
+
+	RegisterType(reflect.TypeOf(net.IPNet{}), func(v interface{}) (string, error) {
+		if ipnet := v.(net.IPNet); len(ipnet.IP) > 0 {
+			return ipnet.String(), nil
+		}
+
+		return ``, nil
+	})
+
+	RegisterType(reflect.TypeOf(net.TCPAddr{}), func(v interface{}) (string, error) {
+		if addr := v.(net.TCPAddr); len(addr.IP) > 0 || addr.Port > 0 {
+			return addr.String(), nil
+		}
+
+		return ``, nil
+	})
+
+	RegisterType(reflect.TypeOf(net.UDPAddr{}), func(v interface{}) (string, error) {
+		if addr := v.(net.UDPAddr); len(addr.IP) > 0 || addr.Port > 0 {
+			return addr.String(), nil
+		}
+
+		return ``, nil
+	})
+}
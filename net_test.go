@@ -0,0 +1,62 @@
+package argonaut
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type netcat struct {
+	Command CommandName  `argonaut:"nc"`
+	Source  net.IP       `argonaut:"s"`
+	Allow   *net.IPNet   `argonaut:"allow,long"`
+	Proxy   *net.TCPAddr `argonaut:"x"`
+	Peers   []net.IP     `argonaut:"peer,long"`
+	Target  net.UDPAddr  `argonaut:",positional"`
+}
+
+func TestNetTypes(t *testing.T) {
+	assert := require.New(t)
+
+	_, allow, _ := net.ParseCIDR(`10.0.0.0/8`)
+
+	input := &netcat{
+		Source: net.ParseIP(`192.168.0.1`).To16(),
+		Allow:  allow,
+		Proxy: &net.TCPAddr{
+			IP:   net.ParseIP(`::1`),
+			Port: 3128,
+		},
+		Peers: []net.IP{
+			net.ParseIP(`2001:0db8:0000:0000:0000:0000:0000:0001`),
+		},
+		Target: net.UDPAddr{
+			IP:   net.IPv4(127, 0, 0, 1),
+			Port: 53,
+		},
+	}
+
+	args, err := Parse(input)
+	assert.NoError(err)
+	assert.Equal([]string{
+		`nc`,
+		`-s`, `192.168.0.1`,
+		`--allow`, `10.0.0.0/8`,
+		`-x`, `[::1]:3128`,
+		`--peer`, `2001:db8::1`,
+		`127.0.0.1:53`,
+	}, args)
+
+	output := &netcat{}
+	assert.NoError(Unmarshal(args, output))
+	assert.True(output.Source.Equal(input.Source))
+	assert.Equal(`10.0.0.0/8`, output.Allow.String())
+	assert.Equal(`[::1]:3128`, output.Proxy.String())
+	assert.Len(output.Peers, 1)
+	assert.Equal(`2001:db8::1`, output.Peers[0].String())
+	assert.Equal(`127.0.0.1:53`, output.Target.String())
+
+	// zero values are omitted
+	assert.Equal([]string{`nc`}, MustParse(&netcat{}))
+}
@@ -0,0 +1,123 @@
+package argonaut
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Applies the transformations declared in the argonaut tags of the struct pointed to by v to the
+// values of its fields, in place: leading and trailing whitespace is removed from the values of
+// fields with the "trim" option, the values of fields with the "lowercase" option are converted to
+// lowercase, and the values of fields with the "clamp" option are brought within the range given
+// by their "min" and "max" options.  Slices are transformed element by element.  Normalizing a
+// value more than once has the same effect as normalizing it once.
+func Normalize(v interface{}) error {
+	vV := reflect.ValueOf(v)
+
+	if vV.Kind() != reflect.Ptr || vV.IsNil() || vV.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("pointer to struct needed, got %T", v)
+	}
+
+	return walkFields(newConfig(), vV.Elem().Type(), nil, func(path []int, field reflect.StructField, tag *argonautTag) error {
+		if !tag.Trim && !tag.Lowercase && !tag.Clamp {
+			return nil
+		}
+
+		if fieldV, ok := fieldValueByPath(vV.Elem(), path); ok {
+			normalizeValue(fieldV, tag)
+		}
+
+		return nil
+	})
+}
+
+func normalizeValue(value reflect.Value, tag *argonautTag) {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			normalizeValue(value.Elem(), tag)
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			normalizeValue(value.Index(i), tag)
+		}
+
+	case reflect.String:
+		str := value.String()
+
+		if tag.Trim {
+			str = strings.TrimSpace(str)
+		}
+
+		if tag.Lowercase {
+			str = strings.ToLower(str)
+		}
+
+		value.SetString(str)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if tag.Clamp {
+			if tag.Min != nil && float64(value.Int()) < *tag.Min {
+				value.SetInt(int64(math.Ceil(*tag.Min)))
+			} else if tag.Max != nil && float64(value.Int()) > *tag.Max {
+				value.SetInt(int64(math.Floor(*tag.Max)))
+			}
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if tag.Clamp {
+			if tag.Min != nil && float64(value.Uint()) < *tag.Min {
+				value.SetUint(uint64(math.Ceil(*tag.Min)))
+			} else if tag.Max != nil && float64(value.Uint()) > *tag.Max {
+				value.SetUint(uint64(math.Max(0, math.Floor(*tag.Max))))
+			}
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if tag.Clamp {
+			if tag.Min != nil && value.Float() < *tag.Min {
+				value.SetFloat(*tag.Min)
+			} else if tag.Max != nil && value.Float() > *tag.Max {
+				value.SetFloat(*tag.Max)
+			}
+		}
+	}
+}
+
+// returns the given element of a numeric slice, brought within the range of the tag if it has the
+// "clamp" option, or an error if it is outside of that range
+func rangeCheckElement(value interface{}, tag *argonautTag) (interface{}, error) {
+	rV := reflect.Indirect(reflect.ValueOf(value))
+
+	if !rV.IsValid() {
+		return value, nil
+	}
+
+	elem := reflect.New(rV.Type()).Elem()
+	elem.Set(rV)
+
+	if tag.Clamp {
+		normalizeValue(elem, tag)
+		return elem.Interface(), nil
+	}
+
+	var n float64
+
+	switch elem.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(elem.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(elem.Uint())
+	default:
+		n = elem.Float()
+	}
+
+	if (tag.Min != nil && n < *tag.Min) || (tag.Max != nil && n > *tag.Max) {
+		return nil, fmt.Errorf("%v is outside of the allowed range %s", value, describeRange(tag))
+	}
+
+	return value, nil
+}
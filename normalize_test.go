@@ -0,0 +1,72 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	assert := require.New(t)
+
+	type encode struct {
+		Command CommandName `argonaut:"encode"`
+		Preset  string      `argonaut:"preset,long,trim,lowercase,choices=fast|slow"`
+		Title   *string     `argonaut:"title,long,trim"`
+		Tags    []string    `argonaut:"tag,long,lowercase"`
+		Quality int         `argonaut:"q,min=0,max=51,clamp"`
+		Threads uint        `argonaut:"threads,long,min=1,clamp"`
+		Scale   float64     `argonaut:"scale,long,min=0.5,max=2,clamp"`
+		Crf     int         `argonaut:"crf,long,min=0,max=51"`
+		Name    string      `argonaut:"name,long"`
+	}
+
+	title := "  My Video\t"
+	input := &encode{
+		Preset:  ` Fast `,
+		Title:   &title,
+		Tags:    []string{`HDR`, `Web`},
+		Quality: 99,
+		Scale:   0.1,
+		Crf:     99,
+		Name:    ` Kept `,
+	}
+
+	assert.NoError(Normalize(input))
+
+	expected := &encode{
+		Preset:  `fast`,
+		Tags:    []string{`hdr`, `web`},
+		Quality: 51,
+		Threads: 1,
+		Scale:   0.5,
+		Crf:     99,
+		Name:    ` Kept `,
+	}
+
+	assert.Equal(`My Video`, *input.Title)
+	input.Title = nil
+	assert.Equal(expected, input)
+
+	// normalizing is idempotent
+	assert.NoError(Normalize(input))
+	assert.Equal(expected, input)
+
+	assert.Error(Normalize(encode{}))
+
+	for _, invalid := range []interface{}{
+		&struct {
+			Count int `argonaut:"count,trim"`
+		}{},
+		&struct {
+			Count int `argonaut:"count,clamp"`
+		}{},
+		&struct {
+			Enabled bool `argonaut:"enabled,lowercase"`
+		}{},
+	} {
+		result := Validate(invalid)
+		assert.False(result.OK())
+		assert.Equal(DiagnosticTypeMismatch, result.Errors[0].Code)
+	}
+}
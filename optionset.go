@@ -0,0 +1,87 @@
+package argonaut
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ghetzel/go-stockutil/typeutil"
+)
+
+var optionSetType = reflect.TypeOf(OptionSet{})
+
+// An OptionSet represents a group of mutually exclusive alternatives, only one of which may be
+// given at a time (e.g.: either "--format=<spec>" or "--profile=<name>").  Each alternative is
+// named with the option it emits.  When marshaled, only the alternative with a non-zero value is
+// emitted, using the prefix and joiner of the OptionSet field's tag; boolean alternatives are
+// emitted as bare flags, and struct alternatives emit their own group of arguments.  Marshaling
+// fails if more than one alternative has a non-zero value.
+type OptionSet struct {
+	Alternatives []KeyValue
+}
+
+// Creates a new OptionSet with the given alternatives, none of which are active.
+func NewOptionSet(names ...string) *OptionSet {
+	set := &OptionSet{
+		Alternatives: make([]KeyValue, len(names)),
+	}
+
+	for i, name := range names {
+		set.Alternatives[i].Key = name
+	}
+
+	return set
+}
+
+// Activates the named alternative with the given value, clearing the values of all others.
+func (self *OptionSet) Set(name string, value interface{}) error {
+	found := false
+
+	for i, alt := range self.Alternatives {
+		if alt.Key == name {
+			self.Alternatives[i].Value = value
+			found = true
+		} else {
+			self.Alternatives[i].Value = nil
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("OptionSet has no alternative named %q", name)
+	}
+
+	return nil
+}
+
+// Returns the active alternative (the only one with a non-zero value), whether one was active, and
+// an error if more than one alternative is active.
+func (self *OptionSet) Active() (KeyValue, bool, error) {
+	var active *KeyValue
+
+	for i, alt := range self.Alternatives {
+		if typeutil.IsZero(alt.Value) {
+			continue
+		} else if active != nil {
+			return KeyValue{}, false, fmt.Errorf("only one of the alternatives in an OptionSet may be given, got %q and %q", active.Key, alt.Key)
+		}
+
+		active = &self.Alternatives[i]
+	}
+
+	if active != nil {
+		return *active, true, nil
+	}
+
+	return KeyValue{}, false, nil
+}
+
+// returns the OptionSet the given value is or points to (if any)
+func asOptionSet(value interface{}) (*OptionSet, bool) {
+	switch set := value.(type) {
+	case OptionSet:
+		return &set, true
+	case *OptionSet:
+		return set, true
+	}
+
+	return nil, false
+}
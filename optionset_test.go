@@ -0,0 +1,70 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionSet(t *testing.T) {
+	assert := require.New(t)
+
+	type profile struct {
+		Name  string `argonaut:"profile,long"`
+		Level int    `argonaut:"level,long"`
+	}
+
+	type encoder struct {
+		Command CommandName `argonaut:"encoder"`
+		Mode    *OptionSet  `argonaut:",long,joiner=[=]"`
+		Output  string      `argonaut:",positional"`
+	}
+
+	mode := NewOptionSet(`format`, `preset`, `lossless`, `profile`)
+
+	assert.Equal([]string{`encoder`, `out.mkv`}, MustParse(&encoder{
+		Mode:   mode,
+		Output: `out.mkv`,
+	}))
+
+	assert.NoError(mode.Set(`format`, `yuv420p`))
+	assert.Equal([]string{`encoder`, `--format=yuv420p`, `out.mkv`}, MustParse(&encoder{
+		Mode:   mode,
+		Output: `out.mkv`,
+	}))
+
+	assert.NoError(mode.Set(`lossless`, true))
+	assert.Equal([]string{`encoder`, `--lossless`, `out.mkv`}, MustParse(&encoder{
+		Mode:   mode,
+		Output: `out.mkv`,
+	}))
+
+	assert.NoError(mode.Set(`profile`, profile{Name: `high`, Level: 4}))
+	assert.Equal([]string{`encoder`, `--profile`, `high`, `--level`, `4`, `out.mkv`}, MustParse(&encoder{
+		Mode:   mode,
+		Output: `out.mkv`,
+	}))
+
+	assert.Error(mode.Set(`nope`, 1))
+
+	// a nil OptionSet emits nothing
+	assert.Equal([]string{`encoder`, `out.mkv`}, MustParse(&encoder{
+		Output: `out.mkv`,
+	}))
+
+	// setting more than one alternative directly is an error
+	_, err := Parse(&encoder{
+		Mode: &OptionSet{
+			Alternatives: []KeyValue{
+				{Key: `format`, Value: `yuv420p`},
+				{Key: `preset`, Value: `fast`},
+			},
+		},
+	})
+
+	assert.EqualError(err, `field Mode: only one of the alternatives in an OptionSet may be given, got "format" and "preset"`)
+
+	names, err := FieldNames(&encoder{})
+	assert.NoError(err)
+	assert.Empty(names)
+}
@@ -0,0 +1,110 @@
+package argonaut
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/ghetzel/go-stockutil/maputil"
+)
+
+var orderedMapType = reflect.TypeOf(OrderedMap{})
+
+// A single key-value pair in an OrderedMap.
+type KeyValue struct {
+	Key   string
+	Value interface{}
+}
+
+// An OrderedMap can be used in place of a map field to control the order in which its key-value
+// pairs are emitted.  Pairs are emitted in the order they are declared, and are otherwise treated
+// exactly like the entries of a map.
+type OrderedMap []KeyValue
+
+// Returns the value of the first pair with the given key.
+func (self OrderedMap) Get(key string) (interface{}, bool) {
+	for _, kv := range self {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+// whether values of the given type are exploded into key-value arguments
+func isMapType(t reflect.Type) bool {
+	return t == orderedMapType || t.Kind() == reflect.Map
+}
+
+// calls fn for every leaf value in the given map, OrderedMap, or slice (descending into nested
+// values), along with the key path that leads to it.  Map keys are visited in sorted order, and
+// OrderedMap keys in declaration order.
+func walkMapArguments(value interface{}, path []string, fn func(key []string, value interface{}) error) error {
+	if value == nil {
+		return nil
+	}
+
+	if om, ok := value.(OrderedMap); ok {
+		for _, kv := range om {
+			if err := walkMapArguments(kv.Value, append(append([]string{}, path...), kv.Key), fn); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	valueV := reflect.ValueOf(value)
+
+	if valueV.Kind() == reflect.Ptr {
+		if valueV.IsNil() {
+			return nil
+		}
+
+		return walkMapArguments(valueV.Elem().Interface(), path, fn)
+	}
+
+	switch valueV.Kind() {
+	case reflect.Map:
+		keys := make([]string, 0, valueV.Len())
+		values := make(map[string]interface{}, valueV.Len())
+
+		for _, key := range valueV.MapKeys() {
+			k := fmt.Sprintf("%v", key.Interface())
+			keys = append(keys, k)
+			values[k] = valueV.MapIndex(key).Interface()
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if err := walkMapArguments(values[key], append(append([]string{}, path...), key), fn); err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < valueV.Len(); i++ {
+			if err := walkMapArguments(valueV.Index(i).Interface(), append(append([]string{}, path...), fmt.Sprintf("%d", i)), fn); err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	case reflect.Struct:
+		return maputil.Walk(value, func(v interface{}, key []string, isLeaf bool) error {
+			if isLeaf {
+				return fn(append(append([]string{}, path...), key...), v)
+			}
+
+			return nil
+		})
+
+	default:
+		return fn(path, value)
+	}
+}
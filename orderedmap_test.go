@@ -0,0 +1,114 @@
+package argonaut
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedMap(t *testing.T) {
+	assert := require.New(t)
+
+	type encoder struct {
+		Command CommandName `argonaut:"x264"`
+		Options OrderedMap  `argonaut:",long,joiner=[=],keyjoiner=[-]"`
+		Input   string      `argonaut:",positional"`
+	}
+
+	params := OrderedMap{
+		{Key: `preset`, Value: `veryfast`},
+		{Key: `tune`, Value: `film`},
+		{Key: `crf`, Value: 18},
+		{Key: `nal`, Value: OrderedMap{
+			{Key: `hrd`, Value: `vbr`},
+		}},
+		{Key: `skipped`, Value: nil},
+		{Key: `aq`, Value: map[string]interface{}{
+			`strength`: 1.5,
+			`mode`:     2,
+		}},
+	}
+
+	assert.Equal([]string{
+		`x264`,
+		`--preset=veryfast`,
+		`--tune=film`,
+		`--crf=18`,
+		`--nal-hrd=vbr`,
+		`--aq-mode=2`,
+		`--aq-strength=1.5`,
+		`in.y4m`,
+	}, MustParse(&encoder{
+		Options: params,
+		Input:   `in.y4m`,
+	}))
+
+	value, ok := params.Get(`tune`)
+	assert.True(ok)
+	assert.Equal(`film`, value)
+
+	_, ok = params.Get(`nope`)
+	assert.False(ok)
+
+	names, err := FieldNames(&encoder{})
+	assert.NoError(err)
+	assert.Empty(names)
+}
+
+func TestMapsSorted(t *testing.T) {
+	assert := require.New(t)
+
+	type encoder struct {
+		Command CommandName            `argonaut:"encoder"`
+		Params  map[string]interface{} `argonaut:",positional,short"`
+	}
+
+	for i := 0; i < 16; i++ {
+		assert.Equal([]string{`encoder`, `-a`, `1`, `-b`, `2`, `-c`, `3`, `-d`, `4`}, MustParse(&encoder{
+			Params: map[string]interface{}{
+				`d`: 4,
+				`c`: 3,
+				`b`: 2,
+				`a`: 1,
+			},
+		}))
+	}
+}
+
+func TestMapValueTypes(t *testing.T) {
+	assert := require.New(t)
+
+	type encoder struct {
+		Command CommandName            `argonaut:"x264"`
+		Options map[string]interface{} `argonaut:",long,joiner=[=]"`
+	}
+
+	var missing *string
+
+	assert.Equal([]string{
+		`x264`, `--crf=18.5`, `--fast-decode`, `--keyint=250`, `--large=100000000000000000000`, `--preset=veryfast`, `--timeout=1m30s`,
+	}, MustParse(&encoder{
+		Options: map[string]interface{}{
+			`preset`:      `veryfast`,
+			`keyint`:      250,
+			`crf`:         18.5,
+			`large`:       1e20,
+			`fast-decode`: true,
+			`no-cabac`:    false,
+			`missing`:     missing,
+			`nothing`:     nil,
+			`timeout`:     90 * time.Second,
+		},
+	}))
+
+	// boolean flags are emitted on their own when the joiner is the separator
+	type encoderSpaced struct {
+		Command CommandName     `argonaut:"x264"`
+		Options map[string]bool `argonaut:",long"`
+	}
+
+	assert.Equal([]string{`x264`, `--a`}, MustParse(&encoderSpaced{
+		Options: map[string]bool{`a`: true, `b`: false},
+	}))
+}
@@ -0,0 +1,66 @@
+package argonaut
+
+import (
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ghetzel/go-stockutil/stringutil"
+	"github.com/ghetzel/go-stockutil/typeutil"
+)
+
+// the fill characters used by the "pad_left" and "pad_right" options when "pad_char" is not given
+const (
+	defaultPadLeftChar  = '0'
+	defaultPadRightChar = ' '
+)
+
+// pads the string form of a scalar (string or numeric) value to the widths given by the
+// "pad_left" and "pad_right" options of the tag.  Values that are already at least as wide are
+// left as they are, and zero values that would be omitted are not padded.
+func padValue(value interface{}, tag *argonautTag) interface{} {
+	if tag.PadLeft <= 0 && tag.PadRight <= 0 {
+		return value
+	}
+
+	resolved := typeutil.ResolveValue(value)
+
+	if resolved == nil {
+		return value
+	}
+
+	switch resolved.(type) {
+	case CommandName, ArgName:
+		return value
+	}
+
+	switch kind := reflect.TypeOf(resolved).Kind(); {
+	case kind == reflect.String, isNumericKind(kind):
+		if typeutil.IsZero(resolved) && tag.OmitZero() {
+			return value
+		}
+
+		str := stringutil.MustString(resolved)
+
+		if n := tag.PadLeft - utf8.RuneCountInString(str); n > 0 {
+			str = strings.Repeat(string(tag.padChar(defaultPadLeftChar)), n) + str
+		}
+
+		if n := tag.PadRight - utf8.RuneCountInString(str); n > 0 {
+			str += strings.Repeat(string(tag.padChar(defaultPadRightChar)), n)
+		}
+
+		return str
+	}
+
+	return value
+}
+
+// the fill character given by the "pad_char" option, or the given default
+func (self *argonautTag) padChar(fallback rune) rune {
+	if self.PadChar != 0 {
+		return self.PadChar
+	}
+
+	return fallback
+}
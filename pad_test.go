@@ -0,0 +1,46 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPad(t *testing.T) {
+	assert := require.New(t)
+
+	type render struct {
+		Command CommandName `argonaut:"render"`
+		Start   int         `argonaut:"start,long,pad_left=6"`
+		Frames  []int       `argonaut:"frame,long,pad_left=4"`
+		Label   string      `argonaut:"label,long,pad_right=8,pad_char=."`
+		Scale   float64     `argonaut:"scale,long,pad_left=5,pad_char= "`
+		Skip    int         `argonaut:"skip,long,pad_left=3"`
+		Name    string      `argonaut:",positional,pad_left=3,pad_right=5,pad_char=_"`
+	}
+
+	assert.Equal([]string{
+		`render`, `--start`, `000001`, `--frame`, `0012`, `--frame`, `12345`, `--label`, `intro...`, `--scale`, `  1.5`, `__x__`,
+	}, MustParse(&render{
+		Start:  1,
+		Frames: []int{12, 12345},
+		Label:  `intro`,
+		Scale:  1.5,
+		Name:   `x`,
+	}))
+
+	defaults := newConfig().defaultTag()
+
+	_, err := parseTag(`start,pad_left=-1`, &defaults)
+	assert.Error(err)
+
+	_, err = parseTag(`start,pad_char=ab`, &defaults)
+	assert.Error(err)
+
+	result := Validate(&struct {
+		Fast bool   `argonaut:"fast,pad_left=2"`
+		Name string `argonaut:"name,pad_char=0"`
+	}{})
+
+	assert.Len(result.Errors, 2)
+}
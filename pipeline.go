@@ -0,0 +1,213 @@
+package argonaut
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+)
+
+// A Pipeline is a sequence of commands whose standard output is connected to the standard input of
+// the command that follows it, as with a shell pipe ("a | b | c").
+type Pipeline struct {
+	cmds    []*exec.Cmd
+	outputs []*bytes.Buffer
+	stderrs []*bytes.Buffer
+	errs    []error
+	started bool
+}
+
+// Creates a Pipeline from the given values, each of which is converted into a command using
+// Command.  A Redirect is only honored for the standard input of the first command and the
+// standard output of the last one; redirecting any stream that is connected to a neighboring
+// command is an error.
+func Pipe(cmds ...interface{}) (*Pipeline, error) {
+	if len(cmds) == 0 {
+		return nil, fmt.Errorf("Cannot create a pipeline without any commands")
+	}
+
+	cfg := newConfig()
+	pipeline := &Pipeline{
+		cmds: make([]*exec.Cmd, len(cmds)),
+	}
+
+	for i, v := range cmds {
+		if cmd, err := buildCommand(nil, cfg, v); err == nil {
+			pipeline.cmds[i] = cmd
+		} else {
+			return nil, fmt.Errorf("command %d: %v", i, err)
+		}
+	}
+
+	if err := pipeline.checkRedirects(); err != nil {
+		return nil, err
+	}
+
+	return pipeline, nil
+}
+
+// returns an error if any stream that the pipeline connects to a neighboring command has already
+// been redirected elsewhere
+func (self *Pipeline) checkRedirects() error {
+	for i, cmd := range self.cmds {
+		if i > 0 && cmd.Stdin != nil {
+			return fmt.Errorf("command %d: cannot redirect standard input of a command that reads from the pipeline", i)
+		}
+
+		if i < len(self.cmds)-1 && cmd.Stdout != nil {
+			return fmt.Errorf("command %d: cannot redirect standard output of a command that writes to the pipeline", i)
+		}
+	}
+
+	return nil
+}
+
+// Returns the commands that make up the pipeline.
+func (self *Pipeline) Commands() []*exec.Cmd {
+	return self.cmds
+}
+
+// Returns everything the i-th command wrote to standard output after the pipeline has run.  For
+// all but the last command, this is the data that was passed to the next command.
+func (self *Pipeline) Output(i int) []byte {
+	if i >= 0 && i < len(self.outputs) {
+		return self.outputs[i].Bytes()
+	}
+
+	return nil
+}
+
+// Returns everything the i-th command wrote to standard error after the pipeline has run.  This is
+// only captured for commands whose Stderr was not already set.
+func (self *Pipeline) Stderr(i int) []byte {
+	if i >= 0 && i < len(self.stderrs) {
+		return self.stderrs[i].Bytes()
+	}
+
+	return nil
+}
+
+// Returns the error (if any) returned by the i-th command after the pipeline has run.
+func (self *Pipeline) Err(i int) error {
+	if i >= 0 && i < len(self.errs) {
+		return self.errs[i]
+	}
+
+	return nil
+}
+
+// Runs the pipeline and waits for all commands to exit, returning the standard output of the
+// last command.
+func (self *Pipeline) Run() ([]byte, error) {
+	return self.RunContext(context.Background())
+}
+
+// Runs the pipeline and waits for all commands to exit, returning the standard output of the
+// last command (which is empty if it was redirected).  If the context is done before the pipeline completes, all commands that are still
+// running are killed.  As with the "pipefail" shell option, the returned error is the first error
+// encountered by any command, in pipeline order.
+func (self *Pipeline) RunContext(ctx context.Context) ([]byte, error) {
+	if self.started {
+		return nil, fmt.Errorf("Pipeline has already been run")
+	}
+
+	if err := self.checkRedirects(); err != nil {
+		return nil, err
+	}
+
+	self.started = true
+
+	n := len(self.cmds)
+	readers := make([]*io.PipeReader, n)
+	writers := make([]*io.PipeWriter, n)
+
+	self.outputs = make([]*bytes.Buffer, n)
+	self.stderrs = make([]*bytes.Buffer, n)
+	self.errs = make([]error, n)
+
+	for i, cmd := range self.cmds {
+		self.outputs[i] = new(bytes.Buffer)
+		self.stderrs[i] = new(bytes.Buffer)
+
+		if cmd.Stderr == nil {
+			cmd.Stderr = self.stderrs[i]
+		}
+
+		if i < n-1 {
+			readers[i], writers[i] = io.Pipe()
+			cmd.Stdout = io.MultiWriter(writers[i], self.outputs[i])
+			self.cmds[i+1].Stdin = readers[i]
+		} else if cmd.Stdout == nil {
+			cmd.Stdout = self.outputs[i]
+		}
+	}
+
+	// closes the pipes attached to the i-th command so its neighbors see EOF (or a closed pipe)
+	release := func(i int) {
+		if i < n-1 {
+			writers[i].Close()
+		}
+
+		if i > 0 {
+			readers[i-1].Close()
+		}
+	}
+
+	for i, cmd := range self.cmds {
+		if err := cmd.Start(); err != nil {
+			for j := 0; j < i; j++ {
+				self.cmds[j].Process.Kill()
+				release(j)
+				self.cmds[j].Wait()
+			}
+
+			for _, cmd := range self.cmds {
+				ReleaseCommand(cmd)
+			}
+
+			return nil, fmt.Errorf("command %d: %v", i, err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+
+	for i, cmd := range self.cmds {
+		wg.Add(1)
+
+		go func(i int, cmd *exec.Cmd) {
+			defer wg.Done()
+
+			self.errs[i] = cmd.Wait()
+			release(i)
+			ReleaseCommand(cmd)
+		}(i, cmd)
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			for _, cmd := range self.cmds {
+				cmd.Process.Kill()
+			}
+		case <-done:
+		}
+	}()
+
+	wg.Wait()
+	close(done)
+
+	if err := ctx.Err(); err != nil {
+		return self.outputs[n-1].Bytes(), err
+	}
+
+	for i, err := range self.errs {
+		if err != nil {
+			return self.outputs[n-1].Bytes(), fmt.Errorf("command %d: %v", i, err)
+		}
+	}
+
+	return self.outputs[n-1].Bytes(), nil
+}
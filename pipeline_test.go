@@ -0,0 +1,166 @@
+package argonaut
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipe(t *testing.T) {
+	assert := require.New(t)
+
+	type echo struct {
+		Command CommandName `argonaut:"echo"`
+		Words   []string    `argonaut:",positional"`
+	}
+
+	type translate struct {
+		Command CommandName `argonaut:"tr"`
+		From    string      `argonaut:",positional"`
+		To      string      `argonaut:",positional"`
+	}
+
+	pipeline, err := Pipe(&echo{
+		Words: []string{`hello`, `world`},
+	}, &translate{
+		From: `a-z`,
+		To:   `A-Z`,
+	}, []string{`tr`, ` `, `_`})
+
+	assert.NoError(err)
+	assert.Len(pipeline.Commands(), 3)
+
+	out, err := pipeline.Run()
+	assert.NoError(err)
+	assert.Equal("HELLO_WORLD\n", string(out))
+
+	// intermediate outputs are retained
+	assert.Equal("hello world\n", string(pipeline.Output(0)))
+	assert.Equal("HELLO WORLD\n", string(pipeline.Output(1)))
+	assert.Equal("HELLO_WORLD\n", string(pipeline.Output(2)))
+	assert.Nil(pipeline.Output(3))
+
+	// pipelines can only be run once
+	_, err = pipeline.Run()
+	assert.Error(err)
+}
+
+func TestPipeErrors(t *testing.T) {
+	assert := require.New(t)
+
+	_, err := Pipe()
+	assert.Error(err)
+
+	_, err = Pipe(`echo`, ``)
+	assert.Error(err)
+
+	// a failing command fails the pipeline
+	pipeline, err := Pipe([]string{`echo`, `hello`}, []string{`cat`, `/nonexistent/file`})
+	assert.NoError(err)
+
+	_, err = pipeline.Run()
+	assert.Error(err)
+	assert.Nil(pipeline.Err(0))
+	assert.Error(pipeline.Err(1))
+	assert.NotEmpty(pipeline.Stderr(1))
+
+	// a downstream command exiting early does not hang the pipeline
+	pipeline, err = Pipe(`yes`, []string{`head`, `-n`, `2`})
+	assert.NoError(err)
+
+	out, _ := pipeline.Run()
+	assert.Equal("y\ny\n", string(out))
+}
+
+func TestPipeRunContext(t *testing.T) {
+	assert := require.New(t)
+
+	pipeline, err := Pipe([]string{`sleep`, `10`}, `cat`)
+	assert.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	_, err = pipeline.RunContext(ctx)
+
+	assert.Equal(context.DeadlineExceeded, err)
+	assert.True(time.Since(started) < 5*time.Second)
+}
+
+func TestPipeRedirect(t *testing.T) {
+	assert := require.New(t)
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, `input.txt`)
+	output := filepath.Join(dir, `output.txt`)
+
+	assert.NoError(os.WriteFile(input, []byte("hello world\n"), 0644))
+
+	type cat struct {
+		Command CommandName `argonaut:"cat"`
+		Streams Redirect
+	}
+
+	type translate struct {
+		Command CommandName `argonaut:"tr"`
+		Streams Redirect
+		From    string `argonaut:",positional"`
+		To      string `argonaut:",positional"`
+	}
+
+	// the first command's input and the last command's output are honored
+	pipeline, err := Pipe(&cat{
+		Streams: Redirect{
+			Stdin: input,
+		},
+	}, &translate{
+		Streams: Redirect{
+			Stdout: output,
+		},
+		From: `a-z`,
+		To:   `A-Z`,
+	})
+
+	assert.NoError(err)
+
+	out, err := pipeline.Run()
+	assert.NoError(err)
+	assert.Empty(out)
+	assert.Equal("hello world\n", string(pipeline.Output(0)))
+
+	data, err := os.ReadFile(output)
+	assert.NoError(err)
+	assert.Equal("HELLO WORLD\n", string(data))
+
+	// redirecting a stream that is connected to another command is an error
+	_, err = Pipe(&cat{
+		Streams: Redirect{
+			Stdin:  input,
+			Stdout: output,
+		},
+	}, `cat`)
+
+	assert.EqualError(err, `command 0: cannot redirect standard output of a command that writes to the pipeline`)
+
+	_, err = Pipe(`echo`, &cat{
+		Streams: Redirect{
+			Stdin: input,
+		},
+	})
+
+	assert.EqualError(err, `command 1: cannot redirect standard input of a command that reads from the pipeline`)
+
+	// ...including when the command is changed after the pipeline is created
+	pipeline, err = Pipe(`echo`, `cat`)
+	assert.NoError(err)
+
+	pipeline.Commands()[1].Stdin = os.Stdin
+
+	_, err = pipeline.Run()
+	assert.EqualError(err, `command 1: cannot redirect standard input of a command that reads from the pipeline`)
+}
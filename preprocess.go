@@ -0,0 +1,69 @@
+package argonaut
+
+import (
+	"reflect"
+
+	"github.com/ghetzel/go-stockutil/stringutil"
+	"github.com/ghetzel/go-stockutil/typeutil"
+)
+
+// A function that transforms the string form of a field's value before it is added to a command.
+type PreprocessFunc func(field string, value string) string
+
+// Generates the arguments for the given struct as Parse does, but first passes the name of each
+// field and the string form of each of its (non-zero) values through fn, using the returned
+// string in place of the value.  This can be used to lowercase values, normalize paths, redact
+// secrets, and so on.  If fn returns an empty string for a field that is not required, that value
+// is omitted.  Only scalar values (strings, numbers, and booleans, including the elements of
+// slices, times, and the output of registered types) are preprocessed; command names are not.
+func Preprocess(v interface{}, fn PreprocessFunc) ([]string, error) {
+	cfg := newConfig()
+	cfg.preprocess = fn
+
+	if command, _, err := generateCommand(cfg, v, true, false); err == nil {
+		return command, nil
+	} else {
+		return nil, err
+	}
+}
+
+// applies the configured PreprocessFunc (if any) to a scalar value.  Returns the new value, and
+// false if the value should be omitted.
+func (self *Config) preprocessValue(fieldName string, value interface{}, tag *argonautTag) (interface{}, bool) {
+	if self.preprocess == nil || value == nil {
+		return value, true
+	}
+
+	resolved := typeutil.ResolveValue(value)
+
+	if resolved == nil {
+		return value, true
+	}
+
+	switch resolved.(type) {
+	case CommandName, ArgName:
+		return value, true
+	}
+
+	switch kind := reflect.TypeOf(resolved).Kind(); kind {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if typeutil.IsZero(resolved) && tag.OmitZero() {
+			return value, true
+		}
+
+		str := self.preprocess(fieldName, stringutil.MustString(resolved))
+
+		if str == `` && !tag.Required {
+			return nil, false
+		} else if kind == reflect.Bool {
+			return typeutil.V(str).Bool(), true
+		} else {
+			return str, true
+		}
+	}
+
+	return value, true
+}
@@ -0,0 +1,76 @@
+package argonaut
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreprocess(t *testing.T) {
+	assert := require.New(t)
+
+	type login struct {
+		Command  CommandName `argonaut:"login"`
+		Username string      `argonaut:"user,long"`
+		Password string      `argonaut:"password,long"`
+		Token    string      `argonaut:"token,long,required"`
+		Retries  int         `argonaut:"retries,long"`
+		Insecure bool        `argonaut:"k"`
+		Debug    bool        `argonaut:"debug,long"`
+		Config   string      `argonaut:"config,long"`
+		Hosts    []string    `argonaut:",positional"`
+	}
+
+	input := &login{
+		Username: `ADMIN`,
+		Password: `hunter2`,
+		Token:    `abc`,
+		Retries:  3,
+		Insecure: true,
+		Config:   `/etc/../etc/login.conf`,
+		Hosts:    []string{`One.example.com`, `skip.example.com`},
+	}
+
+	var seen []string
+
+	args, err := Preprocess(input, func(field string, value string) string {
+		seen = append(seen, field)
+
+		switch field {
+		case `Password`:
+			return `********`
+		case `Token`:
+			return ``
+		case `Insecure`:
+			return `false`
+		case `Config`:
+			return filepath.Clean(value)
+		case `Hosts`:
+			if strings.HasPrefix(value, `skip`) {
+				return ``
+			}
+		}
+
+		return strings.ToLower(value)
+	})
+
+	assert.NoError(err)
+	assert.Equal([]string{
+		`login`,
+		`--user`, `admin`,
+		`--password`, `********`,
+		`--token`, ``,
+		`--retries`, `3`,
+		`--config`, `/etc/login.conf`,
+		`one.example.com`,
+	}, args)
+
+	// zero values that would be omitted anyway are not preprocessed
+	assert.Equal([]string{`Username`, `Password`, `Token`, `Retries`, `Insecure`, `Config`, `Hosts`, `Hosts`}, seen)
+
+	// the struct itself is left as-is
+	assert.Equal(`hunter2`, input.Password)
+	assert.Contains(MustParse(input), `hunter2`)
+}
@@ -0,0 +1,66 @@
+package argonaut
+
+import (
+	"reflect"
+	"sync"
+)
+
+// A function that converts a value of a registered type into the string that will be used when
+// it appears in a command line.
+type SerializerFunc func(interface{}) (string, error)
+
+var typeRegistry = make(map[reflect.Type]SerializerFunc)
+var typeRegistryLock sync.RWMutex
+
+// Registers a function that will be used to serialize any field value of the given type. Registered
+// types are checked before any other type handling, which makes this useful for types like net.IP,
+// url.URL, or time.Time that you don't control the implementation of.
+func RegisterType(t reflect.Type, fn func(interface{}) (string, error)) {
+	typeRegistryLock.Lock()
+	defer typeRegistryLock.Unlock()
+
+	typeRegistry[t] = SerializerFunc(fn)
+}
+
+// Removes any serializer previously registered for the given type.
+func UnregisterType(t reflect.Type) {
+	typeRegistryLock.Lock()
+	defer typeRegistryLock.Unlock()
+
+	delete(typeRegistry, t)
+}
+
+// returns whether a serializer has been registered for the given type
+func isRegisteredType(t reflect.Type) bool {
+	typeRegistryLock.RLock()
+	defer typeRegistryLock.RUnlock()
+
+	_, ok := typeRegistry[t]
+	return ok
+}
+
+// retrieve the serializer for the given value's type (or the type it points to), if any
+func registeredSerializer(value interface{}) (SerializerFunc, interface{}, bool) {
+	if value == nil {
+		return nil, nil, false
+	}
+
+	typeRegistryLock.RLock()
+	defer typeRegistryLock.RUnlock()
+
+	if len(typeRegistry) == 0 {
+		return nil, nil, false
+	}
+
+	if fn, ok := typeRegistry[reflect.TypeOf(value)]; ok {
+		return fn, value, true
+	}
+
+	if vV := reflect.ValueOf(value); vV.Kind() == reflect.Ptr && !vV.IsNil() {
+		if fn, ok := typeRegistry[vV.Elem().Type()]; ok {
+			return fn, vV.Elem().Interface(), true
+		}
+	}
+
+	return nil, nil, false
+}
@@ -0,0 +1,73 @@
+package argonaut
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testPoint struct {
+	X int
+	Y int
+}
+
+func TestRegisterType(t *testing.T) {
+	assert := require.New(t)
+
+	type plot struct {
+		Command CommandName `argonaut:"plot"`
+		Origin  testPoint   `argonaut:"origin,long,joiner=[=]"`
+		Points  []testPoint `argonaut:",positional"`
+	}
+
+	RegisterType(reflect.TypeOf(testPoint{}), func(v interface{}) (string, error) {
+		if point, ok := v.(testPoint); ok {
+			return fmt.Sprintf("%d,%d", point.X, point.Y), nil
+		} else {
+			return ``, fmt.Errorf("expected testPoint, got %T", v)
+		}
+	})
+
+	defer UnregisterType(reflect.TypeOf(testPoint{}))
+
+	output, err := Marshal(&plot{
+		Origin: testPoint{1, 2},
+		Points: []testPoint{{3, 4}, {5, 6}},
+	})
+
+	assert.NoError(err)
+	assert.Equal(`plot --origin=1,2 3,4 5,6`, string(output))
+}
+
+func TestRegisterTypePrecedence(t *testing.T) {
+	assert := require.New(t)
+
+	type render struct {
+		Command CommandName     `argonaut:"render"`
+		Filters testFilterGraph `argonaut:"vf,long,joiner=[=]"`
+		Labels  OrderedMap      `argonaut:"label,long"`
+	}
+
+	RegisterType(reflect.TypeOf(testFilterGraph{}), func(v interface{}) (string, error) {
+		return strings.Join(v.(testFilterGraph), `,`), nil
+	})
+
+	defer UnregisterType(reflect.TypeOf(testFilterGraph{}))
+
+	RegisterType(reflect.TypeOf(OrderedMap{}), func(v interface{}) (string, error) {
+		return fmt.Sprintf("%d-labels", len(v.(OrderedMap))), nil
+	})
+
+	defer UnregisterType(reflect.TypeOf(OrderedMap{}))
+
+	output, err := Marshal(&render{
+		Filters: testFilterGraph{`scale=640:-1`, `fps=30`},
+		Labels:  OrderedMap{{`a`, `1`}, {`b`, `2`}},
+	})
+
+	assert.NoError(err)
+	assert.Equal(`render --vf=scale=640:-1,fps=30 --label 2-labels`, string(output))
+}
@@ -0,0 +1,168 @@
+package argonaut
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// The outcome of a single execution of a command.
+type RunResult struct {
+	Stdout     []byte
+	Stderr     []byte
+	Err        error
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// Generates the command described by v (see Command) and runs it, waiting for it to complete.
+func Run(v interface{}, opts ...Option) error {
+	if cmd, err := Command(v, opts...); err == nil {
+		defer ReleaseCommand(cmd)
+		return cmd.Run()
+	} else {
+		return err
+	}
+}
+
+// Describes how ExecWithRetry re-runs a failed command.  At most MaxAttempts runs are made (at
+// least one is always made).  The first retry waits for Delay, and each subsequent retry waits
+// Backoff times longer than the one before it (a Backoff less than 1 keeps the delay constant).
+// RetryOn decides whether a failed run is retried; if nil, runs that exit with a non-zero exit
+// code are retried, and all other errors (e.g.: the command not being found) are returned
+// immediately.
+type RetryPolicy struct {
+	MaxAttempts int
+	Delay       time.Duration
+	Backoff     float64
+	RetryOn     func(error) bool
+}
+
+// Generates the command described by v (see Command) and runs it, returning its standard output
+// (unless the struct redirects it to a file, in which case the returned output is empty).
+// If the command fails, it is regenerated from v and run again according to the given policy, and
+// the output and error of the last run are returned.
+func ExecWithRetry(v interface{}, policy RetryPolicy) ([]byte, error) {
+	retryOn := policy.RetryOn
+
+	if retryOn == nil {
+		retryOn = isNonZeroExit
+	}
+
+	cfg := newConfig()
+	delay := policy.Delay
+
+	for attempt := 1; ; attempt++ {
+		cmd, err := buildCommand(nil, cfg, v)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var output []byte
+
+		// output that the struct redirects (see Redirect) is written to its file instead
+		if cmd.Stdout == nil {
+			output, err = cmd.Output()
+		} else {
+			err = cmd.Run()
+		}
+
+		ReleaseCommand(cmd)
+
+		if err == nil || attempt >= policy.MaxAttempts || !retryOn(err) {
+			return output, err
+		}
+
+		time.Sleep(delay)
+
+		if policy.Backoff > 1 {
+			delay = time.Duration(float64(delay) * policy.Backoff)
+		}
+	}
+}
+
+// reports whether the given error is the result of a command exiting with a non-zero exit code
+func isNonZeroExit(err error) bool {
+	var exitErr *exec.ExitError
+
+	return errors.As(err, &exitErr) && exitErr.ExitCode() != 0
+}
+
+// Re-runs the command described by v every interval, sending the result of each run on the returned
+// channel.  The command is regenerated from v on every tick, so changes made to v between runs are
+// reflected in subsequent executions.  Output streams that the struct redirects (see Redirect) are
+// written to their files instead of being captured in the result.  Canceling the given context
+// stops the ticker, kills any in-progress run, and closes the channel.
+func WatchAndRun(ctx context.Context, v interface{}, interval time.Duration) (<-chan RunResult, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval must be greater than zero")
+	}
+
+	cfg := newConfig()
+
+	// fail early if the command can't be generated at all
+	if cmd, err := buildCommand(nil, cfg, v); err == nil {
+		ReleaseCommand(cmd)
+	} else {
+		return nil, err
+	}
+
+	results := make(chan RunResult)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+
+		defer close(results)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				result := runOnce(ctx, cfg, v)
+
+				select {
+				case results <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return results, nil
+}
+
+// runs the command once, capturing any of its output streams that the struct does not redirect
+func runOnce(ctx context.Context, cfg *Config, v interface{}) RunResult {
+	var result RunResult
+	var stdout, stderr bytes.Buffer
+
+	result.StartedAt = time.Now()
+
+	if cmd, err := buildCommand(ctx, cfg, v); err == nil {
+		if cmd.Stdout == nil {
+			cmd.Stdout = &stdout
+		}
+
+		if cmd.Stderr == nil {
+			cmd.Stderr = &stderr
+		}
+
+		result.Err = cmd.Run()
+		ReleaseCommand(cmd)
+	} else {
+		result.Err = err
+	}
+
+	result.FinishedAt = time.Now()
+	result.Stdout = stdout.Bytes()
+	result.Stderr = stderr.Bytes()
+
+	return result
+}
@@ -0,0 +1,272 @@
+package argonaut
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type echo struct {
+	Command CommandName `argonaut:"echo"`
+	Words   []string    `argonaut:",positional"`
+}
+
+func TestWatchAndRun(t *testing.T) {
+	assert := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	results, err := WatchAndRun(ctx, &echo{
+		Words: []string{`hello`},
+	}, 10*time.Millisecond)
+
+	assert.NoError(err)
+
+	for i := 0; i < 2; i++ {
+		result := <-results
+
+		assert.NoError(result.Err)
+		assert.Equal("hello\n", string(result.Stdout))
+		assert.False(result.FinishedAt.Before(result.StartedAt))
+	}
+
+	cancel()
+
+	// the channel should be closed shortly after canceling
+	for range results {
+	}
+
+	_, err = WatchAndRun(context.Background(), &echo{}, 0)
+	assert.Error(err)
+}
+
+func TestWatchAndRunStreams(t *testing.T) {
+	assert := require.New(t)
+
+	type redirected struct {
+		Command CommandName `argonaut:"echo"`
+		Streams Redirect    `argonaut:",append=true"`
+		Words   []string    `argonaut:",positional"`
+	}
+
+	output := filepath.Join(t.TempDir(), `output.txt`)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	results, err := WatchAndRun(ctx, &redirected{
+		Streams: Redirect{
+			Stdout: output,
+		},
+		Words: []string{`hello`},
+	}, 10*time.Millisecond)
+
+	assert.NoError(err)
+
+	// redirected streams are written to their files, not captured
+	result := <-results
+	assert.NoError(result.Err)
+	assert.Empty(result.Stdout)
+
+	// (later runs may already be appending to the file)
+	data, err := os.ReadFile(output)
+	assert.NoError(err)
+	assert.True(strings.HasPrefix(string(data), "hello\n"))
+
+	cancel()
+
+	for range results {
+	}
+
+	// canceling the context kills a run that is in progress
+	type sleep struct {
+		Command CommandName `argonaut:"sleep"`
+		Seconds int         `argonaut:",positional"`
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	results, err = WatchAndRun(ctx, &sleep{Seconds: 10}, 10*time.Millisecond)
+	assert.NoError(err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	started := time.Now()
+	cancel()
+
+	for range results {
+	}
+
+	assert.True(time.Since(started) < 5*time.Second)
+}
+
+func TestRunWithTimeout(t *testing.T) {
+	assert := require.New(t)
+
+	type sleep struct {
+		Command CommandName `argonaut:"sleep"`
+		Seconds int         `argonaut:",positional"`
+	}
+
+	assert.NoError(Run(&echo{Words: []string{`hello`}}, WithTimeout(10*time.Second)))
+
+	started := time.Now()
+	assert.Error(Run(&sleep{Seconds: 10}, WithTimeout(100*time.Millisecond)))
+	assert.True(time.Since(started) < 5*time.Second)
+}
+
+func TestReleaseTimeout(t *testing.T) {
+	assert := require.New(t)
+
+	pending := func() int {
+		commandTimeoutsLock.Lock()
+		defer commandTimeoutsLock.Unlock()
+
+		return len(commandTimeouts)
+	}
+
+	cmd := MustCommand(&echo{Words: []string{`hello`}}, WithTimeout(time.Minute))
+
+	commandTimeoutsLock.Lock()
+	cancel := commandTimeouts[cmd]
+	canceled := false
+
+	commandTimeouts[cmd] = func() {
+		canceled = true
+		cancel()
+	}
+
+	commandTimeoutsLock.Unlock()
+
+	assert.NoError(cmd.Run())
+	assert.False(canceled)
+
+	// the context is canceled as soon as the command is released, well before its deadline
+	ReleaseCommand(cmd)
+	assert.True(canceled)
+	assert.Zero(pending())
+
+	// commands run by the package are released once they exit
+	assert.NoError(Run(&echo{Words: []string{`hello`}}, WithTimeout(time.Minute)))
+	assert.Zero(pending())
+
+	// commands that are never released are forgotten after their deadline
+	MustCommand(&echo{}, WithTimeout(10*time.Millisecond))
+	time.Sleep(100 * time.Millisecond)
+	assert.Zero(pending())
+}
+
+func TestCommandContext(t *testing.T) {
+	assert := require.New(t)
+
+	cmd := MustCommandContext(context.Background(), &echo{Words: []string{`hello`}})
+	output, err := cmd.Output()
+	assert.NoError(err)
+	assert.Equal("hello\n", string(output))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd = MustCommandContext(ctx, []string{`sleep`, `10`})
+	assert.NoError(cmd.Start())
+
+	started := time.Now()
+	cancel()
+	assert.Error(cmd.Wait())
+	assert.True(time.Since(started) < 5*time.Second)
+
+	_, err = CommandContext(nil, &echo{Words: []string{`hello`}})
+	assert.Error(err)
+
+	assert.Panics(func() {
+		MustCommandContext(context.Background(), []string{})
+	})
+}
+
+func TestExecWithRetry(t *testing.T) {
+	assert := require.New(t)
+
+	type shell struct {
+		Command CommandName `argonaut:"sh"`
+		Script  string      `argonaut:"c"`
+	}
+
+	counter := filepath.Join(t.TempDir(), `attempts`)
+
+	// fails twice before succeeding on the third attempt
+	flaky := &shell{
+		Script: fmt.Sprintf(`echo x >> %s; test $(wc -l < %s) -ge 3 && echo done`, counter, counter),
+	}
+
+	started := time.Now()
+	output, err := ExecWithRetry(flaky, RetryPolicy{
+		MaxAttempts: 5,
+		Delay:       10 * time.Millisecond,
+		Backoff:     2,
+	})
+
+	assert.NoError(err)
+	assert.Equal("done\n", string(output))
+	assert.True(time.Since(started) >= 30*time.Millisecond)
+
+	// gives up after MaxAttempts runs
+	assert.NoError(os.Remove(counter))
+	_, err = ExecWithRetry(flaky, RetryPolicy{
+		MaxAttempts: 2,
+	})
+
+	assert.Error(err)
+
+	data, err := os.ReadFile(counter)
+	assert.NoError(err)
+	assert.Equal("x\nx\n", string(data))
+
+	// errors rejected by RetryOn are returned immediately
+	assert.NoError(os.Remove(counter))
+	_, err = ExecWithRetry(flaky, RetryPolicy{
+		MaxAttempts: 5,
+		RetryOn: func(err error) bool {
+			return false
+		},
+	})
+
+	assert.Error(err)
+
+	data, err = os.ReadFile(counter)
+	assert.NoError(err)
+	assert.Equal("x\n", string(data))
+
+	// output redirected to a file is written there on every attempt
+	type redirected struct {
+		Command CommandName `argonaut:"sh"`
+		Streams Redirect
+		Script  string `argonaut:"c"`
+	}
+
+	assert.NoError(os.Remove(counter))
+	logfile := filepath.Join(t.TempDir(), `output.txt`)
+
+	output, err = ExecWithRetry(&redirected{
+		Streams: Redirect{
+			Stdout: logfile,
+		},
+		Script: flaky.Script,
+	}, RetryPolicy{
+		MaxAttempts: 5,
+	})
+
+	assert.NoError(err)
+	assert.Empty(output)
+
+	data, err = os.ReadFile(logfile)
+	assert.NoError(err)
+	assert.Equal("done\n", string(data))
+
+	// as are errors other than non-zero exit codes
+	_, err = ExecWithRetry([]string{`argonaut-command-that-does-not-exist`}, RetryPolicy{
+		MaxAttempts: 3,
+		Delay:       time.Hour,
+	})
+
+	assert.Error(err)
+}
@@ -0,0 +1,134 @@
+package argonaut
+
+import (
+	"reflect"
+
+	"github.com/ghetzel/go-stockutil/stringutil"
+)
+
+const JSONSchemaDraft07 = `http://json-schema.org/draft-07/schema#`
+
+// A subset of a JSON Schema (draft-07) document.
+type JSONSchema struct {
+	Schema      string                 `json:"$schema,omitempty"`
+	Title       string                 `json:"title,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Type        string                 `json:"type,omitempty"`
+	Properties  map[string]*JSONSchema `json:"properties,omitempty"`
+	Items       *JSONSchema            `json:"items,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+	Enum        []interface{}          `json:"enum,omitempty"`
+	Minimum     *float64               `json:"minimum,omitempty"`
+	Maximum     *float64               `json:"maximum,omitempty"`
+}
+
+// Returns a JSON Schema describing the fields of the given struct.  Properties are keyed on the
+// primary option name of each field, and use the "help", "choices", "min", "max", and "required"
+// tag options to populate the description, enum values, numeric range, and required fields.
+func Schema(v interface{}) (*JSONSchema, error) {
+	if structT, err := structTypeOf(v); err == nil {
+		if schema, err := structSchema(newConfig(), nil, structT); err == nil {
+			schema.Schema = JSONSchemaDraft07
+			schema.Title = structT.Name()
+
+			return schema, nil
+		} else {
+			return nil, err
+		}
+	} else {
+		return nil, err
+	}
+}
+
+func structSchema(cfg *Config, enclosing []reflect.Type, structT reflect.Type) (*JSONSchema, error) {
+	schema := &JSONSchema{
+		Type:       `object`,
+		Properties: make(map[string]*JSONSchema),
+	}
+
+	if err := walkFieldsWithin(cfg, enclosing, structT, nil, func(path []int, field reflect.StructField, tag *argonautTag) error {
+		fieldT := derefType(field.Type)
+
+		if fieldT == commandNameType || fieldT == argNameType {
+			return nil
+		}
+
+		name := cfg.primaryOption(tag, field.Name)
+		property, err := typeSchema(cfg, enclosingTypes(enclosing, structT, path), fieldT)
+
+		if err != nil {
+			return err
+		}
+
+		property.Description = tag.Help
+		property.Minimum = tag.Min
+		property.Maximum = tag.Max
+
+		for _, choice := range tag.Choices {
+			if fieldT.Kind() == reflect.String {
+				property.Enum = append(property.Enum, choice)
+			} else {
+				property.Enum = append(property.Enum, stringutil.Autotype(choice))
+			}
+		}
+
+		if tag.Required {
+			schema.Required = append(schema.Required, name)
+		}
+
+		schema.Properties[name] = property
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// returns the schema of values of the given type; enclosing holds the struct types whose schemas
+// are being generated by enclosing calls
+func typeSchema(cfg *Config, enclosing []reflect.Type, t reflect.Type) (*JSONSchema, error) {
+	t = derefType(t)
+
+	if t == orderedMapType {
+		return &JSONSchema{Type: `object`}, nil
+	} else if isLeafType(t) {
+		return &JSONSchema{
+			Type: `string`,
+		}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &JSONSchema{Type: `boolean`}, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: `integer`}, nil
+
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: `number`}, nil
+
+	case reflect.String:
+		return &JSONSchema{Type: `string`}, nil
+
+	case reflect.Slice, reflect.Array:
+		if items, err := typeSchema(cfg, enclosing, t.Elem()); err == nil {
+			return &JSONSchema{
+				Type:  `array`,
+				Items: items,
+			}, nil
+		} else {
+			return nil, err
+		}
+
+	case reflect.Map:
+		return &JSONSchema{Type: `object`}, nil
+
+	case reflect.Struct:
+		return structSchema(cfg, enclosing, t)
+
+	default:
+		return &JSONSchema{}, nil
+	}
+}
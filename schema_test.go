@@ -0,0 +1,56 @@
+package argonaut
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema(t *testing.T) {
+	assert := require.New(t)
+
+	type encoder struct {
+		Command CommandName `argonaut:"encoder"`
+		Preset  string      `argonaut:"preset,help=The encoding preset to use,choices=fast|slow"`
+		Quality int         `argonaut:"q,min=0,max=51"`
+		Verbose bool        `argonaut:"v"`
+		Inputs  []string    `argonaut:"input,positional,required"`
+		Codecs  []CodecOptions
+	}
+
+	schema, err := Schema(encoder{})
+	assert.NoError(err)
+
+	assert.Equal(JSONSchemaDraft07, schema.Schema)
+	assert.Equal(`encoder`, schema.Title)
+	assert.Equal(`object`, schema.Type)
+	assert.Equal([]string{`input`}, schema.Required)
+	assert.Len(schema.Properties, 5)
+
+	assert.Equal(`string`, schema.Properties[`preset`].Type)
+	assert.Equal(`The encoding preset to use`, schema.Properties[`preset`].Description)
+	assert.Equal([]interface{}{`fast`, `slow`}, schema.Properties[`preset`].Enum)
+
+	assert.Equal(`integer`, schema.Properties[`q`].Type)
+	assert.Equal(float64(0), *schema.Properties[`q`].Minimum)
+	assert.Equal(float64(51), *schema.Properties[`q`].Maximum)
+
+	assert.Equal(`boolean`, schema.Properties[`v`].Type)
+	assert.Equal(`array`, schema.Properties[`input`].Type)
+	assert.Equal(`string`, schema.Properties[`input`].Items.Type)
+
+	assert.Equal(`array`, schema.Properties[`codecs`].Type)
+	assert.Equal(`object`, schema.Properties[`codecs`].Items.Type)
+	assert.Contains(schema.Properties[`codecs`].Items.Properties, `parameters`)
+
+	_, err = json.Marshal(schema)
+	assert.NoError(err)
+
+	type invalid struct {
+		Quality int `argonaut:"q,min=low"`
+	}
+
+	_, err = Schema(invalid{})
+	assert.Error(err)
+}
@@ -0,0 +1,188 @@
+package argonaut
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var shellSafeToken = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// unlike POSIX shells, older versions of fish expand a leading "%" into process IDs
+var fishSafeToken = regexp.MustCompile(`^[A-Za-z0-9_@+=:,./-]+$`)
+
+// Returns a single string containing the given arguments separated by spaces, suitable for pasting
+// into (or evaluating with) a POSIX shell.  Arguments that are empty or contain characters that
+// have special meaning to the shell are enclosed in single quotes.
+func SerializeArgs(args []string) string {
+	quoted := make([]string, len(args))
+
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+
+	return strings.Join(quoted, ` `)
+}
+
+// quotes a single argument for use in a POSIX shell, only if necessary
+func shellQuote(arg string) string {
+	if shellSafeToken.MatchString(arg) {
+		return arg
+	}
+
+	return `'` + strings.Replace(arg, `'`, `'\''`, -1) + `'`
+}
+
+// Marshals the given struct into a command line string that a POSIX shell will split back into the
+// same arguments, as SerializeArgs does, but escaping each character that has special meaning to
+// the shell with a backslash instead of quoting the whole argument (e.g.: "some\ file.txt" rather
+// than "'some file.txt'").  Empty arguments and newlines, which cannot be escaped with a backslash,
+// are single-quoted.
+func MarshalEscaped(v interface{}, opts ...Option) ([]byte, error) {
+	if args, err := Parse(v, opts...); err == nil {
+		escaped := make([]string, len(args))
+
+		for i, arg := range args {
+			escaped[i] = shellEscape(arg)
+		}
+
+		return []byte(strings.Join(escaped, ` `)), nil
+	} else {
+		return nil, err
+	}
+}
+
+// backslash-escapes the special characters in a single argument for use in a POSIX shell
+func shellEscape(arg string) string {
+	if arg == `` {
+		return `''`
+	}
+
+	var out strings.Builder
+
+	for i := 0; i < len(arg); i++ {
+		c := arg[i]
+
+		switch {
+		case c == '\n':
+			// an escaped newline is a line continuation, which the shell removes entirely
+			out.WriteString("'\n'")
+		case c < 0x80 && !shellSafeToken.Match([]byte{c}):
+			out.WriteByte('\\')
+			out.WriteByte(c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+
+	return out.String()
+}
+
+// Marshals the given struct into a command line string that can be pasted into the fish shell.
+// Arguments that are empty or contain characters that have special meaning to fish (including
+// braces, which fish expands even within words) are enclosed in single quotes.  Unlike in POSIX
+// shells, backslashes and single quotes within single quotes are escaped with a backslash (e.g.:
+// "'it\'s'"), and newlines and tabs are kept literally rather than as "\n" or "\t" sequences.
+func MarshalFish(v interface{}, opts ...Option) ([]byte, error) {
+	if args, err := Parse(v, opts...); err == nil {
+		quoted := make([]string, len(args))
+
+		for i, arg := range args {
+			quoted[i] = fishQuote(arg)
+		}
+
+		return []byte(strings.Join(quoted, ` `)), nil
+	} else {
+		return nil, err
+	}
+}
+
+// quotes a single argument for use in the fish shell, only if necessary
+func fishQuote(arg string) string {
+	if fishSafeToken.MatchString(arg) {
+		return arg
+	}
+
+	return `'` + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(arg) + `'`
+}
+
+// Splits a string into words according to POSIX shell quoting rules, the inverse of SerializeArgs.
+// Words are separated by unquoted whitespace.  Characters inside single quotes are taken
+// literally; inside double quotes, a backslash only escapes "$", "`", `"`, "\", and newline (and is
+// otherwise kept).  Outside of quotes, a backslash escapes any character, and a backslash followed
+// by a newline is removed entirely.  No expansion of variables, commands, or globs takes place.
+func Tokenize(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+
+		case c == '\\':
+			if i+1 >= len(s) {
+				return nil, fmt.Errorf("unterminated escape at end of input")
+			}
+
+			i += 1
+
+			if s[i] != '\n' {
+				word.WriteByte(s[i])
+				inWord = true
+			}
+
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote at position %d", i)
+			}
+
+			word.WriteString(s[i+1 : i+1+end])
+			inWord = true
+			i += end + 1
+
+		case c == '"':
+			start := i
+			closed := false
+			inWord = true
+
+			for i += 1; i < len(s); i++ {
+				if s[i] == '"' {
+					closed = true
+					break
+				} else if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("$`\"\\\n", s[i+1]) >= 0 {
+					if s[i+1] != '\n' {
+						word.WriteByte(s[i+1])
+					}
+
+					i += 1
+				} else {
+					word.WriteByte(s[i])
+				}
+			}
+
+			if !closed {
+				return nil, fmt.Errorf("unterminated double quote at position %d", start)
+			}
+
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
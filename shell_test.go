@@ -0,0 +1,111 @@
+package argonaut
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerializeArgs(t *testing.T) {
+	assert := require.New(t)
+
+	args := []string{
+		`ls`,
+		`--block-size=1024`,
+		`/tmp/some file.txt`,
+		``,
+		`it's`,
+		`$HOME`,
+		`*.go`,
+		"multi\nline",
+		`a;b|c&d`,
+		`C:\path`,
+	}
+
+	assert.Equal(`ls --block-size=1024 '/tmp/some file.txt' '' 'it'\''s' '$HOME' '*.go' 'multi`+"\n"+`line' 'a;b|c&d' 'C:\path'`, SerializeArgs(args))
+	assert.Equal(``, SerializeArgs(nil))
+
+	// round-trip the arguments through an actual shell, if one is available
+	if sh, err := exec.LookPath(`sh`); err == nil {
+		output, err := exec.Command(sh, `-c`, `printf '%s\0' `+SerializeArgs(args[1:])).Output()
+		assert.NoError(err)
+		assert.Equal(args[1:], strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00"))
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	assert := require.New(t)
+
+	cases := map[string][]string{
+		``:                              nil,
+		"  \t\n ":                       nil,
+		`ls -l /tmp`:                    {`ls`, `-l`, `/tmp`},
+		`echo 'single $HOME \n' "x"`:    {`echo`, `single $HOME \n`, `x`},
+		`echo "a \$b \"c\" \\ \d"`:      {`echo`, `a $b "c" \ \d`},
+		`echo "$HOME" '$HOME' \$HOME`:   {`echo`, `$HOME`, `$HOME`, `$HOME`},
+		`a\ b c\\d`:                     {`a b`, `c\d`},
+		`pre'mid'"post" ''`:             {`premidpost`, ``},
+		"line\\\ncontinued":             {`linecontinued`},
+		"\"quoted\\\nnewline\"":         {`quotednewline`},
+		"`cmd` \"`cmd`\" \"\\`cmd\\`\"": {"`cmd`", "`cmd`", "`cmd`"},
+	}
+
+	for input, expected := range cases {
+		words, err := Tokenize(input)
+		assert.NoError(err, input)
+		assert.Equal(expected, words, input)
+	}
+
+	for _, input := range []string{`echo 'open`, `echo "open`, `trailing\`} {
+		_, err := Tokenize(input)
+		assert.Error(err, input)
+	}
+
+	// tokenizing is the inverse of serializing
+	args := []string{`ls`, `/tmp/some file.txt`, ``, `it's`, `$HOME`, "multi\nline", `C:\path`}
+	words, err := Tokenize(SerializeArgs(args))
+	assert.NoError(err)
+	assert.Equal(args, words)
+}
+
+func TestMarshalEscaped(t *testing.T) {
+	assert := require.New(t)
+
+	input := &echo{
+		Words: []string{`/tmp/some file.txt`, ``, `it's`, `$HOME`, `*.go`, "multi\nline", `a;b|c&d`, `C:\path`, `héllo`},
+	}
+
+	output, err := MarshalEscaped(input)
+	assert.NoError(err)
+	assert.Equal(`echo /tmp/some\ file.txt '' it\'s \$HOME \*.go multi'`+"\n"+`'line a\;b\|c\&d C:\\path héllo`, string(output))
+
+	// the escaped arguments are split back into the originals
+	words, err := Tokenize(string(output))
+	assert.NoError(err)
+	assert.Equal(MustParse(input), words)
+
+	if sh, err := exec.LookPath(`sh`); err == nil {
+		output, err := exec.Command(sh, `-c`, `printf '%s\0' `+string(output[len(`echo `):])).Output()
+		assert.NoError(err)
+		assert.Equal(input.Words, strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00"))
+	}
+
+	_, err = MarshalEscaped(`nope`)
+	assert.Error(err)
+}
+
+func TestMarshalFish(t *testing.T) {
+	assert := require.New(t)
+
+	output, err := MarshalFish(&echo{
+		Words: []string{`/tmp/file.txt`, `some file`, ``, `it's`, `C:\path`, `{a,b}`, `%self`, "tab\there", `$HOME`},
+	})
+
+	assert.NoError(err)
+	assert.Equal(`echo /tmp/file.txt 'some file' '' 'it\'s' 'C:\\path' '{a,b}' '%self' '`+"tab\there"+`' '$HOME'`, string(output))
+
+	_, err = MarshalFish(`nope`)
+	assert.Error(err)
+}
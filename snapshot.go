@@ -0,0 +1,47 @@
+package argonaut
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+)
+
+// Serializes the values of the given struct's exported fields into a compact binary form that can
+// later be passed to Restore.  Unlike Parse and Marshal, this captures the Go values themselves
+// (including fields that would not appear in the command line), making it suitable for
+// checkpointing a command's state between process restarts or before retrying a request.
+// Interface-typed values (e.g.: in an OrderedMap) of non-builtin types must be registered with
+// gob.Register.
+func Snapshot(v interface{}) ([]byte, error) {
+	if v == nil {
+		return nil, fmt.Errorf("Cannot snapshot a nil value")
+	}
+
+	var buf bytes.Buffer
+
+	if err := gob.NewEncoder(&buf).Encode(v); err == nil {
+		return buf.Bytes(), nil
+	} else {
+		return nil, fmt.Errorf("Cannot snapshot %T: %v", v, err)
+	}
+}
+
+// Restores the field values previously captured with Snapshot into v, which must be a pointer to
+// a struct of the same type.  Any existing values in v are discarded.
+func Restore(data []byte, v interface{}) error {
+	vV := reflect.ValueOf(v)
+
+	if vV.Kind() != reflect.Ptr || vV.IsNil() {
+		return fmt.Errorf("Restore requires a non-nil pointer, got %T", v)
+	}
+
+	restored := reflect.New(vV.Elem().Type())
+
+	if err := gob.NewDecoder(bytes.NewReader(data)).DecodeValue(restored); err == nil {
+		vV.Elem().Set(restored.Elem())
+		return nil
+	} else {
+		return fmt.Errorf("Cannot restore %T: %v", v, err)
+	}
+}
@@ -0,0 +1,62 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	assert := require.New(t)
+
+	type encoder struct {
+		Command  CommandName `argonaut:"encode"`
+		Preset   string      `argonaut:"preset,long"`
+		Threads  *int        `argonaut:"threads,long"`
+		Scale    complex128  `argonaut:"scale,long,complex"`
+		Options  OrderedMap  `argonaut:",long"`
+		Internal string      `argonaut:"-"`
+		Inputs   []string    `argonaut:",positional"`
+	}
+
+	threads := 4
+	input := &encoder{
+		Preset:  `slow`,
+		Threads: &threads,
+		Scale:   complex(1, 2),
+		Options: OrderedMap{
+			{Key: `crf`, Value: 23},
+			{Key: `tune`, Value: `film`},
+		},
+		Internal: `retained`,
+		Inputs:   []string{`a.mkv`, `b.mkv`},
+	}
+
+	data, err := Snapshot(input)
+	assert.NoError(err)
+	assert.NotEmpty(data)
+
+	// existing values in the destination are discarded
+	output := &encoder{
+		Preset: `fast`,
+		Inputs: []string{`c.mkv`},
+	}
+
+	assert.NoError(Restore(data, output))
+	assert.Equal(input, output)
+	assert.Equal(MustParse(input), MustParse(output))
+
+	// snapshots of values (rather than pointers) restore the same way
+	data, err = Snapshot(*input)
+	assert.NoError(err)
+
+	var fromValue encoder
+	assert.NoError(Restore(data, &fromValue))
+	assert.Equal(*input, fromValue)
+
+	assert.Error(Restore(data, fromValue))
+	assert.Error(Restore([]byte(`garbage`), &fromValue))
+
+	_, err = Snapshot(nil)
+	assert.Error(err)
+}
@@ -0,0 +1,91 @@
+package argonaut
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Generates the arguments for the given struct as Parse does, but with the fields named in
+// substitutions set to the corresponding values first.  Fields are named by their Go field names;
+// fields of nested structs are named by joining the names with a dot (e.g.: "Network.Port").  The
+// substitutions are applied to a copy, leaving v unmodified.  Values are converted to the field's
+// type as with ParseJSON, and a nil value resets the field to its zero value.
+func Substitute(v interface{}, substitutions map[string]interface{}) ([]string, error) {
+	structT, err := structTypeOf(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	structV := reflect.Indirect(reflect.ValueOf(v))
+
+	if !structV.IsValid() {
+		return nil, fmt.Errorf("Cannot substitute fields of a nil %T", v)
+	}
+
+	cfg := newConfig()
+	copyV := reflect.New(structT)
+	copyV.Elem().Set(structV)
+
+	names := make([]string, 0, len(substitutions))
+
+	for name := range substitutions {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if target, err := substitutionTarget(copyV.Elem(), name); err == nil {
+			if value := substitutions[name]; value == nil {
+				target.Set(reflect.Zero(target.Type()))
+			} else if err := setFieldFromInterface(cfg, target, value); err != nil {
+				return nil, fmt.Errorf("field %s: %v", name, err)
+			}
+		} else {
+			return nil, err
+		}
+	}
+
+	if command, _, err := generateCommand(cfg, copyV.Interface(), true, false); err == nil {
+		return command, nil
+	} else {
+		return nil, err
+	}
+}
+
+// resolves a (possibly dotted) field name to a settable field, copying any structs that are
+// reached through pointers so that the original value is never modified
+func substitutionTarget(structV reflect.Value, name string) (reflect.Value, error) {
+	current := structV
+	parts := strings.Split(name, `.`)
+
+	for i, part := range parts {
+		if i > 0 {
+			if current.Kind() == reflect.Ptr {
+				clone := reflect.New(current.Type().Elem())
+
+				if !current.IsNil() {
+					clone.Elem().Set(current.Elem())
+				}
+
+				current.Set(clone)
+				current = clone.Elem()
+			}
+
+			if current.Kind() != reflect.Struct {
+				return reflect.Value{}, fmt.Errorf("Cannot substitute field %q: %s is not a struct", name, strings.Join(parts[:i], `.`))
+			}
+		}
+
+		current = current.FieldByName(part)
+
+		if !current.IsValid() || !current.CanSet() {
+			return reflect.Value{}, fmt.Errorf("Cannot substitute field %q: no such field", name)
+		}
+	}
+
+	return current, nil
+}
@@ -0,0 +1,69 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubstitute(t *testing.T) {
+	assert := require.New(t)
+
+	type network struct {
+		Host string `argonaut:"host,long"`
+		Port int    `argonaut:"port,long"`
+	}
+
+	type server struct {
+		Command CommandName `argonaut:"serve"`
+		Verbose bool        `argonaut:"v"`
+		Workers int         `argonaut:"workers,long"`
+		Network *network
+		Roots   []string `argonaut:",positional"`
+		secret  string
+	}
+
+	input := &server{
+		Workers: 2,
+		Network: &network{
+			Host: `localhost`,
+			Port: 80,
+		},
+		Roots: []string{`/srv`},
+	}
+
+	args, err := Substitute(input, map[string]interface{}{
+		`Verbose`:      true,
+		`Workers`:      `8`,
+		`Network.Port`: 8080,
+		`Roots`:        []interface{}{`/a`, `/b`},
+	})
+
+	assert.NoError(err)
+	assert.Equal([]string{`serve`, `-v`, `--workers`, `8`, `--host`, `localhost`, `--port`, `8080`, `/a`, `/b`}, args)
+
+	// the original is left untouched
+	assert.Equal([]string{`serve`, `--workers`, `2`, `--host`, `localhost`, `--port`, `80`, `/srv`}, MustParse(input))
+
+	// nil values reset fields to their zero value
+	args, err = Substitute(*input, map[string]interface{}{
+		`Network`: nil,
+	})
+
+	assert.NoError(err)
+	assert.Equal([]string{`serve`, `--workers`, `2`, `/srv`}, args)
+
+	for _, name := range []string{`Missing`, `secret`, `Workers.Count`, `Network.Missing`} {
+		_, err = Substitute(input, map[string]interface{}{
+			name: 1,
+		})
+
+		assert.Error(err, name)
+	}
+
+	_, err = Substitute(input, map[string]interface{}{
+		`Workers`: `many`,
+	})
+
+	assert.Error(err)
+}
@@ -0,0 +1,138 @@
+package argonaut
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// A Template holds a partially-specified command line that can be reused as the basis for many
+// concrete commands.
+type Template struct {
+	args []string
+}
+
+// Parses the given command line into a reusable Template.  Arguments are separated by whitespace;
+// quoting is not supported.
+func ParseTemplate(tmpl string) (*Template, error) {
+	args := strings.Fields(tmpl)
+
+	if len(args) == 0 {
+		return nil, fmt.Errorf("Cannot parse empty template")
+	}
+
+	return &Template{
+		args: args,
+	}, nil
+}
+
+// Returns the arguments the template was created from.
+func (self *Template) Args() []string {
+	return append([]string{}, self.args...)
+}
+
+// Unmarshals the template arguments into a new instance of v's type, then overlays all non-zero
+// fields from v on top of it.  The merged struct is then parsed into a slice of arguments.  The
+// value of v itself is not modified.
+func (self *Template) Apply(v interface{}) ([]string, error) {
+	vV := reflect.ValueOf(v)
+
+	for vV.Kind() == reflect.Ptr {
+		if vV.IsNil() {
+			return nil, fmt.Errorf("Cannot apply template to a nil value")
+		}
+
+		vV = vV.Elem()
+	}
+
+	if vV.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("struct needed, got %T", v)
+	}
+
+	cfg := newConfig()
+	base := reflect.New(vV.Type())
+
+	if err := unmarshalCommand(cfg, self.args, base.Interface(), nil); err != nil {
+		return nil, err
+	}
+
+	overlayNonZero(base.Elem(), vV)
+
+	if command, _, err := generateCommand(cfg, base.Interface(), true, false); err == nil {
+		return command, nil
+	} else {
+		return nil, err
+	}
+}
+
+// copies all exported, non-zero fields from src onto dst (which must be of the same struct type),
+// descending into nested structs so that their fields are merged rather than replaced
+func overlayNonZero(dst reflect.Value, src reflect.Value) {
+	for i := 0; i < src.NumField(); i++ {
+		if src.Type().Field(i).PkgPath != `` {
+			continue
+		}
+
+		srcF := src.Field(i)
+		dstF := dst.Field(i)
+
+		if srcF.IsZero() {
+			continue
+		}
+
+		switch {
+		case srcF.Kind() == reflect.Struct && !isLeafType(srcF.Type()):
+			overlayNonZero(dstF, srcF)
+
+		case srcF.Kind() == reflect.Ptr && srcF.Elem().Kind() == reflect.Struct && !isLeafType(srcF.Elem().Type()):
+			if dstF.IsNil() {
+				dstF.Set(reflect.New(srcF.Elem().Type()))
+			}
+
+			overlayNonZero(dstF.Elem(), srcF.Elem())
+
+		default:
+			dstF.Set(srcF)
+		}
+	}
+}
+
+// expands a string value as a template (if TemplateString was given), using the values of the
+// fields of the struct it belongs to as data
+func (self *Config) expandTemplate(fieldName string, value interface{}, data map[string]interface{}) (interface{}, error) {
+	if !self.templating {
+		return value, nil
+	}
+
+	switch value.(type) {
+	case CommandName, ArgName:
+		return value, nil
+	}
+
+	rV := reflect.ValueOf(value)
+
+	if rV.Kind() != reflect.String || !strings.Contains(rV.String(), `{{`) {
+		return value, nil
+	}
+
+	if self.templateBase == nil {
+		if base, err := template.New(``).Option(`missingkey=error`).Parse(self.templateDefs); err == nil {
+			self.templateBase = base
+		} else {
+			return nil, err
+		}
+	}
+
+	var out strings.Builder
+
+	if tmpl, err := self.templateBase.Clone(); err != nil {
+		return nil, err
+	} else if tmpl, err = tmpl.New(fieldName).Parse(rV.String()); err != nil {
+		return nil, err
+	} else if err := tmpl.Execute(&out, data); err != nil {
+		return nil, err
+	}
+
+	return out.String(), nil
+}
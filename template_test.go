@@ -0,0 +1,76 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplate(t *testing.T) {
+	assert := require.New(t)
+
+	tmpl, err := ParseTemplate(`ls -a --block-size=512 /base`)
+	assert.NoError(err)
+
+	job := &ls{
+		HumanReadable: true,
+		BlockSize:     1024,
+	}
+
+	args, err := tmpl.Apply(job)
+	assert.NoError(err)
+	assert.Equal([]string{`ls`, `--all`, `--block-size=1024`, `--human-readable`, `/base`}, args)
+
+	// the applied value should not be modified
+	assert.False(job.All)
+
+	args, err = tmpl.Apply(ls{Paths: []string{`/other`}})
+	assert.NoError(err)
+	assert.Equal([]string{`ls`, `--all`, `--block-size=512`, `/other`}, args)
+
+	_, err = ParseTemplate(`   `)
+	assert.Error(err)
+}
+
+func TestTemplateString(t *testing.T) {
+	assert := require.New(t)
+
+	type transcode struct {
+		Command   CommandName `argonaut:"transcode"`
+		OutputDir string      `argonaut:"-"`
+		Basename  string      `argonaut:"-"`
+		Input     string      `argonaut:"i"`
+		Output    string      `argonaut:",positional"`
+	}
+
+	input := &transcode{
+		OutputDir: `/srv/media`,
+		Basename:  `movie`,
+		Input:     `{{.Basename}}.avi`,
+		Output:    `{{.OutputDir}}/{{.Basename}}.{{template "ext"}}`,
+	}
+
+	args, err := Parse(input, TemplateString(`{{define "ext"}}mp4{{end}}`))
+	assert.NoError(err)
+	assert.Equal([]string{`transcode`, `-i`, `movie.avi`, `/srv/media/movie.mp4`}, args)
+
+	output, err := Marshal(input, TemplateString(`{{define "ext"}}mkv{{end}}`))
+	assert.NoError(err)
+	assert.Equal(`transcode -i movie.avi /srv/media/movie.mkv`, string(output))
+
+	// without the option, values are emitted as-is
+	assert.Equal(`{{.Basename}}.avi`, MustParse(input)[2])
+
+	// template errors are returned
+	input.Input = `{{.Missing}}`
+	_, err = Parse(input, TemplateString(`{{define "ext"}}mp4{{end}}`))
+	assert.Error(err)
+	assert.Contains(err.Error(), `field Input:`)
+
+	input.Input = `{{.Basename`
+	_, err = Parse(input, TemplateString(``))
+	assert.Error(err)
+
+	_, err = Parse(&transcode{Input: `{{.Basename}}`}, TemplateString(`{{define`))
+	assert.Error(err)
+}
@@ -0,0 +1,47 @@
+package argonaut
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// named layouts accepted by the "timefmt" tag option
+var timeFormatPresets = map[string]string{
+	`rfc3339`:  time.RFC3339,
+	`iso8601`:  `2006-01-02T15:04:05Z0700`,
+	`date`:     `2006-01-02`,
+	`datetime`: `2006-01-02 15:04:05`,
+}
+
+// formats time.Time values (or pointers to them) using the given layout: either a named preset,
+// "unix" for a Unix timestamp, or a Go time layout.  An empty layout uses RFC3339.
+func formatTime(value interface{}, layout string) (string, bool, bool) {
+	rV := reflect.ValueOf(value)
+
+	for rV.Kind() == reflect.Ptr {
+		if rV.IsNil() {
+			return ``, false, false
+		}
+
+		rV = rV.Elem()
+	}
+
+	if !rV.IsValid() || rV.Type() != timeType {
+		return ``, false, false
+	}
+
+	t := rV.Interface().(time.Time)
+
+	if layout == `unix` {
+		return strconv.FormatInt(t.Unix(), 10), t.IsZero(), true
+	} else if preset, ok := timeFormatPresets[layout]; ok {
+		layout = preset
+	} else if layout == `` {
+		layout = time.RFC3339
+	}
+
+	return t.Format(layout), t.IsZero(), true
+}
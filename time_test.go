@@ -0,0 +1,60 @@
+package argonaut
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeFields(t *testing.T) {
+	assert := require.New(t)
+
+	type logs struct {
+		Command CommandName `argonaut:"logs"`
+		Since   time.Time   `argonaut:"since,long"`
+		Until   *time.Time  `argonaut:"until,long,timefmt=unix"`
+		Day     time.Time   `argonaut:"day,long,timefmt=date"`
+		At      time.Time   `argonaut:"at,long,timefmt=datetime"`
+		ISO     time.Time   `argonaut:"iso,long,timefmt=iso8601"`
+		Custom  time.Time   `argonaut:"custom,long,timefmt=[Jan 2, 2006]"`
+		Marks   []time.Time `argonaut:",positional,timefmt=15:04"`
+	}
+
+	moment := time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC)
+
+	assert.Equal([]string{
+		`logs`,
+		`--since`, `2021-03-04T05:06:07Z`,
+		`--until`, `1614834367`,
+		`--day`, `2021-03-04`,
+		`--at`, `2021-03-04 05:06:07`,
+		`--iso`, `2021-03-04T05:06:07Z`,
+		`--custom`, `Mar 4, 2021`,
+		`05:06`, `06:06`,
+	}, MustParse(&logs{
+		Since:  moment,
+		Until:  &moment,
+		Day:    moment,
+		At:     moment,
+		ISO:    moment,
+		Custom: moment,
+		Marks:  []time.Time{moment, moment.Add(time.Hour)},
+	}))
+
+	// zero times are omitted like any other zero value
+	assert.Equal([]string{`logs`}, MustParse(&logs{}))
+
+	assert.Equal([]string{`logs`, `--since`, `0001-01-01T00:00:00Z`}, MustParse(&struct {
+		Command CommandName `argonaut:"logs"`
+		Since   time.Time   `argonaut:"since,long,emit_zero"`
+	}{}))
+
+	result := Validate(&struct {
+		Command CommandName `argonaut:"logs"`
+		Since   string      `argonaut:"since,long,timefmt=unix"`
+	}{})
+
+	assert.False(result.OK())
+	assert.Equal(DiagnosticTypeMismatch, result.Errors[0].Code)
+}
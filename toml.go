@@ -0,0 +1,640 @@
+package argonaut
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var tomlBareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+var tomlInteger = regexp.MustCompile(`^([-+]?(0|[1-9][0-9]*)|0x[0-9A-Fa-f]+|0o[0-7]+|0b[01]+)$`)
+var tomlFloat = regexp.MustCompile(`^[-+]?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+// Serializes the field values of the given struct (not the command line it generates) as a TOML
+// document.  Keys are the primary option name of each field.  Zero-valued fields are omitted,
+// unless they specify a "default" tag option, in which case they are included as a comment showing
+// the default value.  Slices of structs are written as arrays of tables.
+func MarshalTOML(v interface{}) ([]byte, error) {
+	vV := reflect.ValueOf(v)
+
+	for vV.Kind() == reflect.Ptr {
+		vV = vV.Elem()
+	}
+
+	if vV.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("struct needed, got %T", v)
+	}
+
+	var buf bytes.Buffer
+
+	if err := encodeTOMLTable(newConfig(), &buf, vV, ``); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func encodeTOMLTable(cfg *Config, buf *bytes.Buffer, structV reflect.Value, prefix string) error {
+	var tables bytes.Buffer
+
+	if err := walkFields(cfg, structV.Type(), nil, func(path []int, field reflect.StructField, tag *argonautTag) error {
+		fieldT := derefType(field.Type)
+
+		if fieldT == commandNameType || fieldT == argNameType {
+			return nil
+		}
+
+		key := tomlKey(cfg.primaryOption(tag, field.Name))
+		fieldV, ok := fieldValueByPath(structV, path)
+
+		if !ok || fieldV.IsZero() {
+			if tag.Default != `` {
+				if fieldT.Kind() == reflect.String {
+					fmt.Fprintf(buf, "# %s = %s\n", key, tomlString(tag.Default))
+				} else {
+					fmt.Fprintf(buf, "# %s = %s\n", key, tag.Default)
+				}
+			}
+
+			return nil
+		}
+
+		if _, ok := structSliceElem(fieldT); ok {
+			fieldV = reflect.Indirect(fieldV)
+
+			for i := 0; i < fieldV.Len(); i++ {
+				fmt.Fprintf(&tables, "\n[[%s%s]]\n", prefix, key)
+
+				if err := encodeTOMLTable(cfg, &tables, reflect.Indirect(fieldV.Index(i)), prefix+key+`.`); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+
+		if value, err := tomlValue(cfg, fieldV); err == nil {
+			fmt.Fprintf(buf, "%s = %s\n", key, value)
+		} else {
+			return fmt.Errorf("field %s: %v", field.Name, err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	_, err := tables.WriteTo(buf)
+	return err
+}
+
+func tomlValue(cfg *Config, value reflect.Value) (string, error) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return `""`, nil
+		}
+
+		value = value.Elem()
+	}
+
+	if value.CanInterface() {
+		if t, ok := value.Interface().(time.Time); ok {
+			return t.Format(time.RFC3339Nano), nil
+		} else if fn, rvalue, ok := registeredSerializer(value.Interface()); ok {
+			if str, err := fn(rvalue); err == nil {
+				return tomlString(str), nil
+			} else {
+				return ``, err
+			}
+		} else if marshaler, ok := value.Interface().(encoding.TextMarshaler); ok {
+			if text, err := marshaler.MarshalText(); err == nil {
+				return tomlString(string(text)), nil
+			} else {
+				return ``, err
+			}
+		}
+	}
+
+	if om, ok := value.Interface().(OrderedMap); ok {
+		pairs := make([]string, 0, len(om))
+
+		for _, kv := range om {
+			if item, err := tomlValue(cfg, reflect.ValueOf(&kv.Value).Elem()); err == nil {
+				pairs = append(pairs, tomlKey(kv.Key)+` = `+item)
+			} else {
+				return ``, err
+			}
+		}
+
+		return `{ ` + strings.Join(pairs, `, `) + ` }`, nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return tomlString(value.String()), nil
+
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), nil
+
+	case reflect.Float32, reflect.Float64:
+		f := value.Float()
+
+		switch {
+		case math.IsNaN(f):
+			return `nan`, nil
+		case math.IsInf(f, 1):
+			return `inf`, nil
+		case math.IsInf(f, -1):
+			return `-inf`, nil
+		}
+
+		str := strconv.FormatFloat(f, 'f', -1, 64)
+
+		if !strings.ContainsAny(str, `.e`) {
+			str += `.0`
+		}
+
+		return str, nil
+
+	case reflect.Slice, reflect.Array:
+		items := make([]string, 0, value.Len())
+
+		for i := 0; i < value.Len(); i++ {
+			if item, err := tomlValue(cfg, value.Index(i)); err == nil {
+				items = append(items, item)
+			} else {
+				return ``, err
+			}
+		}
+
+		return `[` + strings.Join(items, `, `) + `]`, nil
+
+	case reflect.Map:
+		pairs := make([]string, 0, value.Len())
+
+		for _, key := range value.MapKeys() {
+			if item, err := tomlValue(cfg, value.MapIndex(key)); err == nil {
+				pairs = append(pairs, tomlKey(fmt.Sprintf("%v", key.Interface()))+` = `+item)
+			} else {
+				return ``, err
+			}
+		}
+
+		sort.Strings(pairs)
+
+		return `{ ` + strings.Join(pairs, `, `) + ` }`, nil
+
+	case reflect.Struct:
+		pairs := make([]string, 0)
+
+		if err := walkFields(cfg, value.Type(), nil, func(path []int, field reflect.StructField, tag *argonautTag) error {
+			if fieldV, ok := fieldValueByPath(value, path); ok && !fieldV.IsZero() {
+				if item, err := tomlValue(cfg, fieldV); err == nil {
+					pairs = append(pairs, tomlKey(cfg.primaryOption(tag, field.Name))+` = `+item)
+				} else {
+					return err
+				}
+			}
+
+			return nil
+		}); err != nil {
+			return ``, err
+		}
+
+		return `{ ` + strings.Join(pairs, `, `) + ` }`, nil
+
+	default:
+		return ``, fmt.Errorf("cannot represent %v as TOML", value.Type())
+	}
+}
+
+func tomlKey(key string) string {
+	if tomlBareKey.MatchString(key) {
+		return key
+	} else {
+		return tomlString(key)
+	}
+}
+
+func tomlString(in string) string {
+	var out strings.Builder
+
+	out.WriteRune('"')
+
+	for _, r := range in {
+		switch r {
+		case '"':
+			out.WriteString(`\"`)
+		case '\\':
+			out.WriteString(`\\`)
+		case '\b':
+			out.WriteString(`\b`)
+		case '\t':
+			out.WriteString(`\t`)
+		case '\n':
+			out.WriteString(`\n`)
+		case '\f':
+			out.WriteString(`\f`)
+		case '\r':
+			out.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&out, "\\u%04X", r)
+			} else {
+				out.WriteRune(r)
+			}
+		}
+	}
+
+	out.WriteRune('"')
+
+	return out.String()
+}
+
+// parses a subset of TOML: tables, arrays of tables, dotted keys, and all value types except for
+// multi-line strings
+func parseTOML(doc string) (map[string]interface{}, error) {
+	root := make(map[string]interface{})
+	current := root
+	lines := strings.Split(strings.Replace(doc, "\r\n", "\n", -1), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		lineNumber := i + 1
+		line := strings.TrimSpace(lines[i])
+
+		if line == `` || strings.HasPrefix(line, `#`) {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, `[[`):
+			keys, rest, err := parseTOMLKey(line[2:], lineNumber)
+
+			if err != nil {
+				return nil, err
+			} else if rest = stripTOMLComment(rest); rest != `]]` {
+				return nil, fmt.Errorf("toml: line %d: invalid array table header", lineNumber)
+			}
+
+			parent, err := tomlTable(root, keys[:len(keys)-1], lineNumber)
+
+			if err != nil {
+				return nil, err
+			}
+
+			last := keys[len(keys)-1]
+			table := make(map[string]interface{})
+
+			switch existing := parent[last].(type) {
+			case nil:
+				parent[last] = []interface{}{table}
+			case []interface{}:
+				parent[last] = append(existing, table)
+			default:
+				return nil, fmt.Errorf("toml: line %d: key %q is already defined", lineNumber, last)
+			}
+
+			current = table
+
+		case strings.HasPrefix(line, `[`):
+			keys, rest, err := parseTOMLKey(line[1:], lineNumber)
+
+			if err != nil {
+				return nil, err
+			} else if rest = stripTOMLComment(rest); rest != `]` {
+				return nil, fmt.Errorf("toml: line %d: invalid table header", lineNumber)
+			}
+
+			if table, err := tomlTable(root, keys, lineNumber); err == nil {
+				current = table
+			} else {
+				return nil, err
+			}
+
+		default:
+			keys, rest, err := parseTOMLKey(line, lineNumber)
+
+			if err != nil {
+				return nil, err
+			} else if !strings.HasPrefix(rest, `=`) {
+				return nil, fmt.Errorf("toml: line %d: expected '=' after key", lineNumber)
+			}
+
+			rest = strings.TrimSpace(rest[1:])
+
+			// arrays may span multiple lines; keep consuming lines until the brackets are balanced
+			for strings.HasPrefix(rest, `[`) && !tomlBalanced(rest) && i+1 < len(lines) {
+				i += 1
+				rest += "\n" + stripTOMLComment(lines[i])
+			}
+
+			value, remainder, err := parseTOMLValue(rest, lineNumber)
+
+			if err != nil {
+				return nil, err
+			} else if remainder = stripTOMLComment(remainder); remainder != `` {
+				return nil, fmt.Errorf("toml: line %d: unexpected %q after value", lineNumber, remainder)
+			}
+
+			if parent, err := tomlTable(current, keys[:len(keys)-1], lineNumber); err == nil {
+				if _, exists := parent[keys[len(keys)-1]]; exists {
+					return nil, fmt.Errorf("toml: line %d: key %q is already defined", lineNumber, keys[len(keys)-1])
+				}
+
+				parent[keys[len(keys)-1]] = value
+			} else {
+				return nil, err
+			}
+		}
+	}
+
+	return root, nil
+}
+
+// retrieves (creating as necessary) the table at the given key path; arrays of tables resolve to
+// their most recently defined element
+func tomlTable(root map[string]interface{}, keys []string, lineNumber int) (map[string]interface{}, error) {
+	current := root
+
+	for _, key := range keys {
+		switch existing := current[key].(type) {
+		case nil:
+			table := make(map[string]interface{})
+			current[key] = table
+			current = table
+		case map[string]interface{}:
+			current = existing
+		case []interface{}:
+			if len(existing) > 0 {
+				if table, ok := existing[len(existing)-1].(map[string]interface{}); ok {
+					current = table
+					continue
+				}
+			}
+
+			return nil, fmt.Errorf("toml: line %d: key %q is not a table", lineNumber, key)
+		default:
+			return nil, fmt.Errorf("toml: line %d: key %q is not a table", lineNumber, key)
+		}
+	}
+
+	return current, nil
+}
+
+// parses a (possibly dotted, possibly quoted) key, returning its parts and the remaining text
+func parseTOMLKey(in string, lineNumber int) ([]string, string, error) {
+	var keys []string
+
+	in = strings.TrimLeft(in, " \t")
+
+	for {
+		var key string
+
+		switch {
+		case strings.HasPrefix(in, `"`), strings.HasPrefix(in, `'`):
+			if value, rest, err := parseTOMLString(in, lineNumber); err == nil {
+				key = value
+				in = rest
+			} else {
+				return nil, ``, err
+			}
+
+		default:
+			end := 0
+
+			for end < len(in) && tomlBareKey.MatchString(in[end:end+1]) {
+				end += 1
+			}
+
+			if end == 0 {
+				return nil, ``, fmt.Errorf("toml: line %d: invalid key", lineNumber)
+			}
+
+			key = in[:end]
+			in = in[end:]
+		}
+
+		keys = append(keys, key)
+		in = strings.TrimLeft(in, " \t")
+
+		if strings.HasPrefix(in, `.`) {
+			in = strings.TrimLeft(in[1:], " \t")
+		} else {
+			return keys, in, nil
+		}
+	}
+}
+
+func parseTOMLValue(in string, lineNumber int) (interface{}, string, error) {
+	in = strings.TrimLeft(in, " \t\n")
+
+	switch {
+	case strings.HasPrefix(in, `"""`), strings.HasPrefix(in, `'''`):
+		return nil, ``, fmt.Errorf("toml: line %d: multi-line strings are not supported", lineNumber)
+
+	case strings.HasPrefix(in, `"`), strings.HasPrefix(in, `'`):
+		return parseTOMLString(in, lineNumber)
+
+	case strings.HasPrefix(in, `[`):
+		out := make([]interface{}, 0)
+		in = skipTOMLWhitespace(in[1:])
+
+		for !strings.HasPrefix(in, `]`) {
+			if value, rest, err := parseTOMLValue(in, lineNumber); err == nil {
+				out = append(out, value)
+				in = skipTOMLWhitespace(rest)
+			} else {
+				return nil, ``, err
+			}
+
+			if strings.HasPrefix(in, `,`) {
+				in = skipTOMLWhitespace(in[1:])
+			} else if !strings.HasPrefix(in, `]`) {
+				return nil, ``, fmt.Errorf("toml: line %d: unterminated array", lineNumber)
+			}
+		}
+
+		return out, in[1:], nil
+
+	case strings.HasPrefix(in, `{`):
+		out := make(map[string]interface{})
+		in = strings.TrimLeft(in[1:], " \t")
+
+		for !strings.HasPrefix(in, `}`) {
+			keys, rest, err := parseTOMLKey(in, lineNumber)
+
+			if err != nil {
+				return nil, ``, err
+			} else if !strings.HasPrefix(rest, `=`) {
+				return nil, ``, fmt.Errorf("toml: line %d: expected '=' in inline table", lineNumber)
+			}
+
+			value, rest, err := parseTOMLValue(rest[1:], lineNumber)
+
+			if err != nil {
+				return nil, ``, err
+			}
+
+			if parent, err := tomlTable(out, keys[:len(keys)-1], lineNumber); err == nil {
+				parent[keys[len(keys)-1]] = value
+			} else {
+				return nil, ``, err
+			}
+
+			in = strings.TrimLeft(rest, " \t")
+
+			if strings.HasPrefix(in, `,`) {
+				in = strings.TrimLeft(in[1:], " \t")
+			} else if !strings.HasPrefix(in, `}`) {
+				return nil, ``, fmt.Errorf("toml: line %d: unterminated inline table", lineNumber)
+			}
+		}
+
+		return out, in[1:], nil
+
+	default:
+		end := strings.IndexAny(in, ",]}#\n")
+
+		if end < 0 {
+			end = len(in)
+		}
+
+		token := strings.TrimSpace(in[:end])
+		rest := in[end:]
+
+		switch token {
+		case `true`:
+			return true, rest, nil
+		case `false`:
+			return false, rest, nil
+		case `inf`, `+inf`:
+			return math.Inf(1), rest, nil
+		case `-inf`:
+			return math.Inf(-1), rest, nil
+		case `nan`, `+nan`, `-nan`:
+			return math.NaN(), rest, nil
+		}
+
+		clean := strings.Replace(token, `_`, ``, -1)
+
+		if tomlInteger.MatchString(clean) {
+			if i, err := strconv.ParseInt(clean, 0, 64); err == nil {
+				return i, rest, nil
+			}
+		} else if tomlFloat.MatchString(clean) {
+			if f, err := strconv.ParseFloat(clean, 64); err == nil {
+				return f, rest, nil
+			}
+		}
+
+		for _, layout := range []string{time.RFC3339Nano, `2006-01-02T15:04:05.999999999`, `2006-01-02 15:04:05Z07:00`, `2006-01-02`} {
+			if t, err := time.Parse(layout, token); err == nil {
+				return t, rest, nil
+			}
+		}
+
+		return nil, ``, fmt.Errorf("toml: line %d: invalid value %q", lineNumber, token)
+	}
+}
+
+func parseTOMLString(in string, lineNumber int) (string, string, error) {
+	if strings.HasPrefix(in, `'`) {
+		if end := strings.Index(in[1:], `'`); end >= 0 {
+			return in[1 : end+1], in[end+2:], nil
+		}
+	} else {
+		for i := 1; i < len(in); i++ {
+			if in[i] == '\\' {
+				i += 1
+			} else if in[i] == '"' {
+				if value, err := strconv.Unquote(in[:i+1]); err == nil {
+					return value, in[i+1:], nil
+				} else {
+					return ``, ``, fmt.Errorf("toml: line %d: invalid string %s: %v", lineNumber, in[:i+1], err)
+				}
+			}
+		}
+	}
+
+	return ``, ``, fmt.Errorf("toml: line %d: unterminated string", lineNumber)
+}
+
+func skipTOMLWhitespace(in string) string {
+	for {
+		in = strings.TrimLeft(in, " \t\n")
+
+		if strings.HasPrefix(in, `#`) {
+			if nl := strings.Index(in, "\n"); nl >= 0 {
+				in = in[nl:]
+				continue
+			}
+
+			return ``
+		}
+
+		return in
+	}
+}
+
+// removes a trailing comment (outside of strings) and surrounding whitespace
+func stripTOMLComment(in string) string {
+	var quote rune
+
+	for i, r := range in {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' {
+				continue
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return strings.TrimSpace(in[:i])
+		}
+	}
+
+	return strings.TrimSpace(in)
+}
+
+// whether all brackets opened in the given text (outside of strings) have been closed
+func tomlBalanced(in string) bool {
+	var quote rune
+	var depth int
+	var escaped bool
+
+	for _, r := range in {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if r == '\\' && quote == '"' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '[' || r == '{':
+			depth += 1
+		case r == ']' || r == '}':
+			depth -= 1
+		}
+	}
+
+	return depth <= 0
+}
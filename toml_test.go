@@ -0,0 +1,61 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalTOML(t *testing.T) {
+	assert := require.New(t)
+
+	type encoder struct {
+		Command  CommandName `argonaut:"encoder"`
+		Preset   string      `argonaut:"preset,default=medium"`
+		Port     int         `argonaut:"port,default=8080"`
+		Quality  float64     `argonaut:"q"`
+		Verbose  bool        `argonaut:"v"`
+		Title    string      `argonaut:"title"`
+		Tags     []string    `argonaut:"tag"`
+		Ignored  string      `argonaut:"-"`
+		Metadata map[string]interface{}
+		Codecs   []CodecOptions
+	}
+
+	output, err := MarshalTOML(&encoder{
+		Quality: 1,
+		Verbose: true,
+		Title:   "The \"Best\"\tOne",
+		Tags:    []string{`a`, `b`},
+		Ignored: `nope`,
+		Metadata: map[string]interface{}{
+			`year`:   2018,
+			`artist`: `someone`,
+		},
+		Codecs: []CodecOptions{
+			{Stream: `v`, Codec: `libx264`},
+			{Stream: `a`, Codec: `aac`},
+		},
+	})
+
+	assert.NoError(err)
+	assert.Equal(`# preset = "medium"
+# port = 8080
+q = 1.0
+v = true
+title = "The \"Best\"\tOne"
+tag = ["a", "b"]
+metadata = { artist = "someone", year = 2018 }
+
+[[codecs]]
+stream = "v"
+codec = "libx264"
+
+[[codecs]]
+stream = "a"
+codec = "aac"
+`, string(output))
+
+	_, err = MarshalTOML(`nope`)
+	assert.Error(err)
+}
@@ -0,0 +1,145 @@
+package argonaut
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Generates the arguments for the given struct as Parse does, then drops values until the length
+// of the command line (with the arguments joined by the argument delimiter) is at most maxLen
+// characters, as is needed on systems with a small ARG_MAX.  Values are dropped from the end of
+// the command: first from positional fields (one value at a time for slices), then from optional
+// flags.  Command names and required fields are never dropped.  If any values are dropped, the
+// shortened arguments are returned along with a *TruncatedError naming the affected fields.  An
+// error is returned without any arguments if the command cannot be shortened enough.  If maxLen is
+// zero or less, the system's limit (as returned by ArgMax) is used.
+func Truncate(v interface{}, maxLen int) ([]string, error) {
+	if maxLen <= 0 {
+		maxLen = ArgMax()
+	}
+
+	structT, err := structTypeOf(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := newConfig()
+	args, _, err := generateCommand(cfg, v, true, false)
+
+	if err != nil {
+		return nil, err
+	} else if fitsIn(cfg, args, maxLen) {
+		return args, nil
+	}
+
+	structV := reflect.Indirect(reflect.ValueOf(v))
+	copyV := reflect.New(structT)
+	copyV.Elem().Set(structV)
+	cloneNestedStructs(copyV.Elem())
+
+	candidates, err := truncationCandidates(cfg, structT)
+
+	if err != nil {
+		return nil, err
+	}
+
+	truncated := &TruncatedError{
+		MaxLength: maxLen,
+	}
+
+	for _, candidate := range candidates {
+		fieldV, ok := fieldValueByPath(copyV.Elem(), candidate.path)
+
+		if !ok || fieldV.IsZero() {
+			continue
+		}
+
+		truncated.Fields = append(truncated.Fields, candidate.name)
+
+		if fieldV.Kind() == reflect.Slice && !isLeafType(fieldV.Type()) {
+			// find the largest number of values that can be kept
+			all := fieldV.Slice(0, fieldV.Len())
+			var parseErr error
+
+			keep := sort.Search(all.Len(), func(n int) bool {
+				fieldV.Set(all.Slice(0, n+1))
+
+				if args, _, err := generateCommand(cfg, copyV.Interface(), true, false); err == nil {
+					return !fitsIn(cfg, args, maxLen)
+				} else {
+					parseErr = err
+					return true
+				}
+			})
+
+			if parseErr != nil {
+				return nil, parseErr
+			}
+
+			if keep == 0 {
+				fieldV.Set(reflect.Zero(fieldV.Type()))
+			} else {
+				fieldV.Set(all.Slice(0, keep))
+			}
+		} else {
+			fieldV.Set(reflect.Zero(fieldV.Type()))
+		}
+
+		if args, _, err := generateCommand(cfg, copyV.Interface(), true, false); err != nil {
+			return nil, err
+		} else if fitsIn(cfg, args, maxLen) {
+			return args, truncated
+		}
+	}
+
+	return nil, fmt.Errorf("Cannot shorten the command to %d characters", maxLen)
+}
+
+type truncationCandidate struct {
+	name string
+	path []int
+}
+
+// returns the fields that Truncate may drop values from, in the order they should be dropped:
+// positional fields from last to first, followed by optional flags from last to first
+func truncationCandidates(cfg *Config, structT reflect.Type) ([]truncationCandidate, error) {
+	var positionals []truncationCandidate
+	var flags []truncationCandidate
+
+	err := walkFields(cfg, structT, nil, func(path []int, field reflect.StructField, tag *argonautTag) error {
+		fieldT := derefType(field.Type)
+
+		if _, ok := structSliceElem(fieldT); ok {
+			return nil
+		}
+
+		switch {
+		case fieldT == commandNameType, fieldT == argNameType, fieldT == extraArgsType:
+			return nil
+		case tag.Required, tag.SuffixPrevious, tag.SkipName:
+			return nil
+		}
+
+		candidate := truncationCandidate{
+			name: fieldPathName(structT, path),
+			path: path,
+		}
+
+		if tag.Positional {
+			positionals = append([]truncationCandidate{candidate}, positionals...)
+		} else {
+			flags = append([]truncationCandidate{candidate}, flags...)
+		}
+
+		return nil
+	})
+
+	return append(positionals, flags...), err
+}
+
+func fitsIn(cfg *Config, args []string, maxLen int) bool {
+	return len(strings.Join(args, cfg.ArgumentDelimiter)) <= maxLen
+}
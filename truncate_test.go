@@ -0,0 +1,73 @@
+package argonaut
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncate(t *testing.T) {
+	assert := require.New(t)
+
+	type rsync struct {
+		Command CommandName `argonaut:"rsync"`
+		Archive bool        `argonaut:"a"`
+		Exclude []string    `argonaut:"exclude,long"`
+		Sources []string    `argonaut:",positional"`
+		Dest    string      `argonaut:",positional,required"`
+	}
+
+	input := &rsync{
+		Archive: true,
+		Exclude: []string{`*.tmp`, `*.log`},
+		Sources: []string{`/data/one`, `/data/two`, `/data/three`},
+		Dest:    `host:/backup`,
+	}
+
+	full := MustParse(input)
+
+	// commands that already fit are returned as-is
+	args, err := Truncate(input, 1000)
+	assert.NoError(err)
+	assert.Equal(full, args)
+
+	// without a limit, the system's ARG_MAX is used
+	args, err = Truncate(input, 0)
+	assert.NoError(err)
+	assert.Equal(full, args)
+
+	// positional values are dropped first, one at a time
+	args, err = Truncate(input, len(strings.Join(full, ` `))-1)
+	assert.Equal([]string{
+		`rsync`, `-a`, `--exclude`, `*.tmp`, `--exclude`, `*.log`, `/data/one`, `/data/two`, `host:/backup`,
+	}, args)
+
+	assert.IsType(&TruncatedError{}, err)
+	assert.Equal([]string{`Sources`}, err.(*TruncatedError).Fields)
+
+	// then optional flags, once the positionals are exhausted
+	args, err = Truncate(input, 40)
+	assert.Equal([]string{`rsync`, `-a`, `--exclude`, `*.tmp`, `host:/backup`}, args)
+	assert.Equal(&TruncatedError{
+		MaxLength: 40,
+		Fields:    []string{`Sources`, `Exclude`},
+	}, err)
+
+	assert.EqualError(err, `command truncated to 40 characters, dropped values of: Sources, Exclude`)
+
+	// the original value is not modified
+	assert.Len(input.Sources, 3)
+	assert.Len(input.Exclude, 2)
+
+	// required fields and command names are never dropped
+	args, err = Truncate(input, 10)
+	assert.Nil(args)
+	assert.Error(err)
+
+	_, ok := err.(*TruncatedError)
+	assert.False(ok)
+
+	_, err = Truncate(`nope`, 10)
+	assert.Error(err)
+}
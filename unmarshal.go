@@ -0,0 +1,622 @@
+package argonaut
+
+import (
+	"encoding"
+	"fmt"
+	"os/exec"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ghetzel/go-stockutil/stringutil"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+type unmarshalField struct {
+	Name  string
+	Path  []int
+	Type  reflect.Type
+	Tag   argonautTag
+	Names []string
+}
+
+// returns true if the field is a bare boolean flag
+func (self *unmarshalField) IsBool() bool {
+	return self.Type.Kind() == reflect.Bool
+}
+
+// returns true if this field can be specified multiple times
+func (self *unmarshalField) IsSlice() bool {
+	return self.Type.Kind() == reflect.Slice && !isLeafType(self.Type)
+}
+
+// attempts to match the given flag token against this field, returning whether it matched, the
+// value that was joined to the flag name (if any), and whether such a value was present
+func (self *unmarshalField) Match(token string) (bool, string, bool) {
+	body := strings.TrimPrefix(strings.TrimPrefix(token, `-`), `-`)
+
+	for _, name := range self.Names {
+		if body == name {
+			return true, ``, false
+		}
+	}
+
+	for _, name := range self.Names {
+		for _, joiner := range []string{self.Tag.Joiner, `=`} {
+			if joiner == `` {
+				continue
+			}
+
+			if strings.HasPrefix(body, name+joiner) {
+				return true, strings.TrimPrefix(body, name+joiner), true
+			}
+		}
+	}
+
+	return false, ``, false
+}
+
+// attempts to match the given flag token against this field as a composite flag, whose value
+// immediately follows its (single-character) name in the same token (e.g.: "-Dfoo=bar")
+func (self *unmarshalField) MatchComposite(token string) (bool, string) {
+	if !self.Tag.Composite || strings.HasPrefix(token, `--`) {
+		return false, ``
+	}
+
+	body := strings.TrimPrefix(token, `-`)
+
+	for _, name := range self.Names {
+		if len(name) == 1 && len(body) > 1 && strings.HasPrefix(body, name) {
+			return true, body[1:]
+		}
+	}
+
+	return false, ``
+}
+
+type unmarshalIndex struct {
+	Command    *unmarshalField
+	Flags      []*unmarshalField
+	Positional []*unmarshalField
+	Abbreviate bool
+	Strict     bool
+}
+
+// finds the field that the given flag token refers to, along with any value joined to the flag.
+// Composite flags are only considered if no field matches the token by name, so that a composite
+// "-D" flag does not capture a "-Debug" flag.
+func (self *unmarshalIndex) matchFlag(token string) (*unmarshalField, string, bool) {
+	for _, field := range self.Flags {
+		if ok, value, hasValue := field.Match(token); ok {
+			return field, value, hasValue
+		}
+	}
+
+	for _, field := range self.Flags {
+		if ok, value := field.MatchComposite(token); ok {
+			return field, value, true
+		}
+	}
+
+	return nil, ``, false
+}
+
+// sets the given field from a string; in strict mode, values that are not a literal of the field's
+// type are rejected with a *TypeMismatchError rather than being coerced
+func (self *unmarshalIndex) setValue(target reflect.Value, value string, fieldName string) error {
+	if self.Strict {
+		if err := checkStrictType(target.Type(), value, fieldName); err != nil {
+			return err
+		}
+	}
+
+	return setFieldValue(target, value)
+}
+
+// if the given flag token does not match any flag exactly, but is an unambiguous prefix of exactly
+// one flag's name (e.g.: "--verb" for "--verbose"), returns the token with that name in its place
+func (self *unmarshalIndex) expandAbbreviation(token string) (string, error) {
+	for _, field := range self.Flags {
+		if ok, _, _ := field.Match(token); ok {
+			return token, nil
+		}
+	}
+
+	body := strings.TrimLeft(token, `-`)
+	prefix := token[:len(token)-len(body)]
+	abbrev, value := body, ``
+
+	if i := strings.Index(body, `=`); i >= 0 {
+		abbrev, value = body[:i], body[i:]
+	}
+
+	if abbrev == `` {
+		return token, nil
+	}
+
+	var expanded string
+	var candidates []string
+
+	for _, field := range self.Flags {
+		for _, name := range field.Names {
+			if len(name) > len(abbrev) && strings.HasPrefix(name, abbrev) {
+				expanded = name
+				candidates = append(candidates, prefix+name)
+				break
+			}
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return token, nil
+	case 1:
+		return prefix + expanded + value, nil
+	default:
+		return ``, fmt.Errorf("ambiguous flag, could be any of: %s", strings.Join(candidates, `, `))
+	}
+}
+
+// Populates the struct pointed to by v from the given slice of command line arguments.  The first
+// argument is always treated as the command name (as is the case with the output of Parse).  Flags
+// are matched against the names (and aliases) declared in each field's argonaut tag; flags that do
+// not correspond to any field are ignored.  Arguments that are not flags (or that follow a "--"
+// argument) are assigned to positional fields in declaration order.  If a flag for a non-slice
+// field is given more than once, the first occurrence is used unless the field's tag specifies the
+// "last_wins" option.  If the AbbreviateFlags option
+// is enabled, flags may also be given as any unambiguous prefix of their name.
+func Unmarshal(args []string, v interface{}, opts ...Option) error {
+	return unmarshalCommand(newConfig(opts...), args, v, nil)
+}
+
+// The reverse of Command: populates a new struct of type T from the arguments of the given command
+// (e.g.: UnwrapCmd[Encoder](cmd)), as Unmarshal does.  The first of the command's arguments is
+// treated as the command name.
+func UnwrapCmd[T any](cmd *exec.Cmd, opts ...Option) (*T, error) {
+	if cmd == nil {
+		return nil, fmt.Errorf("cannot unwrap a nil command")
+	}
+
+	out := new(T)
+
+	if err := Unmarshal(cmd.Args, out, opts...); err == nil {
+		return out, nil
+	} else {
+		return nil, err
+	}
+}
+
+// Behaves like Unmarshal, except that flags which do not correspond to any field are not ignored.
+// All arguments are processed, after which an *UnknownFlagsError listing every unrecognized flag
+// is returned.
+func UnmarshalStrict(args []string, v interface{}, opts ...Option) error {
+	var unknown []string
+
+	if err := unmarshalCommand(newConfig(opts...), args, v, &unknown); err != nil {
+		return err
+	}
+
+	if len(unknown) > 0 {
+		return &UnknownFlagsError{
+			Flags: unknown,
+		}
+	}
+
+	return nil
+}
+
+// Applies a differential update to the struct pointed to by v.  Starting from the current values
+// of v, all fields whose flag names (with or without leading dashes) appear in removed are reset
+// to their zero values.  The arguments in added (which should not include a command name) are then
+// unmarshaled on top of the result.
+func ParseDiff(removed []string, added []string, v interface{}) error {
+	vV, index, err := prepareUnmarshal(newConfig(), v)
+
+	if err != nil {
+		return err
+	}
+
+	fields := append(append([]*unmarshalField{}, index.Flags...), index.Positional...)
+
+	for _, name := range removed {
+		if !strings.HasPrefix(name, `-`) {
+			name = `--` + name
+		}
+
+		for _, field := range fields {
+			if ok, _, _ := field.Match(name); ok {
+				if target, ok := fieldValueByPath(vV, field.Path); ok {
+					target.Set(reflect.Zero(target.Type()))
+				}
+			}
+		}
+	}
+
+	return unmarshalArgs(added, vV, index, false, nil)
+}
+
+// populates the struct pointed to by v from the given command line (including the command name)
+// using the given Config, as Unmarshal does; flags that do not match any field are appended to
+// unknown if it is non-nil
+func unmarshalCommand(cfg *Config, args []string, v interface{}, unknown *[]string) error {
+	if vV, index, err := prepareUnmarshal(cfg, v); err == nil {
+		if cfg.StrictTags {
+			if err := checkStructTags(cfg, vV.Type()); err != nil {
+				return err
+			}
+		}
+
+		return unmarshalArgs(args, vV, index, true, unknown)
+	} else {
+		return err
+	}
+}
+
+// validates that v points to a struct, and builds the index of its fields that arguments are matched
+// against using the given Config
+func prepareUnmarshal(cfg *Config, v interface{}) (reflect.Value, *unmarshalIndex, error) {
+	vV := reflect.ValueOf(v)
+
+	if vV.Kind() != reflect.Ptr || vV.IsNil() || vV.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, nil, fmt.Errorf("pointer to struct needed, got %T", v)
+	}
+
+	index := &unmarshalIndex{
+		Abbreviate: cfg.AbbreviateFlags,
+		Strict:     cfg.StrictTypes,
+	}
+
+	if err := buildUnmarshalIndex(cfg, index, vV.Elem().Type()); err != nil {
+		return reflect.Value{}, nil, err
+	}
+
+	return vV.Elem(), index, nil
+}
+
+// populates structV from the given arguments; if withCommand is true, the first argument is
+// treated as the command name.  If unknown is non-nil, flags that do not match any field are
+// appended to it.
+func unmarshalArgs(args []string, structV reflect.Value, index *unmarshalIndex, withCommand bool, unknown *[]string) error {
+	start := 0
+
+	if withCommand {
+		if len(args) == 0 {
+			return nil
+		}
+
+		start = 1
+	}
+
+	// only populate the CommandName field if the command differs from what it would default to
+	if cmd := index.Command; cmd != nil && withCommand {
+		if primary := primaryName(cmd); args[0] != primary && args[0] != cmd.Tag.Label {
+			if err := setFieldValue(fieldByPath(structV, cmd.Path), args[0]); err != nil {
+				return newUnmarshalError(Cursor{0, args[0], cmd.Name}, err)
+			}
+		}
+	}
+
+	var positional []Cursor
+	seen := make(map[*unmarshalField]bool)
+
+	for i := start; i < len(args); i++ {
+		token := args[i]
+
+		if token == `--` {
+			for j := i + 1; j < len(args); j++ {
+				positional = append(positional, Cursor{Position: j, Token: args[j]})
+			}
+
+			break
+		} else if len(token) < 2 || !strings.HasPrefix(token, `-`) {
+			positional = append(positional, Cursor{Position: i, Token: token})
+			continue
+		}
+
+		if index.Abbreviate {
+			if expanded, err := index.expandAbbreviation(token); err == nil {
+				token = expanded
+			} else {
+				return newUnmarshalError(Cursor{Position: i, Token: token}, err)
+			}
+		}
+
+		field, value, hasValue := index.matchFlag(token)
+
+		if field != nil {
+			cursor := Cursor{i, token, field.Name}
+
+			if nargs := field.Tag.NArgs; nargs != nil && !field.IsBool() {
+				// NArgs: consume as many of the following arguments as the flag accepts
+				target := fieldByPath(structV, field.Path)
+				consumed := 0
+
+				if hasValue {
+					if err := index.setValue(target, value, field.Name); err != nil {
+						return newUnmarshalError(cursor, err)
+					}
+
+					consumed += 1
+				}
+
+				for nargs.More(consumed) && i+1 < len(args) && isNArgValue(args[i+1]) {
+					i += 1
+
+					if err := index.setValue(target, args[i], field.Name); err != nil {
+						return newUnmarshalError(Cursor{i, args[i], field.Name}, err)
+					}
+
+					consumed += 1
+				}
+
+				if err := nargs.Check(consumed); err != nil {
+					return newUnmarshalError(cursor, err)
+				}
+
+				continue
+			}
+
+			if field.IsBool() {
+				if !hasValue {
+					value = `true`
+				}
+			} else if !hasValue {
+				if i+1 < len(args) {
+					i += 1
+					value = args[i]
+				} else {
+					return newUnmarshalError(cursor, fmt.Errorf("flag requires a value"))
+				}
+			}
+
+			// flags that can only hold one value keep the first occurrence, unless the field
+			// specifies the "last_wins" option
+			if !field.IsSlice() {
+				if seen[field] && !field.Tag.LastWins {
+					continue
+				}
+
+				seen[field] = true
+			}
+
+			if err := index.setValue(fieldByPath(structV, field.Path), value, field.Name); err != nil {
+				return newUnmarshalError(cursor, err)
+			}
+		} else if unknown != nil {
+			*unknown = append(*unknown, token)
+		}
+	}
+
+	for _, field := range index.Positional {
+		if len(positional) == 0 {
+			break
+		}
+
+		target := fieldByPath(structV, field.Path)
+
+		if field.IsSlice() {
+			for _, cursor := range positional {
+				if err := index.setValue(target, cursor.Token, field.Name); err != nil {
+					cursor.FieldName = field.Name
+					return newUnmarshalError(cursor, err)
+				}
+			}
+
+			positional = nil
+		} else {
+			cursor := positional[0]
+
+			if err := index.setValue(target, cursor.Token, field.Name); err != nil {
+				cursor.FieldName = field.Name
+				return newUnmarshalError(cursor, err)
+			}
+
+			positional = positional[1:]
+		}
+	}
+
+	return nil
+}
+
+func buildUnmarshalIndex(cfg *Config, index *unmarshalIndex, structT reflect.Type) error {
+	return walkFields(cfg, structT, nil, func(path []int, structField reflect.StructField, tag *argonautTag) error {
+		field := &unmarshalField{
+			Name: structField.Name,
+			Path: path,
+			Type: structField.Type,
+			Tag:  *tag,
+		}
+
+		if len(tag.Options) > 0 && tag.Options[0] != `` {
+			field.Names = append(field.Names, tag.Options...)
+		} else {
+			field.Names = append(field.Names, cfg.fmtCommandWord(structField.Name))
+		}
+
+		field.Names = append(field.Names, tag.Aliases...)
+		fieldT := derefType(structField.Type)
+
+		if _, ok := structSliceElem(fieldT); ok {
+			// repeated groups of flags cannot be recovered
+			return nil
+		}
+
+		switch {
+		case fieldT == commandNameType:
+			if len(path) == 1 && index.Command == nil {
+				index.Command = field
+			}
+
+		case fieldT == argNameType, tag.SuffixPrevious, tag.SkipName:
+			// these fields modify other arguments and cannot be recovered on their own
+			return nil
+
+		case isMapType(fieldT), fieldT == optionSetType, fieldT == extraArgsType:
+			return nil
+
+		case tag.Positional:
+			index.Positional = append(index.Positional, field)
+
+		default:
+			index.Flags = append(index.Flags, field)
+		}
+
+		return nil
+	})
+}
+
+func primaryName(field *unmarshalField) string {
+	if len(field.Names) > 0 {
+		return field.Names[0]
+	}
+
+	return ``
+}
+
+// types that are populated from a single string rather than being exploded into their constituent
+// parts
+func isLeafType(t reflect.Type) bool {
+	if isRegisteredType(t) {
+		return true
+	} else if t.Implements(textUnmarshalerType) || reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+
+	return false
+}
+
+// retrieves the field at the given index path, allocating any nil pointers along the way
+func fieldByPath(structV reflect.Value, path []int) reflect.Value {
+	current := structV
+
+	for _, i := range path {
+		for current.Kind() == reflect.Ptr {
+			if current.IsNil() {
+				current.Set(reflect.New(current.Type().Elem()))
+			}
+
+			current = current.Elem()
+		}
+
+		current = current.Field(i)
+	}
+
+	return current
+}
+
+// checks that the given string is a literal of the (scalar) type t, without any of the coercions
+// performed by setFieldValue (e.g.: "1.5" or "yes" for an integer or boolean field)
+func checkStrictType(t reflect.Type, value string, fieldName string) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if _, ok := builtinParsers[t]; ok || isLeafType(t) {
+		return nil
+	} else if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		return checkStrictType(t.Elem(), value, fieldName)
+	}
+
+	var err error
+
+	switch t.Kind() {
+	case reflect.Bool:
+		_, err = strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err = strconv.ParseInt(value, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err = strconv.ParseUint(value, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		_, err = strconv.ParseFloat(value, t.Bits())
+	}
+
+	if err != nil {
+		return &TypeMismatchError{
+			Field:        fieldName,
+			ExpectedKind: t.Kind(),
+			GotValue:     value,
+		}
+	}
+
+	return nil
+}
+
+// sets the given value from a string, converting it to the target type as necessary.  Slices have
+// the converted value appended to them.
+func setFieldValue(target reflect.Value, value string) error {
+	if target.Kind() == reflect.Ptr {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+
+		return setFieldValue(target.Elem(), value)
+	}
+
+	if parser, ok := builtinParsers[target.Type()]; ok {
+		if parsed, err := parser(value); err == nil {
+			target.Set(reflect.ValueOf(parsed))
+			return nil
+		} else {
+			return err
+		}
+	}
+
+	if target.CanAddr() {
+		if unmarshaler, ok := target.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return unmarshaler.UnmarshalText([]byte(value))
+		}
+	}
+
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(value)
+
+	case reflect.Bool:
+		if b, err := stringutil.ConvertToBool(value); err == nil {
+			target.SetBool(b)
+		} else {
+			return err
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := stringutil.ConvertToInteger(value); err == nil {
+			target.SetInt(i)
+		} else {
+			return err
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if i, err := stringutil.ConvertToInteger(value); err == nil && i >= 0 {
+			target.SetUint(uint64(i))
+		} else if err == nil {
+			return fmt.Errorf("cannot assign negative value %q to %v", value, target.Type())
+		} else {
+			return err
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if f, err := stringutil.ConvertToFloat(value); err == nil {
+			target.SetFloat(f)
+		} else {
+			return err
+		}
+
+	case reflect.Slice:
+		elem := reflect.New(target.Type().Elem()).Elem()
+
+		if err := setFieldValue(elem, value); err != nil {
+			return err
+		}
+
+		target.Set(reflect.Append(target, elem))
+
+	case reflect.Interface:
+		target.Set(reflect.ValueOf(value))
+
+	default:
+		return fmt.Errorf("cannot assign %q to %v", value, target.Type())
+	}
+
+	return nil
+}
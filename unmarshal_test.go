@@ -0,0 +1,373 @@
+package argonaut
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshal(t *testing.T) {
+	assert := require.New(t)
+
+	input := &ls{
+		All:           true,
+		LongFormat:    true,
+		HumanReadable: true,
+		BlockSize:     1024,
+		CoolStuff:     `yep`,
+		Paths: []string{
+			`/foo`,
+			`/bar/*.txt`,
+			`/baz/`,
+		},
+	}
+
+	output := &ls{}
+
+	assert.NoError(Unmarshal(MustParse(input), output))
+	assert.Equal(input, output)
+
+	output = &ls{}
+	assert.NoError(Unmarshal([]string{`ls`, `-a`, `--unknown`, `-h`, `--`, `-dash`}, output))
+	assert.Equal(&ls{
+		All:           true,
+		HumanReadable: true,
+		Paths:         []string{`-dash`},
+	}, output)
+
+	assert.Error(Unmarshal([]string{`ls`}, ls{}))
+	assert.Error(Unmarshal([]string{`ls`, `--block-size`}, &ls{}))
+}
+
+func TestUnmarshalOptions(t *testing.T) {
+	assert := require.New(t)
+
+	type archive struct {
+		Command    CommandName `argonaut:"archive"`
+		BlockSize  int
+		KeepLinks  bool
+		OutputName string   `argonaut:",long"`
+		Files      []string `argonaut:",positional"`
+	}
+
+	input := &archive{
+		BlockSize:  512,
+		KeepLinks:  true,
+		OutputName: `out.tar`,
+		Files:      []string{`a`, `b`},
+	}
+
+	opts := []Option{WithSeparator(`_`), WithKeyValueJoiner(`=`)}
+
+	// names and joiners given as options are used when unmarshaling, as they are when marshaling
+	cmd, err := Command(input, opts...)
+	assert.NoError(err)
+	assert.Equal([]string{`archive`, `--block_size=512`, `--keep_links`, `--output_name=out.tar`, `a`, `b`}, cmd.Args)
+
+	output, err := UnwrapCmd[archive](cmd, opts...)
+	assert.NoError(err)
+	assert.Equal(input, output)
+
+	output = &archive{}
+	assert.NoError(Unmarshal(cmd.Args, output))
+	assert.NotEqual(input, output)
+}
+
+func TestUnmarshalRecursiveType(t *testing.T) {
+	assert := require.New(t)
+
+	type node struct {
+		Command CommandName `argonaut:"node"`
+		Name    string      `argonaut:"name,long"`
+		Child   *node
+	}
+
+	output := &node{}
+
+	// fields of the recursive type cannot be recovered, but the rest of the struct is
+	assert.NoError(Unmarshal([]string{`node`, `--name`, `root`}, output))
+	assert.Equal(&node{Name: `root`}, output)
+}
+
+func TestUnwrapCmd(t *testing.T) {
+	assert := require.New(t)
+
+	input := &ls{
+		All:       true,
+		BlockSize: 512,
+		Paths:     []string{`/foo`},
+	}
+
+	cmd, err := Command(input)
+	assert.NoError(err)
+
+	output, err := UnwrapCmd[ls](cmd)
+	assert.NoError(err)
+	assert.Equal(input, output)
+
+	_, err = UnwrapCmd[ls](nil)
+	assert.Error(err)
+
+	_, err = UnwrapCmd[ls](exec.Command(`ls`, `--block-size`))
+	assert.Error(err)
+}
+
+func TestUnmarshalStrict(t *testing.T) {
+	assert := require.New(t)
+
+	output := &ls{}
+	assert.NoError(UnmarshalStrict([]string{`ls`, `-a`, `-h`, `/foo`}, output))
+	assert.Equal(&ls{
+		All:           true,
+		HumanReadable: true,
+		Paths:         []string{`/foo`},
+	}, output)
+
+	output = &ls{}
+	err := UnmarshalStrict([]string{`ls`, `-a`, `--unknown`, `-h`, `-x=1`, `--`, `--not-a-flag`}, output)
+	assert.Error(err)
+	assert.IsType(&UnknownFlagsError{}, err)
+	assert.Equal([]string{`--unknown`, `-x=1`}, err.(*UnknownFlagsError).Flags)
+	assert.Equal(`unrecognized flags: --unknown, -x=1`, err.Error())
+
+	// all recognized arguments are still processed
+	assert.Equal(&ls{
+		All:           true,
+		HumanReadable: true,
+		Paths:         []string{`--not-a-flag`},
+	}, output)
+
+	// errors processing known flags take precedence
+	err = UnmarshalStrict([]string{`ls`, `--unknown`, `--block-size`}, &ls{})
+	assert.IsType(&UnmarshalError{}, err)
+}
+
+func TestUnmarshalAlias(t *testing.T) {
+	assert := require.New(t)
+
+	type prompt struct {
+		Command CommandName `argonaut:"prompt"`
+		No      bool        `argonaut:"no,long,alias=n|nope"`
+		Name    string      `argonaut:"name,long,alias=username"`
+	}
+
+	output := &prompt{}
+	assert.NoError(Unmarshal([]string{`prompt`, `-n`, `--username`, `bob`}, output))
+	assert.Equal(&prompt{No: true, Name: `bob`}, output)
+
+	output = &prompt{}
+	assert.NoError(Unmarshal([]string{`prompt`, `--nope`}, output))
+	assert.True(output.No)
+
+	// aliases are never used when marshaling
+	assert.Equal([]string{`prompt`, `--no`, `--name`, `bob`}, MustParse(&prompt{No: true, Name: `bob`}))
+
+	output = &prompt{}
+	assert.NoError(Unmarshal(MustParse(&prompt{No: true, Name: `bob`}), output))
+	assert.Equal(&prompt{No: true, Name: `bob`}, output)
+}
+
+func TestUnmarshalErrorCursor(t *testing.T) {
+	assert := require.New(t)
+
+	err := Unmarshal([]string{`ls`, `-a`, `--block-size`, `huge`}, &ls{})
+	assert.Error(err)
+
+	uerr, ok := err.(*UnmarshalError)
+	assert.True(ok)
+	assert.Equal(Cursor{
+		Position:  2,
+		Token:     `--block-size`,
+		FieldName: `BlockSize`,
+	}, uerr.Cursor)
+
+	type counter struct {
+		Command CommandName `argonaut:"count"`
+		To      int         `argonaut:",positional"`
+	}
+
+	err = Unmarshal([]string{`count`, `--`, `ten`}, &counter{})
+	uerr, ok = err.(*UnmarshalError)
+	assert.True(ok)
+	assert.Equal(Cursor{
+		Position:  2,
+		Token:     `ten`,
+		FieldName: `To`,
+	}, uerr.Cursor)
+
+	assert.Contains(err.Error(), `argument 2 ("ten"), field To:`)
+}
+
+func TestParseDiff(t *testing.T) {
+	assert := require.New(t)
+
+	cmd := &ls{
+		All:        true,
+		LongFormat: true,
+		BlockSize:  1024,
+		Paths:      []string{`/foo`},
+	}
+
+	assert.NoError(ParseDiff(
+		[]string{`--all`, `block-size`, `paths`},
+		[]string{`-h`, `--block-size=512`, `/bar`},
+		cmd,
+	))
+
+	assert.Equal(&ls{
+		LongFormat:    true,
+		HumanReadable: true,
+		BlockSize:     512,
+		Paths:         []string{`/bar`},
+	}, cmd)
+
+	assert.Error(ParseDiff(nil, nil, ls{}))
+}
+
+func TestUnmarshalAbbreviateFlags(t *testing.T) {
+	assert := require.New(t)
+
+	type ls struct {
+		Command   CommandName `argonaut:"ls"`
+		Verbose   bool        `argonaut:"verbose,long"`
+		Version   bool        `argonaut:"version,long"`
+		BlockSize int         `argonaut:"block-size,long"`
+		Color     string      `argonaut:"color,long"`
+		Paths     []string    `argonaut:",positional"`
+	}
+
+	var out ls
+
+	assert.NoError(Unmarshal([]string{`ls`, `--verb`, `--block`, `4`, `--col=auto`, `/tmp`}, &out, WithAbbreviateFlags(true)))
+	assert.Equal(ls{
+		Verbose:   true,
+		BlockSize: 4,
+		Color:     `auto`,
+		Paths:     []string{`/tmp`},
+	}, out)
+
+	// ambiguous prefixes are an error
+	err := Unmarshal([]string{`ls`, `--ver`}, &ls{}, WithAbbreviateFlags(true))
+	assert.Error(err)
+
+	uerr, ok := err.(*UnmarshalError)
+	assert.True(ok)
+	assert.Equal(1, uerr.Cursor.Position)
+	assert.Contains(err.Error(), `--verbose, --version`)
+
+	// without the option, abbreviations are not recognized
+	out = ls{}
+	assert.NoError(Unmarshal([]string{`ls`, `--verb`, `/tmp`}, &out))
+	assert.False(out.Verbose)
+
+	err = UnmarshalStrict([]string{`ls`, `--verb`, `--nope`}, &ls{}, WithAbbreviateFlags(true))
+	assert.Equal(&UnknownFlagsError{Flags: []string{`--nope`}}, err)
+}
+
+func TestUnmarshalStrictTypes(t *testing.T) {
+	assert := require.New(t)
+
+	type resize struct {
+		Command CommandName `argonaut:"resize"`
+		Width   int         `argonaut:"width,long"`
+		Scale   float64     `argonaut:"scale,long"`
+		Keep    bool        `argonaut:"keep,long"`
+		Sizes   []uint8     `argonaut:"size,long"`
+		Name    string      `argonaut:"name,long"`
+	}
+
+	var out resize
+
+	assert.NoError(Unmarshal([]string{
+		`resize`, `--width`, `640`, `--scale`, `1.5`, `--keep=false`, `--size`, `8`, `--size`, `16`, `--name`, `1.5`,
+	}, &out, WithStrictTypes(true)))
+
+	assert.Equal(resize{
+		Width: 640,
+		Scale: 1.5,
+		Sizes: []uint8{8, 16},
+		Name:  `1.5`,
+	}, out)
+
+	for _, args := range [][]string{
+		{`resize`, `--width`, `1.5`},
+		{`resize`, `--width`, `wide`},
+		{`resize`, `--keep=yes`},
+		{`resize`, `--size`, `300`},
+		{`resize`, `--scale`, `big`},
+	} {
+		err := Unmarshal(args, &resize{}, WithStrictTypes(true))
+		assert.Error(err, args)
+
+		uerr, ok := err.(*UnmarshalError)
+		assert.True(ok, args)
+
+		mismatch, ok := uerr.Err.(*TypeMismatchError)
+		assert.True(ok, args)
+		assert.Equal(args[len(args)-1][strings.Index(args[len(args)-1], `=`)+1:], mismatch.GotValue)
+	}
+
+	err := Unmarshal([]string{`resize`, `--width`, `1.5`}, &resize{}, WithStrictTypes(true))
+	assert.EqualError(err, `argument 1 ("--width"), field Width: "1.5" is not a valid int value`)
+}
+
+func TestUnmarshalLastWins(t *testing.T) {
+	assert := require.New(t)
+
+	type transcode struct {
+		Command CommandName `argonaut:"transcode"`
+		Output  string      `argonaut:"o"`
+		Format  string      `argonaut:"f,last_wins"`
+		Quiet   bool        `argonaut:"q"`
+		Maps    []string    `argonaut:"map,long"`
+	}
+
+	var out transcode
+
+	assert.NoError(Unmarshal([]string{
+		`transcode`, `-o`, `out1.mp4`, `-f`, `mp4`, `-o`, `out2.mp4`, `-f`, `mkv`, `-q`, `-q=false`, `--map`, `0`, `--map`, `1`,
+	}, &out))
+
+	assert.Equal(transcode{
+		Output: `out1.mp4`,
+		Format: `mkv`,
+		Quiet:  true,
+		Maps:   []string{`0`, `1`},
+	}, out)
+
+	// marshaling is unaffected
+	assert.Equal([]string{`transcode`, `-o`, `out1.mp4`, `-f`, `mkv`, `-q`, `--map`, `0`, `--map`, `1`}, MustParse(&out))
+}
+
+func TestComposite(t *testing.T) {
+	assert := require.New(t)
+
+	type java struct {
+		Command    CommandName `argonaut:"java"`
+		Properties []string    `argonaut:"D,composite"`
+		MaxHeap    string      `argonaut:"X,composite"`
+		Debug      bool        `argonaut:"Debug"`
+		Class      string      `argonaut:",positional"`
+	}
+
+	input := &java{
+		Properties: []string{`foo=bar`, `log.level=debug`},
+		MaxHeap:    `mx512m`,
+		Debug:      true,
+		Class:      `Main`,
+	}
+
+	args := MustParse(input)
+	assert.Equal([]string{`java`, `-Dfoo=bar`, `-Dlog.level=debug`, `-Xmx512m`, `-Debug`, `Main`}, args)
+
+	var out java
+	assert.NoError(Unmarshal(args, &out))
+	assert.Equal(input, &out)
+
+	// the flag may still be given as a separate argument
+	out = java{}
+	assert.NoError(Unmarshal([]string{`java`, `-D`, `foo=bar`, `Main`}, &out))
+	assert.Equal(java{Properties: []string{`foo=bar`}, Class: `Main`}, out)
+}
@@ -0,0 +1,678 @@
+package argonaut
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ghetzel/go-stockutil/stringutil"
+	"github.com/ghetzel/go-stockutil/typeutil"
+)
+
+// the options recognized by parseTag, used to suggest corrections for unknown options
+var tagOptionNames = []string{
+	`alias`,
+	`append`,
+	`autopath`,
+	`choices`,
+	`clamp`,
+	`clean_env`,
+	`complex`,
+	`composite`,
+	`default`,
+	`delimiters`,
+	`deprecated`,
+	`emit_if`,
+	`emit_unless`,
+	`emit_zero`,
+	`help`,
+	`joiner`,
+	`keyjoiner`,
+	`label`,
+	`last_wins`,
+	`long`,
+	`lowercase`,
+	`max`,
+	`min`,
+	`nargs`,
+	`no_expand`,
+	`noomitempty`,
+	`pad_char`,
+	`pad_left`,
+	`pad_right`,
+	`omitempty`,
+	`positional`,
+	`positional_safe`,
+	`repeated_struct_no_cmd`,
+	`required`,
+	`required_group`,
+	`short`,
+	`skip_empty`,
+	`skipname`,
+	`stdin`,
+	`suffixprev`,
+	`timefmt`,
+	`trim`,
+	`value`,
+	`when`,
+	`wrap`,
+}
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (self Severity) String() string {
+	switch self {
+	case SeverityError:
+		return `error`
+	case SeverityWarning:
+		return `warning`
+	default:
+		return fmt.Sprintf("Severity(%d)", int(self))
+	}
+}
+
+// Identifies the kind of problem a Diagnostic describes.
+type DiagnosticCode string
+
+const (
+	// the argonaut tag could not be parsed
+	DiagnosticTagParse DiagnosticCode = `tag_parse`
+
+	// the argonaut tag contains an option that is not recognized
+	DiagnosticUnknownOption DiagnosticCode = `unknown_option`
+
+	// a tag option is not valid for the type of the field, or a tag value cannot be converted to
+	// the type of the field
+	DiagnosticTypeMismatch DiagnosticCode = `type_mismatch`
+
+	// a required field (or all fields in a required group) has a zero value
+	DiagnosticRequired DiagnosticCode = `required`
+
+	// the value of a field is not one of the values given in its "choices" tag option
+	DiagnosticInvalidChoice DiagnosticCode = `invalid_choice`
+
+	// the value of a field is outside the range given by its "min" and "max" tag options
+	DiagnosticOutOfRange DiagnosticCode = `out_of_range`
+
+	// a deprecated field has a non-zero value
+	DiagnosticDeprecated DiagnosticCode = `deprecated`
+
+	// the "emit_if" and "emit_unless" options of a group of fields refer to each other in a cycle
+	DiagnosticCircularCondition DiagnosticCode = `circular_condition`
+)
+
+// Describes a single problem found by Validate.
+type Diagnostic struct {
+	Severity   Severity
+	Code       DiagnosticCode
+	Field      string
+	Message    string
+	Suggestion string
+}
+
+func (self Diagnostic) String() string {
+	out := fmt.Sprintf("%v: field %s: %s", self.Severity, self.Field, self.Message)
+
+	if self.Suggestion != `` {
+		out += ` (` + self.Suggestion + `)`
+	}
+
+	return out
+}
+
+// The problems found by Validate, separated by severity.
+type ValidationResult struct {
+	Errors   []Diagnostic
+	Warnings []Diagnostic
+}
+
+// Returns true if no errors were found (warnings are permitted).
+func (self ValidationResult) OK() bool {
+	return len(self.Errors) == 0
+}
+
+func (self *ValidationResult) add(diag Diagnostic) {
+	if diag.Severity == SeverityWarning {
+		self.Warnings = append(self.Warnings, diag)
+	} else {
+		self.Errors = append(self.Errors, diag)
+	}
+}
+
+// Checks the given struct for problems without generating a command line, reporting every problem
+// found instead of stopping at the first one.  The argonaut tags of all fields are checked for
+// syntax errors, unrecognized options, options that do not apply to the field's type, and
+// "emit_if" and "emit_unless" options that refer to each other in a cycle.  If v
+// is a struct (or non-nil pointer to one), the field values are also checked against the
+// "required", "required_group", "choices", "min", "max", and "deprecated" tag options.
+func Validate(v interface{}) ValidationResult {
+	var result ValidationResult
+
+	structT, err := structTypeOf(v)
+
+	if err != nil {
+		result.add(Diagnostic{
+			Severity: SeverityError,
+			Code:     DiagnosticTypeMismatch,
+			Message:  err.Error(),
+		})
+
+		return result
+	}
+
+	validateStruct(newConfig(), &result, nil, structT, reflect.Indirect(reflect.ValueOf(v)))
+
+	return result
+}
+
+// validates the fields of the given struct type; if structV is invalid (e.g.: a nil pointer), only
+// the tags are validated.  enclosing holds the struct types being validated by enclosing calls, whose
+// tags are not validated again for fields that hold no value (e.g.: a nil "Child *Node" of Node).
+func validateStruct(cfg *Config, result *ValidationResult, enclosing []reflect.Type, structT reflect.Type, structV reflect.Value) {
+	enclosing = append(enclosing[:len(enclosing):len(enclosing)], structT)
+	defaults := cfg.defaultTag()
+	groups := make([]string, 0)
+	groupFields := make(map[string][]string)
+	groupSatisfied := make(map[string]bool)
+	emitConditions := make(map[string][]string)
+	peers := make(map[string]interface{})
+
+	if structV.IsValid() {
+		for i := 0; i < structT.NumField(); i++ {
+			if structT.Field(i).PkgPath == `` {
+				peers[structT.Field(i).Name] = structV.Field(i).Interface()
+			}
+		}
+	}
+
+	for i := 0; i < structT.NumField(); i++ {
+		field := structT.Field(i)
+		rawTag := field.Tag.Get(`argonaut`)
+
+		if field.PkgPath != `` || rawTag == `-` {
+			continue
+		}
+
+		tag, err := parseTag(rawTag, &defaults)
+
+		if err != nil {
+			result.add(Diagnostic{
+				Severity: SeverityError,
+				Code:     DiagnosticTagParse,
+				Field:    field.Name,
+				Message:  err.Error(),
+			})
+
+			continue
+		}
+
+		var fieldV reflect.Value
+
+		if structV.IsValid() {
+			fieldV = reflect.Indirect(structV.Field(i))
+		}
+
+		fieldT := derefType(field.Type)
+
+		if fieldT == commandNameType {
+			defaults.Delimiters = tag.Delimiters
+			defaults.Joiner = tag.Joiner
+			defaults.KeyPartJoiner = tag.KeyPartJoiner
+		}
+
+		validateTag(result, field.Name, fieldT, &tag)
+
+		for _, peer := range []string{tag.EmitIf, tag.EmitUnless} {
+			if peer != `` {
+				emitConditions[field.Name] = append(emitConditions[field.Name], peer)
+			}
+		}
+
+		// descend into nested structs and slices of structs
+		if fieldT.Kind() == reflect.Struct && !isLeafType(fieldT) && fieldT != optionSetType {
+			if fieldV.IsValid() || !refersToEnclosing(enclosing, fieldT) {
+				validateStruct(cfg, result, enclosing, fieldT, fieldV)
+			}
+
+			continue
+		} else if elemT, ok := structSliceElem(fieldT); ok {
+			if fieldV.IsValid() && fieldV.Len() > 0 {
+				for j := 0; j < fieldV.Len(); j++ {
+					validateStruct(cfg, result, enclosing, elemT, reflect.Indirect(fieldV.Index(j)))
+				}
+			} else if !refersToEnclosing(enclosing, elemT) {
+				validateStruct(cfg, result, enclosing, elemT, reflect.Value{})
+			}
+
+			continue
+		}
+
+		if !structV.IsValid() {
+			continue
+		}
+
+		// fields that will not be emitted are not checked (problems with the conditions themselves
+		// are reported by validateEmitConditions)
+		if ok, err := tag.emitConditionMet(peers); err != nil || !ok {
+			continue
+		}
+
+		if group := tag.RequiredGroup; group != `` {
+			if _, ok := groupFields[group]; !ok {
+				groups = append(groups, group)
+			}
+
+			groupFields[group] = append(groupFields[group], field.Name)
+
+			if fieldV.IsValid() && !fieldV.IsZero() {
+				groupSatisfied[group] = true
+			}
+		}
+
+		validateValue(result, field.Name, fieldV, &tag)
+	}
+
+	validateEmitConditions(result, structT, emitConditions)
+
+	for _, group := range groups {
+		if !groupSatisfied[group] {
+			result.add(Diagnostic{
+				Severity:   SeverityError,
+				Code:       DiagnosticRequired,
+				Field:      strings.Join(groupFields[group], `, `),
+				Message:    fmt.Sprintf("at least one of the fields in required group %q must be given", group),
+				Suggestion: fmt.Sprintf("set one of: %s", strings.Join(groupFields[group], `, `)),
+			})
+		}
+	}
+}
+
+// checks that the fields named by "emit_if" and "emit_unless" options are boolean peers, and that
+// they do not refer to each other in a cycle (e.g.: A emits if B, and B emits if A)
+func validateEmitConditions(result *ValidationResult, structT reflect.Type, conditions map[string][]string) {
+	for i := 0; i < structT.NumField(); i++ {
+		name := structT.Field(i).Name
+
+		for _, peer := range conditions[name] {
+			if peerField, ok := structT.FieldByName(peer); !ok || len(peerField.Index) != 1 {
+				result.add(Diagnostic{
+					Severity: SeverityError,
+					Code:     DiagnosticTypeMismatch,
+					Field:    name,
+					Message:  fmt.Sprintf("emit condition refers to unknown field %q", peer),
+				})
+			} else if derefType(peerField.Type).Kind() != reflect.Bool {
+				result.add(Diagnostic{
+					Severity: SeverityError,
+					Code:     DiagnosticTypeMismatch,
+					Field:    name,
+					Message:  fmt.Sprintf("emit condition refers to non-boolean field %q", peer),
+				})
+			}
+		}
+	}
+
+	// each cycle is reported once, by the first of its fields (in declaration order)
+	reported := make(map[string]bool)
+
+	for i := 0; i < structT.NumField(); i++ {
+		name := structT.Field(i).Name
+
+		if reported[name] {
+			continue
+		}
+
+		if cycle := emitConditionCycle(conditions, name, nil); len(cycle) > 0 {
+			for _, member := range cycle {
+				reported[member] = true
+			}
+
+			result.add(Diagnostic{
+				Severity:   SeverityError,
+				Code:       DiagnosticCircularCondition,
+				Field:      name,
+				Message:    fmt.Sprintf("emit conditions form a cycle: %s", strings.Join(append(cycle, name), ` -> `)),
+				Suggestion: `remove the emit_if or emit_unless option from one of these fields`,
+			})
+		}
+	}
+}
+
+// returns the path of fields leading from the field that starts the given path back to it, if the
+// emit conditions of those fields form a cycle
+func emitConditionCycle(conditions map[string][]string, name string, path []string) []string {
+	if len(path) > 0 && name == path[0] {
+		return path
+	}
+
+	for _, visited := range path {
+		if visited == name {
+			return nil
+		}
+	}
+
+	path = append(path, name)
+
+	for _, peer := range conditions[name] {
+		if cycle := emitConditionCycle(conditions, peer, path); len(cycle) > 0 {
+			return cycle
+		}
+	}
+
+	return nil
+}
+
+func validateTag(result *ValidationResult, fieldName string, fieldT reflect.Type, tag *argonautTag) {
+	for _, unknown := range tag.UnknownOptions {
+		diag := Diagnostic{
+			Severity: SeverityWarning,
+			Code:     DiagnosticUnknownOption,
+			Field:    fieldName,
+			Message:  fmt.Sprintf("unknown argonaut tag option %q", unknown),
+		}
+
+		if suggestion := closestString(unknown, tagOptionNames); suggestion != `` {
+			diag.Suggestion = fmt.Sprintf("did you mean %q?", suggestion)
+		}
+
+		result.add(diag)
+	}
+
+	mismatch := func(format string, args ...interface{}) {
+		result.add(Diagnostic{
+			Severity: SeverityError,
+			Code:     DiagnosticTypeMismatch,
+			Field:    fieldName,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	if tag.AutoPath && fieldT != commandNameType {
+		mismatch("the %q option is only valid on CommandName fields, not %v", `autopath`, fieldT)
+	}
+
+	if tag.NoExpand && fieldT != commandNameType {
+		mismatch("the %q option is only valid on CommandName fields, not %v", `no_expand`, fieldT)
+	}
+
+	if tag.CleanEnv && fieldT != envType {
+		mismatch("the %q option is only valid on Env fields, not %v", `clean_env`, fieldT)
+	}
+
+	if k := fieldT.Kind(); tag.Complex && k != reflect.Complex64 && k != reflect.Complex128 {
+		mismatch("the %q option is only valid on complex64 and complex128 fields, not %v", `complex`, fieldT)
+	}
+
+	if tag.SkipEmpty && fieldT.Kind() != reflect.String {
+		mismatch("the %q option is only valid on string fields, not %v", `skip_empty`, fieldT)
+	}
+
+	if tag.TimeFormat != `` && fieldT != timeType {
+		mismatch("the %q option is only valid on time.Time fields, not %v", `timefmt`, fieldT)
+	}
+
+	if tag.ArgValue != `` && fieldT != argNameType {
+		mismatch("the %q option is only valid on ArgName fields, not %v", `value`, fieldT)
+	}
+
+	if tag.Append && fieldT != redirectType {
+		mismatch("the %q option is only valid on Redirect fields, not %v", `append`, fieldT)
+	}
+
+	elemT := fieldT
+
+	if elemT.Kind() == reflect.Slice || elemT.Kind() == reflect.Array {
+		elemT = derefType(elemT.Elem())
+	}
+
+	if (tag.Min != nil || tag.Max != nil) && !isNumericKind(elemT.Kind()) {
+		mismatch("the %q and %q options are only valid on numeric fields, not %v", `min`, `max`, fieldT)
+	}
+
+	if tag.Clamp && tag.Min == nil && tag.Max == nil {
+		mismatch("the %q option requires a %q or %q option", `clamp`, `min`, `max`)
+	}
+
+	if tag.Trim && elemT.Kind() != reflect.String {
+		mismatch("the %q option is only valid on string fields, not %v", `trim`, fieldT)
+	}
+
+	if tag.Lowercase && elemT.Kind() != reflect.String {
+		mismatch("the %q option is only valid on string fields, not %v", `lowercase`, fieldT)
+	}
+
+	if k := elemT.Kind(); (tag.PadLeft > 0 || tag.PadRight > 0) && k != reflect.String && !isNumericKind(k) {
+		mismatch("the %q and %q options are only valid on string and numeric fields, not %v", `pad_left`, `pad_right`, fieldT)
+	}
+
+	if tag.PadChar != 0 && tag.PadLeft == 0 && tag.PadRight == 0 {
+		mismatch("the %q option requires a %q or %q option", `pad_char`, `pad_left`, `pad_right`)
+	}
+
+	if tag.Default != `` && !convertibleTo(tag.Default, fieldT) {
+		mismatch("default value %q cannot be converted to %v", tag.Default, fieldT)
+	}
+
+	for _, choice := range tag.Choices {
+		if !convertibleTo(choice, fieldT) {
+			mismatch("choice %q cannot be converted to %v", choice, fieldT)
+		}
+	}
+}
+
+func validateValue(result *ValidationResult, fieldName string, fieldV reflect.Value, tag *argonautTag) {
+	isZero := !fieldV.IsValid() || fieldV.IsZero()
+
+	if tag.Required && isZero {
+		result.add(Diagnostic{
+			Severity:   SeverityError,
+			Code:       DiagnosticRequired,
+			Field:      fieldName,
+			Message:    `a value is required`,
+			Suggestion: suggestChoiceOrDefault(tag),
+		})
+	}
+
+	if isZero {
+		return
+	}
+
+	value := fieldV.Interface()
+
+	if tag.Deprecated != `` {
+		result.add(Diagnostic{
+			Severity: SeverityWarning,
+			Code:     DiagnosticDeprecated,
+			Field:    fieldName,
+			Message:  fmt.Sprintf("field is deprecated: %s", tag.Deprecated),
+		})
+	}
+
+	if len(tag.Choices) > 0 && fieldV.Kind() != reflect.Bool {
+		for _, item := range valuesOf(fieldV) {
+			str := stringutil.MustString(item)
+			valid := false
+
+			for _, choice := range tag.Choices {
+				if choiceMatches(choice, str) {
+					valid = true
+					break
+				}
+			}
+
+			if !valid {
+				diag := Diagnostic{
+					Severity:   SeverityError,
+					Code:       DiagnosticInvalidChoice,
+					Field:      fieldName,
+					Message:    fmt.Sprintf("%q is not one of the allowed values: %s", str, strings.Join(tag.Choices, `, `)),
+					Suggestion: suggestChoiceOrDefault(tag),
+				}
+
+				if closest := closestString(str, tag.Choices); closest != `` {
+					diag.Suggestion = fmt.Sprintf("did you mean %q?", closest)
+				}
+
+				result.add(diag)
+			}
+		}
+	}
+
+	if tag.Min != nil || tag.Max != nil {
+		for _, item := range valuesOf(fieldV) {
+			if n, err := stringutil.ConvertToFloat(item); err == nil {
+				if (tag.Min != nil && n < *tag.Min) || (tag.Max != nil && n > *tag.Max) {
+					result.add(Diagnostic{
+						Severity:   SeverityError,
+						Code:       DiagnosticOutOfRange,
+						Field:      fieldName,
+						Message:    fmt.Sprintf("%v is outside of the allowed range %s", value, describeRange(tag)),
+						Suggestion: fmt.Sprintf("use a value in the range %s", describeRange(tag)),
+					})
+				}
+			}
+		}
+	}
+}
+
+// returns the individual values of a field (the elements of slices, or the value itself)
+func valuesOf(fieldV reflect.Value) []interface{} {
+	if (fieldV.Kind() == reflect.Slice || fieldV.Kind() == reflect.Array) && !isLeafType(fieldV.Type()) {
+		values := make([]interface{}, fieldV.Len())
+
+		for i := 0; i < fieldV.Len(); i++ {
+			values[i] = fieldV.Index(i).Interface()
+		}
+
+		return values
+	}
+
+	return []interface{}{fieldV.Interface()}
+}
+
+// whether the given value is the same as the choice, either as a string or as a number
+func choiceMatches(choice string, value string) bool {
+	if choice == value {
+		return true
+	} else if typeutil.IsNumeric(choice) && typeutil.IsNumeric(value) {
+		return typeutil.V(choice).Float() == typeutil.V(value).Float()
+	}
+
+	return false
+}
+
+func suggestChoiceOrDefault(tag *argonautTag) string {
+	if len(tag.Choices) > 0 {
+		return fmt.Sprintf("use one of: %s", strings.Join(tag.Choices, `, `))
+	} else if tag.Default != `` {
+		return fmt.Sprintf("the default value is %q", tag.Default)
+	}
+
+	return ``
+}
+
+func describeRange(tag *argonautTag) string {
+	min := `-inf`
+	max := `+inf`
+
+	if tag.Min != nil {
+		min = fmt.Sprintf("%v", *tag.Min)
+	}
+
+	if tag.Max != nil {
+		max = fmt.Sprintf("%v", *tag.Max)
+	}
+
+	return `[` + min + `, ` + max + `]`
+}
+
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// whether the given string could be assigned to a value of the given type
+func convertibleTo(value string, t reflect.Type) bool {
+	if t.Kind() == reflect.Slice && !isLeafType(t) {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Map, reflect.Struct, reflect.Slice, reflect.Array, reflect.Func, reflect.Chan:
+		if !isLeafType(derefType(t)) {
+			return true
+		}
+	}
+
+	return setFieldValue(reflect.New(t).Elem(), value) == nil
+}
+
+// returns the candidate closest to the given input, if any is reasonably close
+func closestString(input string, candidates []string) string {
+	best := ``
+	bestDistance := -1
+
+	for _, candidate := range candidates {
+		if d := editDistance(input, candidate); bestDistance < 0 || d < bestDistance {
+			best = candidate
+			bestDistance = d
+		}
+	}
+
+	if bestDistance >= 0 && bestDistance <= (len(input)+2)/3 {
+		return best
+	}
+
+	return ``
+}
+
+// the Levenshtein distance between two strings
+func editDistance(a string, b string) int {
+	ar := []rune(a)
+	br := []rune(b)
+	previous := make([]int, len(br)+1)
+	current := make([]int, len(br)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(br)]
+}
+
+func minInt(first int, rest ...int) int {
+	for _, n := range rest {
+		if n < first {
+			first = n
+		}
+	}
+
+	return first
+}
@@ -0,0 +1,132 @@
+package argonaut
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	assert := require.New(t)
+
+	type encoder struct {
+		Command  CommandName `argonaut:"encoder"`
+		Preset   string      `argonaut:"preset,choices=fast|medium|slow,default=medium"`
+		Threads  int         `argonaut:"threads,min=1,max=64"`
+		Levels   []int       `argonaut:"level,choices=1|2|3"`
+		Input    string      `argonaut:"i,required"`
+		Old      string      `argonaut:"old,deprecated=use -new instead"`
+		Typo     bool        `argonaut:"typo,requied=yes"`
+		Name     string      `argonaut:"name,autopath,min=2"`
+		Count    int         `argonaut:"count,default=many"`
+		URL      string      `argonaut:"url,required_group=source"`
+		File     string      `argonaut:"file,required_group=source"`
+		Codecs   []CodecOptions
+		Filename string `argonaut:",positional"`
+	}
+
+	result := Validate(&encoder{
+		Preset:  `fsat`,
+		Threads: 128,
+		Levels:  []int{1, 4},
+		Old:     `yes`,
+	})
+
+	assert.False(result.OK())
+
+	codes := make(map[DiagnosticCode][]string)
+
+	for _, diag := range append(result.Errors, result.Warnings...) {
+		codes[diag.Code] = append(codes[diag.Code], diag.Field)
+	}
+
+	assert.Equal(map[DiagnosticCode][]string{
+		DiagnosticInvalidChoice: {`Preset`, `Levels`},
+		DiagnosticOutOfRange:    {`Threads`},
+		DiagnosticRequired:      {`Input`, `URL, File`},
+		DiagnosticTypeMismatch:  {`Name`, `Name`, `Count`},
+		DiagnosticDeprecated:    {`Old`},
+		DiagnosticUnknownOption: {`Typo`},
+	}, codes)
+
+	assert.Equal(Diagnostic{
+		Severity:   SeverityError,
+		Code:       DiagnosticInvalidChoice,
+		Field:      `Preset`,
+		Message:    `"fsat" is not one of the allowed values: fast, medium, slow`,
+		Suggestion: `did you mean "fast"?`,
+	}, result.Errors[0])
+
+	assert.Len(result.Warnings, 2)
+	assert.Equal(`warning: field Typo: unknown argonaut tag option "requied" (did you mean "required"?)`, result.Warnings[1].String())
+	assert.Equal(DiagnosticDeprecated, result.Warnings[0].Code)
+
+	result = Validate(&encoder{
+		Preset:  `slow`,
+		Threads: 8,
+		Levels:  []int{3},
+		Input:   `in.mp4`,
+		File:    `list.txt`,
+	})
+
+	assert.Len(result.Errors, 3)
+	assert.Equal(DiagnosticTypeMismatch, result.Errors[0].Code)
+
+	type broken struct {
+		Min     int `argonaut:"min,min=abc"`
+		Max     int `argonaut:"max,max"`
+		Working int `argonaut:"working,required"`
+	}
+
+	result = Validate(broken{})
+	assert.Len(result.Errors, 3)
+	assert.Equal(DiagnosticTagParse, result.Errors[0].Code)
+	assert.Equal(`Min`, result.Errors[0].Field)
+	assert.Equal(DiagnosticTagParse, result.Errors[1].Code)
+	assert.Equal(`Max`, result.Errors[1].Field)
+	assert.Equal(DiagnosticRequired, result.Errors[2].Code)
+
+	// type-only validation only checks tags
+	result = Validate((*encoder)(nil))
+	assert.Len(result.Errors, 3)
+	assert.Len(result.Warnings, 1)
+
+	assert.True(Validate(&FFMPEG{}).OK())
+	assert.False(Validate(`nope`).OK())
+}
+
+func TestValidateRecursiveType(t *testing.T) {
+	assert := require.New(t)
+
+	type limited struct {
+		Command CommandName `argonaut:"limited"`
+		Level   int         `argonaut:"level,max=3"`
+		Child   *limited
+		Peers   []limited
+	}
+
+	assert.True(Validate(&limited{}).OK())
+	assert.True(Validate((*limited)(nil)).OK())
+
+	// values held by fields of the recursive type are still checked
+	result := Validate(&limited{
+		Level: 1,
+		Child: &limited{Level: 5},
+		Peers: []limited{{Level: 2}, {Level: 4}},
+	})
+
+	assert.Len(result.Errors, 2)
+	assert.Equal(DiagnosticOutOfRange, result.Errors[0].Code)
+	assert.Equal(DiagnosticOutOfRange, result.Errors[1].Code)
+}
+
+func TestEditDistance(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal(0, editDistance(`required`, `required`))
+	assert.Equal(1, editDistance(`requied`, `required`))
+	assert.Equal(3, editDistance(`kitten`, `sitting`))
+	assert.Equal(4, editDistance(``, `four`))
+	assert.Equal(`long`, closestString(`lnog`, tagOptionNames))
+	assert.Equal(``, closestString(`banana`, tagOptionNames))
+}
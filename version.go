@@ -0,0 +1,67 @@
+package argonaut
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// The version of argonaut in use.  This can be set at build time (e.g.:
+// `go build -ldflags "-X github.com/ghetzel/argonaut.Version=1.2.3"`); if it is not, Runtime
+// reports the module version recorded in the binary's build information instead.
+var Version string
+
+// The date the binary was built, which can be set at build time in the same way as Version.
+var BuildDate string
+
+// Describes the version of argonaut in use and the environment it was built for.
+type RuntimeInfo struct {
+	Version   string
+	GoVersion string
+	BuildDate string
+	GOOS      string
+	GOARCH    string
+}
+
+// Returns information about the version of argonaut in use and the environment it was built for,
+// which is helpful when diagnosing compatibility problems.
+func Runtime() RuntimeInfo {
+	info := RuntimeInfo{
+		Version:   Version,
+		GoVersion: runtime.Version(),
+		BuildDate: BuildDate,
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+	}
+
+	if info.Version == `` {
+		info.Version = moduleVersion()
+	}
+
+	if info.Version == `` {
+		info.Version = `(devel)`
+	}
+
+	return info
+}
+
+// returns the version of this module as recorded in the build information of the running binary,
+// or "(devel)" if it is unavailable (e.g.: when running the package's own tests)
+func moduleVersion() string {
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if build.Main.Path == `github.com/ghetzel/argonaut` {
+			return build.Main.Version
+		}
+
+		for _, dep := range build.Deps {
+			if dep.Path == `github.com/ghetzel/argonaut` {
+				if dep.Replace != nil {
+					return dep.Replace.Version
+				}
+
+				return dep.Version
+			}
+		}
+	}
+
+	return `(devel)`
+}
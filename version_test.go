@@ -0,0 +1,30 @@
+package argonaut
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntime(t *testing.T) {
+	assert := require.New(t)
+
+	info := Runtime()
+	assert.NotEmpty(info.Version)
+	assert.Equal(runtime.Version(), info.GoVersion)
+	assert.Equal(runtime.GOOS, info.GOOS)
+	assert.Equal(runtime.GOARCH, info.GOARCH)
+
+	defer func(version string, date string) {
+		Version = version
+		BuildDate = date
+	}(Version, BuildDate)
+
+	Version = `1.2.3`
+	BuildDate = `2024-01-02`
+
+	info = Runtime()
+	assert.Equal(`1.2.3`, info.Version)
+	assert.Equal(`2024-01-02`, info.BuildDate)
+}
@@ -0,0 +1,162 @@
+package argonaut
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+
+	"github.com/ghetzel/go-stockutil/stringutil"
+	"github.com/ghetzel/go-stockutil/typeutil"
+)
+
+// an element of the document produced by MarshalXML: a Flag (with an optional value), or an
+// Argument if there is no name
+type xmlElement struct {
+	name     string
+	hasValue bool
+	value    string
+}
+
+// Serializes the given struct as an XML document describing the command it generates, for tools
+// that accept XML-based invocations (e.g.: MSBuild or Ant tasks).  The document has this schema:
+//
+//	<Command name="gcc">                the command name, as it would be generated by Parse
+//	  <Flag name="o" value="a.out"/>    an option and its value (one per value of a slice)
+//	  <Flag name="Wall"/>               a boolean option (or an ArgName without a value)
+//	  <Argument value="main.c"/>        a positional argument
+//	</Command>
+//
+// Flag names do not include their leading dashes, and elements appear in field declaration order
+// (without any whitespace between them).  Zero-valued fields are omitted, as are maps, slices of
+// structs, OptionSets, ExtraArgs, and fields that modify other arguments (e.g.: "suffixprev" and
+// "skipname").  The fields of nested structs are included in the same Command element.
+func MarshalXML(v interface{}) ([]byte, error) {
+	structT, err := structTypeOf(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := newConfig()
+	structV := reflect.ValueOf(v)
+	elements := make([]xmlElement, 0)
+
+	if err := walkFields(cfg, structT, nil, func(path []int, field reflect.StructField, tag *argonautTag) error {
+		fieldT := derefType(field.Type)
+		fieldV, ok := fieldValueByPath(structV, path)
+
+		if !ok || !reflect.Indirect(fieldV).IsValid() {
+			return nil
+		}
+
+		// interfaces are handled according to the type of the value they hold
+		if fieldV.Kind() == reflect.Interface {
+			if fieldV.IsNil() {
+				return nil
+			}
+
+			fieldV = fieldV.Elem()
+			fieldT = derefType(fieldV.Type())
+		}
+
+		if _, ok := structSliceElem(fieldT); ok {
+			return nil
+		}
+
+		switch {
+		case fieldT == commandNameType, fieldT == optionSetType, fieldT == extraArgsType, isMapType(fieldT):
+			return nil
+		case tag.SuffixPrevious, tag.SkipName:
+			return nil
+		case fieldV.IsZero() && (!tag.EmitZero || fieldT.Kind() == reflect.Bool):
+			return nil
+		}
+
+		if fieldT == argNameType {
+			elements = append(elements, xmlElement{
+				name:     cfg.argNameLabel(tag, field.Name),
+				hasValue: tag.ArgValue != ``,
+				value:    tag.ArgValue,
+			})
+
+			return nil
+		}
+
+		values := []interface{}{fieldV.Interface()}
+
+		if k := fieldT.Kind(); (k == reflect.Slice || k == reflect.Array) && !isLeafType(fieldT) {
+			values = nil
+			elems := reflect.Indirect(fieldV)
+
+			for i := 0; i < elems.Len(); i++ {
+				values = append(values, elems.Index(i).Interface())
+			}
+		}
+
+		for _, value := range values {
+			str, err := xmlValue(value, tag)
+
+			if err != nil {
+				return err
+			}
+
+			if tag.Positional {
+				elements = append(elements, xmlElement{
+					hasValue: true,
+					value:    str,
+				})
+			} else {
+				elements = append(elements, xmlElement{
+					name:     cfg.primaryOption(tag, field.Name),
+					hasValue: fieldT.Kind() != reflect.Bool,
+					value:    str,
+				})
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString(`<Command name="` + xmlEscape(helpCommandName(cfg, v, structT)) + `">`)
+
+	for _, element := range elements {
+		if element.name == `` {
+			buf.WriteString(`<Argument`)
+		} else {
+			buf.WriteString(`<Flag name="` + xmlEscape(element.name) + `"`)
+		}
+
+		if element.hasValue {
+			buf.WriteString(` value="` + xmlEscape(element.value) + `"`)
+		}
+
+		buf.WriteString(`/>`)
+	}
+
+	buf.WriteString(`</Command>`)
+
+	return buf.Bytes(), nil
+}
+
+// escapes a string for use in an XML attribute value
+func xmlEscape(in string) string {
+	var buf bytes.Buffer
+
+	xml.EscapeText(&buf, []byte(in))
+	return buf.String()
+}
+
+// formats a single value as it would appear on the command line
+func xmlValue(value interface{}, tag *argonautTag) (string, error) {
+	if fn, resolved, ok := registeredSerializer(value); ok {
+		return fn(resolved)
+	} else if str, _, ok := formatTime(value, tag.TimeFormat); ok {
+		return str, nil
+	}
+
+	return stringutil.MustString(typeutil.ResolveValue(value)), nil
+}
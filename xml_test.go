@@ -0,0 +1,58 @@
+package argonaut
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalXML(t *testing.T) {
+	assert := require.New(t)
+
+	type gcc struct {
+		Command  CommandName `argonaut:"gcc"`
+		Output   string      `argonaut:"o"`
+		Warnings bool        `argonaut:"Wall"`
+		Debug    bool        `argonaut:"g"`
+		Defines  []string    `argonaut:"D"`
+		Std      ArgName     `argonaut:"std,joiner=[=],value=c99"`
+		Sources  []string    `argonaut:",positional"`
+	}
+
+	output, err := MarshalXML(&gcc{
+		Output:   `a.out`,
+		Warnings: true,
+		Defines:  []string{`DEBUG`, `NAME="x" & <y>`},
+		Std:      `std`,
+		Sources:  []string{`main.c`, `util.c`},
+	})
+
+	assert.NoError(err)
+	assert.Equal(`<Command name="gcc">`+
+		`<Flag name="o" value="a.out"/>`+
+		`<Flag name="Wall"/>`+
+		`<Flag name="D" value="DEBUG"/>`+
+		`<Flag name="D" value="NAME=&#34;x&#34; &amp; &lt;y&gt;"/>`+
+		`<Flag name="std" value="c99"/>`+
+		`<Argument value="main.c"/>`+
+		`<Argument value="util.c"/>`+
+		`</Command>`, string(output))
+
+	// the output is well-formed
+	var doc struct {
+		Name  string `xml:"name,attr"`
+		Flags []struct {
+			Name  string `xml:"name,attr"`
+			Value string `xml:"value,attr"`
+		} `xml:"Flag"`
+	}
+
+	assert.NoError(xml.Unmarshal(output, &doc))
+	assert.Equal(`gcc`, doc.Name)
+	assert.Len(doc.Flags, 5)
+	assert.Equal(`NAME="x" & <y>`, doc.Flags[3].Value)
+
+	_, err = MarshalXML(`nope`)
+	assert.Error(err)
+}
@@ -0,0 +1,609 @@
+package argonaut
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var yamlInteger = regexp.MustCompile(`^[-+]?[0-9]+$`)
+var yamlFloat = regexp.MustCompile(`^[-+]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?$`)
+
+type yamlLine struct {
+	Number int
+	Indent int
+	Text   string
+}
+
+// parses a subset of YAML: block mappings and sequences, single-line flow mappings and sequences,
+// comments, and plain, single-quoted, or double-quoted scalars
+func parseYAML(doc string) (interface{}, error) {
+	var lines []yamlLine
+
+	for i, line := range strings.Split(strings.Replace(doc, "\r\n", "\n", -1), "\n") {
+		text := strings.TrimRight(stripYAMLComment(line), " \t")
+		trimmed := strings.TrimLeft(text, ` `)
+
+		if trimmed == `` || trimmed == `---` || trimmed == `...` {
+			continue
+		} else if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("yaml: line %d: tabs are not allowed for indentation", i+1)
+		}
+
+		lines = append(lines, yamlLine{
+			Number: i + 1,
+			Indent: len(text) - len(trimmed),
+			Text:   trimmed,
+		})
+	}
+
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	value, next, err := parseYAMLBlock(lines, 0, lines[0].Indent)
+
+	if err != nil {
+		return nil, err
+	} else if next < len(lines) {
+		return nil, fmt.Errorf("yaml: line %d: unexpected content", lines[next].Number)
+	}
+
+	return value, nil
+}
+
+func parseYAMLBlock(lines []yamlLine, i int, indent int) (interface{}, int, error) {
+	if isYAMLSequenceItem(lines[i].Text) {
+		return parseYAMLSequence(lines, i, indent)
+	} else {
+		return parseYAMLMapping(lines, i, indent)
+	}
+}
+
+func parseYAMLSequence(lines []yamlLine, i int, indent int) (interface{}, int, error) {
+	out := make([]interface{}, 0)
+
+	for i < len(lines) && lines[i].Indent == indent && isYAMLSequenceItem(lines[i].Text) {
+		line := lines[i]
+		rest := strings.TrimLeft(strings.TrimPrefix(line.Text, `-`), ` `)
+
+		switch {
+		case rest == ``:
+			// the item is a nested block on the following lines
+			if i+1 < len(lines) && lines[i+1].Indent > indent {
+				if value, next, err := parseYAMLBlock(lines, i+1, lines[i+1].Indent); err == nil {
+					out = append(out, value)
+					i = next
+				} else {
+					return nil, i, err
+				}
+			} else {
+				out = append(out, nil)
+				i += 1
+			}
+
+		case isYAMLSequenceItem(rest) || yamlKeySplit(rest) >= 0:
+			// the item is a nested block that starts on the same line; rewrite it as if it were on
+			// a line of its own and parse from there
+			nested := make([]yamlLine, 0, len(lines))
+			nested = append(nested, lines[:i]...)
+			nested = append(nested, yamlLine{
+				Number: line.Number,
+				Indent: indent + (len(line.Text) - len(rest)),
+				Text:   rest,
+			})
+			nested = append(nested, lines[i+1:]...)
+
+			if value, next, err := parseYAMLBlock(nested, i, nested[i].Indent); err == nil {
+				out = append(out, value)
+				i = next
+			} else {
+				return nil, i, err
+			}
+
+		default:
+			if value, err := parseYAMLFlow(rest, line.Number); err == nil {
+				out = append(out, value)
+				i += 1
+			} else {
+				return nil, i, err
+			}
+		}
+	}
+
+	return out, i, nil
+}
+
+func parseYAMLMapping(lines []yamlLine, i int, indent int) (interface{}, int, error) {
+	out := make(map[string]interface{})
+
+	for i < len(lines) && lines[i].Indent == indent && !isYAMLSequenceItem(lines[i].Text) {
+		line := lines[i]
+		split := yamlKeySplit(line.Text)
+
+		if split < 0 {
+			return nil, i, fmt.Errorf("yaml: line %d: expected a mapping key", line.Number)
+		}
+
+		key, err := parseYAMLKey(line.Text[:split], line.Number)
+
+		if err != nil {
+			return nil, i, err
+		}
+
+		rest := strings.TrimSpace(line.Text[split+1:])
+		i += 1
+
+		if rest == `` {
+			if i < len(lines) && (lines[i].Indent > indent || (lines[i].Indent == indent && isYAMLSequenceItem(lines[i].Text))) {
+				if value, next, err := parseYAMLBlock(lines, i, lines[i].Indent); err == nil {
+					out[key] = value
+					i = next
+				} else {
+					return nil, i, err
+				}
+			} else {
+				out[key] = nil
+			}
+		} else if rest == `|` || rest == `>` || strings.HasPrefix(rest, `|`) || strings.HasPrefix(rest, `>`) {
+			return nil, i, fmt.Errorf("yaml: line %d: block scalars are not supported", line.Number)
+		} else if value, err := parseYAMLFlow(rest, line.Number); err == nil {
+			out[key] = value
+		} else {
+			return nil, i, err
+		}
+	}
+
+	if i < len(lines) && lines[i].Indent > indent {
+		return nil, i, fmt.Errorf("yaml: line %d: unexpected indentation", lines[i].Number)
+	}
+
+	return out, i, nil
+}
+
+func isYAMLSequenceItem(text string) bool {
+	return text == `-` || strings.HasPrefix(text, `- `)
+}
+
+// returns the index of the colon separating a mapping key from its value, or -1
+func yamlKeySplit(text string) int {
+	var quote rune
+
+	for i, r := range text {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			if i == 0 {
+				quote = r
+			}
+		case r == '[' || r == '{':
+			if i == 0 {
+				return -1
+			}
+		case r == ':':
+			if i+1 == len(text) || text[i+1] == ' ' {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+func parseYAMLKey(in string, lineNumber int) (string, error) {
+	in = strings.TrimSpace(in)
+
+	if strings.HasPrefix(in, `"`) || strings.HasPrefix(in, `'`) {
+		if value, rest, err := parseYAMLQuoted(in, lineNumber); err == nil && strings.TrimSpace(rest) == `` {
+			return value, nil
+		} else if err != nil {
+			return ``, err
+		}
+
+		return ``, fmt.Errorf("yaml: line %d: invalid key %q", lineNumber, in)
+	}
+
+	return in, nil
+}
+
+// parses a scalar or flow collection that makes up the remainder of a line
+func parseYAMLFlow(in string, lineNumber int) (interface{}, error) {
+	if value, rest, err := parseYAMLFlowValue(strings.TrimSpace(in), lineNumber, false); err == nil {
+		if strings.TrimSpace(rest) != `` {
+			return nil, fmt.Errorf("yaml: line %d: unexpected %q", lineNumber, rest)
+		}
+
+		return value, nil
+	} else {
+		return nil, err
+	}
+}
+
+func parseYAMLFlowValue(in string, lineNumber int, nested bool) (interface{}, string, error) {
+	in = strings.TrimLeft(in, ` `)
+
+	switch {
+	case strings.HasPrefix(in, `[`):
+		out := make([]interface{}, 0)
+		in = strings.TrimLeft(in[1:], ` `)
+
+		for !strings.HasPrefix(in, `]`) {
+			if value, rest, err := parseYAMLFlowValue(in, lineNumber, true); err == nil {
+				out = append(out, value)
+				in = strings.TrimLeft(rest, ` `)
+			} else {
+				return nil, ``, err
+			}
+
+			if strings.HasPrefix(in, `,`) {
+				in = strings.TrimLeft(in[1:], ` `)
+			} else if !strings.HasPrefix(in, `]`) {
+				return nil, ``, fmt.Errorf("yaml: line %d: unterminated flow sequence", lineNumber)
+			}
+		}
+
+		return out, in[1:], nil
+
+	case strings.HasPrefix(in, `{`):
+		out := make(map[string]interface{})
+		in = strings.TrimLeft(in[1:], ` `)
+
+		for !strings.HasPrefix(in, `}`) {
+			split := strings.Index(in, `:`)
+
+			if split < 0 {
+				return nil, ``, fmt.Errorf("yaml: line %d: unterminated flow mapping", lineNumber)
+			}
+
+			key, err := parseYAMLKey(in[:split], lineNumber)
+
+			if err != nil {
+				return nil, ``, err
+			}
+
+			if value, rest, err := parseYAMLFlowValue(in[split+1:], lineNumber, true); err == nil {
+				out[key] = value
+				in = strings.TrimLeft(rest, ` `)
+			} else {
+				return nil, ``, err
+			}
+
+			if strings.HasPrefix(in, `,`) {
+				in = strings.TrimLeft(in[1:], ` `)
+			} else if !strings.HasPrefix(in, `}`) {
+				return nil, ``, fmt.Errorf("yaml: line %d: unterminated flow mapping", lineNumber)
+			}
+		}
+
+		return out, in[1:], nil
+
+	case strings.HasPrefix(in, `"`), strings.HasPrefix(in, `'`):
+		return parseYAMLQuoted(in, lineNumber)
+
+	default:
+		end := len(in)
+
+		// inside of flow collections, plain scalars end at the next indicator
+		if nested {
+			if i := strings.IndexAny(in, `,]}`); i >= 0 {
+				end = i
+			}
+		}
+
+		return yamlScalar(strings.TrimSpace(in[:end])), in[end:], nil
+	}
+}
+
+func parseYAMLQuoted(in string, lineNumber int) (string, string, error) {
+	if strings.HasPrefix(in, `'`) {
+		var out strings.Builder
+
+		for i := 1; i < len(in); i++ {
+			if in[i] == '\'' {
+				if i+1 < len(in) && in[i+1] == '\'' {
+					out.WriteByte('\'')
+					i += 1
+				} else {
+					return out.String(), in[i+1:], nil
+				}
+			} else {
+				out.WriteByte(in[i])
+			}
+		}
+	} else {
+		for i := 1; i < len(in); i++ {
+			if in[i] == '\\' {
+				i += 1
+			} else if in[i] == '"' {
+				if value, err := strconv.Unquote(in[:i+1]); err == nil {
+					return value, in[i+1:], nil
+				} else {
+					return ``, ``, fmt.Errorf("yaml: line %d: invalid string %s: %v", lineNumber, in[:i+1], err)
+				}
+			}
+		}
+	}
+
+	return ``, ``, fmt.Errorf("yaml: line %d: unterminated string", lineNumber)
+}
+
+// converts plain scalars into their native types
+func yamlScalar(in string) interface{} {
+	switch in {
+	case ``, `~`, `null`, `Null`, `NULL`:
+		return nil
+	case `true`, `True`, `TRUE`:
+		return true
+	case `false`, `False`, `FALSE`:
+		return false
+	}
+
+	if yamlInteger.MatchString(in) {
+		if i, err := strconv.ParseInt(in, 10, 64); err == nil {
+			return i
+		}
+	} else if yamlFloat.MatchString(in) {
+		if f, err := strconv.ParseFloat(in, 64); err == nil {
+			return f
+		}
+	}
+
+	return in
+}
+
+// removes comments ("#" at the start of the line or preceded by whitespace) outside of quotes
+func stripYAMLComment(line string) string {
+	var quote rune
+
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			if i == 0 || line[i-1] == ' ' || line[i-1] == '[' || line[i-1] == '{' || line[i-1] == ',' || line[i-1] == ':' {
+				quote = r
+			}
+		case r == '#':
+			if i == 0 || line[i-1] == ' ' || line[i-1] == '\t' {
+				return line[:i]
+			}
+		}
+	}
+
+	return line
+}
+
+// Serializes the field values of the given struct (not the command line it generates) as a YAML
+// document that can be read back with ParseYAML.  Keys are the primary option name of each field,
+// and the "help" tag option of a field is written as a comment above its key.  Zero-valued fields
+// are omitted, unless they specify a "default" tag option, in which case they are included as a
+// comment showing the default value.
+func MarshalYAML(v interface{}) ([]byte, error) {
+	vV := reflect.ValueOf(v)
+
+	for vV.Kind() == reflect.Ptr {
+		vV = vV.Elem()
+	}
+
+	if vV.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("struct needed, got %T", v)
+	}
+
+	var buf bytes.Buffer
+
+	if _, err := encodeYAMLStruct(newConfig(), &buf, vV, 0, ``); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writes the fields of a struct as a block mapping, returning the number of keys written.  The
+// first key is preceded by firstPrefix instead of indentation (used to start mappings on the same
+// line as a sequence item's "- ").
+func encodeYAMLStruct(cfg *Config, buf *bytes.Buffer, structV reflect.Value, indent int, firstPrefix string) (int, error) {
+	written := 0
+
+	err := walkFields(cfg, structV.Type(), nil, func(path []int, field reflect.StructField, tag *argonautTag) error {
+		fieldT := derefType(field.Type)
+
+		if fieldT == commandNameType || fieldT == argNameType {
+			return nil
+		}
+
+		pad := strings.Repeat(` `, indent)
+		key := yamlKey(cfg.primaryOption(tag, field.Name))
+		fieldV, ok := fieldValueByPath(structV, path)
+
+		if !ok || fieldV.IsZero() {
+			if tag.Default != `` {
+				fmt.Fprintf(buf, "%s# %s: %s\n", pad, key, tag.Default)
+			}
+
+			return nil
+		}
+
+		if tag.Help != `` {
+			if written == 0 && firstPrefix != `` {
+				fmt.Fprintf(buf, "%s# %s\n", strings.TrimSuffix(firstPrefix, `- `), tag.Help)
+			} else {
+				fmt.Fprintf(buf, "%s# %s\n", pad, tag.Help)
+			}
+		}
+
+		if written == 0 && firstPrefix != `` {
+			buf.WriteString(firstPrefix)
+		} else {
+			buf.WriteString(pad)
+		}
+
+		written += 1
+		buf.WriteString(key + `:`)
+
+		if err := encodeYAMLValue(cfg, buf, fieldV, indent); err != nil {
+			return fmt.Errorf("field %s: %v", field.Name, err)
+		}
+
+		return nil
+	})
+
+	return written, err
+}
+
+// writes a value following a mapping key (at the given indentation level) or sequence indicator
+func encodeYAMLValue(cfg *Config, buf *bytes.Buffer, value reflect.Value, indent int) error {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			buf.WriteString(" null\n")
+			return nil
+		}
+
+		value = value.Elem()
+	}
+
+	pad := strings.Repeat(` `, indent+2)
+
+	if value.CanInterface() {
+		if t, ok := value.Interface().(time.Time); ok {
+			buf.WriteString(` ` + yamlString(t.Format(time.RFC3339Nano)) + "\n")
+			return nil
+		} else if fn, rvalue, ok := registeredSerializer(value.Interface()); ok {
+			if str, err := fn(rvalue); err == nil {
+				buf.WriteString(` ` + yamlString(str) + "\n")
+				return nil
+			} else {
+				return err
+			}
+		} else if marshaler, ok := value.Interface().(encoding.TextMarshaler); ok {
+			if text, err := marshaler.MarshalText(); err == nil {
+				buf.WriteString(` ` + yamlString(string(text)) + "\n")
+				return nil
+			} else {
+				return err
+			}
+		} else if om, ok := value.Interface().(OrderedMap); ok {
+			if len(om) == 0 {
+				buf.WriteString(" {}\n")
+				return nil
+			}
+
+			buf.WriteString("\n")
+
+			for _, kv := range om {
+				buf.WriteString(pad + yamlKey(kv.Key) + `:`)
+
+				if err := encodeYAMLValue(cfg, buf, reflect.ValueOf(&kv.Value).Elem(), indent+2); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		buf.WriteString(` ` + yamlString(value.String()) + "\n")
+
+	case reflect.Bool:
+		buf.WriteString(` ` + strconv.FormatBool(value.Bool()) + "\n")
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString(` ` + strconv.FormatInt(value.Int(), 10) + "\n")
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		buf.WriteString(` ` + strconv.FormatUint(value.Uint(), 10) + "\n")
+
+	case reflect.Float32, reflect.Float64:
+		buf.WriteString(` ` + strconv.FormatFloat(value.Float(), 'g', -1, 64) + "\n")
+
+	case reflect.Slice, reflect.Array:
+		if value.Len() == 0 {
+			buf.WriteString(" []\n")
+			return nil
+		}
+
+		buf.WriteString("\n")
+
+		for i := 0; i < value.Len(); i++ {
+			item := reflect.Indirect(value.Index(i))
+
+			if item.Kind() == reflect.Struct && !isLeafType(item.Type()) {
+				if n, err := encodeYAMLStruct(cfg, buf, item, indent+4, pad+`- `); err != nil {
+					return err
+				} else if n == 0 {
+					buf.WriteString(pad + "- {}\n")
+				}
+			} else {
+				buf.WriteString(pad + `-`)
+
+				if err := encodeYAMLValue(cfg, buf, value.Index(i), indent+2); err != nil {
+					return err
+				}
+			}
+		}
+
+	case reflect.Map:
+		if value.Len() == 0 {
+			buf.WriteString(" {}\n")
+			return nil
+		}
+
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprintf("%v", keys[i].Interface()) < fmt.Sprintf("%v", keys[j].Interface())
+		})
+
+		buf.WriteString("\n")
+
+		for _, k := range keys {
+			buf.WriteString(pad + yamlKey(fmt.Sprintf("%v", k.Interface())) + `:`)
+
+			if err := encodeYAMLValue(cfg, buf, value.MapIndex(k), indent+2); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Struct:
+		buf.WriteString("\n")
+
+		_, err := encodeYAMLStruct(cfg, buf, value, indent+2, ``)
+		return err
+
+	default:
+		return fmt.Errorf("cannot represent %v as YAML", value.Type())
+	}
+
+	return nil
+}
+
+func yamlKey(key string) string {
+	if key != `` && yamlScalar(key) == key && !strings.ContainsAny(key, `:#'"[]{},&*!|>%@`+"`") && !strings.HasPrefix(key, `-`) {
+		return key
+	} else {
+		return strconv.Quote(key)
+	}
+}
+
+// quotes strings that would otherwise be read back as a different type, or that contain
+// characters with special meaning in YAML
+func yamlString(in string) string {
+	if in == `` || yamlScalar(in) != in || strings.TrimSpace(in) != in {
+		return strconv.Quote(in)
+	} else if strings.ContainsAny(in, "#:\n\t\"'\\") || strings.ContainsAny(in[:1], "-?,[]{}&*!|>%@`") {
+		return strconv.Quote(in)
+	}
+
+	return in
+}
@@ -0,0 +1,77 @@
+package argonaut
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalYAML(t *testing.T) {
+	assert := require.New(t)
+
+	type stream struct {
+		Index int    `argonaut:"index"`
+		Codec string `argonaut:"codec,help=The codec to encode with"`
+	}
+
+	type encoder struct {
+		Command CommandName       `argonaut:"encoder"`
+		Preset  string            `argonaut:"preset,help=Speed/quality tradeoff,default=medium"`
+		Threads int               `argonaut:"threads|t,default=0"`
+		Quality float64           `argonaut:"q"`
+		Verbose bool              `argonaut:"verbose"`
+		Tags    []string          `argonaut:"tag"`
+		Labels  map[string]string `argonaut:"label"`
+		Output  string            `argonaut:",positional"`
+		Streams []stream          `argonaut:"stream"`
+	}
+
+	input := &encoder{
+		Preset:  `fast`,
+		Quality: 1.5,
+		Verbose: true,
+		Tags:    []string{`a`, `true`, "with: colon", ``},
+		Labels: map[string]string{
+			`b`: `2`,
+			`a`: `one`,
+		},
+		Output: `out.mp4`,
+		Streams: []stream{
+			{Index: 0, Codec: `libx264`},
+			{},
+			{Index: 1},
+		},
+	}
+
+	data, err := MarshalYAML(input)
+	assert.NoError(err)
+	assert.Equal(`# Speed/quality tradeoff
+preset: fast
+# threads: 0
+q: 1.5
+verbose: true
+tag:
+  - a
+  - "true"
+  - "with: colon"
+  - ""
+label:
+  a: one
+  b: "2"
+output: out.mp4
+stream:
+  # The codec to encode with
+  - codec: libx264
+  - {}
+  - index: 1
+`, string(data))
+
+	var output encoder
+
+	assert.NoError(ParseFromReader(bytes.NewReader(data), `yaml`, &output))
+	assert.Equal(input, &output)
+
+	_, err = MarshalYAML(`nope`)
+	assert.Error(err)
+}